@@ -0,0 +1,131 @@
+package neocliapi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// AssetTypeNEP5 NEP-5 资产在 AssetInfo.Type 中的类型
+const AssetTypeNEP5 = `NEP5`
+
+// AssetInfo 资产的元数据，全局资产（UTXO资产）和NEP-5资产使用相同的结构
+type AssetInfo struct {
+	AssetID  neoutils.HASH256 // 全局资产ID，NEP-5资产为nil
+	Contract neoutils.HASH160 // NEP-5合约，全局资产为nil
+	Type     string           // 全局资产的类型（GoverningToken、UtilityToken、Token 等），NEP-5资产为 AssetTypeNEP5
+	Name     string
+	Symbol   string // NEP-5资产的符号，全局资产 NEO、GAS 为 NEO、GAS，其它为名称
+	Decimals int    // 全局资产的 precision，NEP-5资产的 decimals
+}
+
+// Key 返回资产在 Balances 中的键，即资产ID或合约的大端序十六进制字符串
+func (a *AssetInfo) Key() string {
+	if a.Contract != nil {
+		return a.Contract.ToHexString()
+	}
+	return a.AssetID.ToHexString()
+}
+
+// IsNEP5 判断是否为NEP-5资产
+func (a *AssetInfo) IsNEP5() bool {
+	return a.Contract != nil
+}
+
+// assetCache 资产元数据的缓存，资产的名称和精度注册后不会改变
+type assetCache struct {
+	mu     sync.Mutex
+	assets map[string]*AssetInfo
+}
+
+func (cache *assetCache) get(key string) *AssetInfo {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.assets[key]
+}
+
+func (cache *assetCache) put(info *AssetInfo) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.assets == nil {
+		cache.assets = make(map[string]*AssetInfo)
+	}
+	cache.assets[info.Key()] = info
+}
+
+// sharedAssetCaches 包级别的函数按节点地址共享的资产元数据缓存
+var sharedAssetCaches = struct {
+	sync.Mutex
+	caches map[string]*assetCache
+}{caches: make(map[string]*assetCache)}
+
+// withSharedAssetCache 使用节点地址对应的共享缓存，包级别的函数每次调用都会创建新的 Client，
+// 共享缓存避免每次都重新查询资产的元数据
+func withSharedAssetCache() ClientOption {
+	return func(c *Client) {
+		sharedAssetCaches.Lock()
+		defer sharedAssetCaches.Unlock()
+		cache := sharedAssetCaches.caches[c.endpoint]
+		if cache == nil {
+			cache = &assetCache{}
+			sharedAssetCaches.caches[c.endpoint] = cache
+		}
+		c.assets = cache
+	}
+}
+
+// GetAssetInfo 获取全局资产的元数据
+func GetAssetInfo(url string, assetID neoutils.HASH256) (*AssetInfo, error) {
+	return NewClient(url, withSharedAssetCache()).GetAssetInfo(context.Background(), assetID)
+}
+
+// GetAssetInfo 通过 getassetstate 获取全局资产的元数据，结果缓存在 Client 中
+func (c *Client) GetAssetInfo(ctx context.Context, assetID neoutils.HASH256) (*AssetInfo, error) {
+	key := assetID.ToHexString()
+	if info := c.assets.get(key); info != nil {
+		return info, nil
+	}
+	state, err := c.GetAssetState(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	info := &AssetInfo{
+		AssetID:  state.ID,
+		Type:     state.Type,
+		Name:     state.Name,
+		Symbol:   neotransaction.GetAssetSymbol(key),
+		Decimals: state.Precision,
+	}
+	if len(info.Symbol) == 0 {
+		info.Symbol = state.Name
+	}
+	c.assets.put(info)
+	return info, nil
+}
+
+// GetNEP5Info 获取NEP-5资产的元数据
+func GetNEP5Info(url string, contract neoutils.HASH160) (*AssetInfo, error) {
+	return NewClient(url, withSharedAssetCache()).GetNEP5Info(context.Background(), contract)
+}
+
+// GetNEP5Info 通过试运行合约的 name、symbol、decimals 方法获取NEP-5资产的元数据，结果缓存在 Client 中
+func (c *Client) GetNEP5Info(ctx context.Context, contract neoutils.HASH160) (*AssetInfo, error) {
+	if info := c.assets.get(contract.ToHexString()); info != nil {
+		return info, nil
+	}
+	token, err := NewNEP5Token(c, contract).Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := &AssetInfo{
+		Contract: contract.Copy(),
+		Type:     AssetTypeNEP5,
+		Name:     token.Name,
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+	}
+	c.assets.put(info)
+	return info, nil
+}
@@ -0,0 +1,57 @@
+package neocliapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+)
+
+func TestGetAssetInfo(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{
+		"getassetstate": `{"version":0,"id":"0x602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7","type":"UtilityToken",` +
+			`"name":[{"lang":"zh-CN","name":"小蚁币"},{"lang":"en","name":"AntCoin"}],"amount":"100000000","available":"30244071.37584551",` +
+			`"precision":8,"owner":"00","admin":"AWKECj9RD8rS8RPcpCgYVjk1DeYyHwxZm3","issuer":"AWKECj9RD8rS8RPcpCgYVjk1DeYyHwxZm3",` +
+			`"expiration":4000000,"frozen":false}`,
+	})
+	client := NewClient(node.URL)
+	gas := mustHASH256(neotransaction.AssetGasID)
+	want := &AssetInfo{AssetID: gas, Type: "UtilityToken", Name: "AntCoin", Symbol: "GAS", Decimals: 8}
+	for i := 0; i < 2; i++ {
+		info, err := client.GetAssetInfo(context.Background(), gas)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(info, want) {
+			t.Fatalf("got  %+v\nwant %+v", info, want)
+		}
+	}
+	if node.count("getassetstate") != 1 {
+		t.Fatal("asset info not cached")
+	}
+}
+
+func TestGetNEP5Info(t *testing.T) {
+	// name、symbol、decimals、totalSupply 依次试运行的结果
+	node := newSyntheticNode(t, map[string]string{
+		"invokescript": `{"script":"","state":"HALT","gas_consumed":"0.424","stack":[` +
+			`{"type":"ByteArray","value":"5265642050756c736520546f6b656e"},{"type":"ByteArray","value":"525058"},` +
+			`{"type":"Integer","value":"8"},{"type":"ByteArray","value":"00e057eb481b"}]}`,
+	})
+	client := NewClient(node.URL)
+	contract := mustHASH160("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9")
+	want := &AssetInfo{Contract: contract, Type: AssetTypeNEP5, Name: "Red Pulse Token", Symbol: "RPX", Decimals: 8}
+	for i := 0; i < 2; i++ {
+		info, err := client.GetNEP5Info(context.Background(), contract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(info, want) || info.Key() != "ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9" || !info.IsNEP5() {
+			t.Fatalf("got  %+v\nwant %+v", info, want)
+		}
+	}
+	if node.count("invokescript") != 1 {
+		t.Fatal("NEP-5 info not cached")
+	}
+}
@@ -0,0 +1,233 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// BatchCall 批量调用中的一个调用
+type BatchCall struct {
+	Method string
+	Params []interface{}
+	Result interface{} // 结果解析到的目标，为nil时不解析
+	Error  error       // 调用完成后的错误，与单个调用相同，为 RPCError、DecodeError 或 TransportError
+}
+
+// NewBatchCall 创建一个调用 method 并将结果解析到 result 的批量调用
+func NewBatchCall(result interface{}, method string, params ...interface{}) *BatchCall {
+	return &BatchCall{Method: method, Params: params, Result: result}
+}
+
+// Batch 按 WithBatchSize 分批发送调用，每批在一个HTTP请求中发送，应答按id对应到每个调用
+// 每个调用的结果和错误写入对应的 BatchCall，有调用失败时返回 BatchError
+func (c *Client) Batch(ctx context.Context, calls []*BatchCall) error {
+	size := c.batchSize
+	if size < 1 {
+		size = len(calls)
+	}
+	for start := 0; start < len(calls); start += size {
+		end := start + size
+		if end > len(calls) {
+			end = len(calls)
+		}
+		c.batch(ctx, calls[start:end])
+	}
+	return batchError(calls, nil)
+}
+
+// batch 在一个HTTP请求中发送一批调用
+func (c *Client) batch(ctx context.Context, calls []*BatchCall) {
+	fail := func(err error) {
+		for _, call := range calls {
+			call.Error = err
+		}
+	}
+
+	requests := make([]*rpcRequest, len(calls))
+	for i, call := range calls {
+		call.Error = nil
+		requests[i] = newRPCRequest(call.Method, call.Params)
+	}
+	body, err := json.Marshal(requests)
+	if err != nil {
+		fail(fmt.Errorf(`encode batch request: %w`, err))
+		return
+	}
+	status, buff, err := c.post(ctx, body)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	items := make([]json.RawMessage, 0)
+	if err = json.Unmarshal(buff, &items); err != nil {
+		// 节点对整个请求返回了一个错误应答，例如不支持批量调用
+		single := &rpcResponse{}
+		if json.Unmarshal(buff, single) == nil && single.Error != nil {
+			fail(single.Error)
+		} else if status != http.StatusOK {
+			fail(&TransportError{Endpoint: c.endpoint, StatusCode: status, Err: fmt.Errorf(`http status %d`, status)})
+		} else {
+			fail(&DecodeError{Body: buff, Err: err})
+		}
+		return
+	}
+
+	responses := make(map[string]*rpcResponse, len(items))
+	raws := make(map[string]json.RawMessage, len(items))
+	var nullIDError *RPCError
+	for _, item := range items {
+		response := &rpcResponse{}
+		if err := json.Unmarshal(item, response); err != nil {
+			continue
+		}
+		if response.Error != nil && (len(response.ID) == 0 || string(response.ID) == `null`) {
+			nullIDError = response.Error
+			continue
+		}
+		responses[string(response.ID)] = response
+		raws[string(response.ID)] = item
+	}
+
+	for i, call := range calls {
+		id := strconv.FormatUint(requests[i].ID, 10)
+		response, ok := responses[id]
+		if !ok {
+			if nullIDError != nil {
+				call.Error = nullIDError
+			} else {
+				call.Error = &DecodeError{Body: buff, Err: fmt.Errorf(`no response for request id %s`, id)}
+			}
+			continue
+		}
+		result, err := c.result(response, requests[i].ID, status, raws[id])
+		if err != nil {
+			call.Error = err
+			continue
+		}
+		if call.Result != nil {
+			if err := json.Unmarshal(result, call.Result); err != nil {
+				call.Error = &DecodeError{Body: result, Err: err}
+			}
+		}
+	}
+}
+
+// batchError 有调用失败时返回 BatchError，wrap 不为nil时用于包装每个调用的错误
+func batchError(calls []*BatchCall, wrap func(i int, err error) error) error {
+	var errs []error
+	for i, call := range calls {
+		if call.Error == nil {
+			continue
+		}
+		if errs == nil {
+			errs = make([]error, len(calls))
+		}
+		errs[i] = call.Error
+		if wrap != nil {
+			errs[i] = wrap(i, call.Error)
+		}
+	}
+	if errs == nil {
+		return nil
+	}
+	return &BatchError{Errors: errs}
+}
+
+// GetBlocks 批量获取指定高度的区块
+func GetBlocks(url string, heights []uint64) ([]*Block, error) {
+	return NewClient(url).GetBlocks(context.Background(), heights)
+}
+
+// GetBlocks 批量获取指定高度的区块，结果与 heights 一一对应，获取失败的区块为nil并返回 BatchError
+func (c *Client) GetBlocks(ctx context.Context, heights []uint64) ([]*Block, error) {
+	blocks := make([]*Block, len(heights))
+	calls := make([]*BatchCall, len(heights))
+	for i, height := range heights {
+		blocks[i] = &Block{}
+		calls[i] = NewBatchCall(blocks[i], `getblock`, height, 1)
+	}
+	c.Batch(ctx, calls)
+	for i, call := range calls {
+		if call.Error != nil {
+			blocks[i] = nil
+		}
+	}
+	return blocks, batchError(calls, func(i int, err error) error {
+		return fmt.Errorf(`GetBlock[%v] error: %w`, heights[i], err)
+	})
+}
+
+// GetTransactions 批量获取交易
+func GetTransactions(url string, txids []string) ([]*Transaction, error) {
+	return NewClient(url).GetTransactions(context.Background(), txids)
+}
+
+// GetTransactions 批量获取交易，结果与 txids 一一对应，获取失败的交易为nil并返回 BatchError
+func (c *Client) GetTransactions(ctx context.Context, txids []string) ([]*Transaction, error) {
+	txs := make([]*Transaction, len(txids))
+	calls := make([]*BatchCall, len(txids))
+	for i, txid := range txids {
+		txs[i] = &Transaction{}
+		calls[i] = NewBatchCall(txs[i], `getrawtransaction`, txid, 1)
+	}
+	c.Batch(ctx, calls)
+	for i, call := range calls {
+		if call.Error != nil {
+			txs[i] = nil
+		}
+	}
+	return txs, batchError(calls, func(i int, err error) error {
+		return fmt.Errorf(`GetTransaction[%s] error: %w`, txids[i], err)
+	})
+}
+
+// FetchBlocks 批量获取区块
+func FetchBlocks(url string, heights []uint64) ([]map[string]interface{}, error) {
+	return NewClient(url).FetchBlocks(context.Background(), heights)
+}
+
+// FetchBlocks 批量获取区块，结果与 heights 一一对应，获取失败的区块为nil并返回 BatchError
+func (c *Client) FetchBlocks(ctx context.Context, heights []uint64) ([]map[string]interface{}, error) {
+	blocks := make([]map[string]interface{}, len(heights))
+	calls := make([]*BatchCall, len(heights))
+	for i, height := range heights {
+		calls[i] = NewBatchCall(&blocks[i], `getblock`, height, 1)
+	}
+	c.Batch(ctx, calls)
+	for i, call := range calls {
+		if call.Error != nil {
+			blocks[i] = nil
+		}
+	}
+	return blocks, batchError(calls, func(i int, err error) error {
+		return fmt.Errorf(`FetchBlock[%v] error: %w`, heights[i], err)
+	})
+}
+
+// FetchBalances 批量获取账户持有的全局资产余额
+func FetchBalances(url string, addrs []string) ([]Balances, error) {
+	return NewClient(url, withSharedAssetCache()).FetchBalances(context.Background(), addrs)
+}
+
+// FetchBalances 批量获取账户持有的全局资产余额，结果与 addrs 一一对应，获取失败的账户为nil并返回 BatchError
+func (c *Client) FetchBalances(ctx context.Context, addrs []string) ([]Balances, error) {
+	results := make([]accountStateJSON, len(addrs))
+	calls := make([]*BatchCall, len(addrs))
+	for i, addr := range addrs {
+		calls[i] = NewBatchCall(&results[i], `getaccountstate`, addr)
+	}
+	c.Batch(ctx, calls)
+	balances := make([]Balances, len(addrs))
+	for i, call := range calls {
+		if call.Error == nil {
+			balances[i], call.Error = c.parseBalance(ctx, &results[i])
+		}
+	}
+	return balances, batchError(calls, func(i int, err error) error {
+		return fmt.Errorf(`FetchBalance[%s] error: %w`, addrs[i], err)
+	})
+}
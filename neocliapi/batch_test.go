@@ -0,0 +1,311 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// batchServer 模拟节点的批量调用接口，respond 按一个HTTP请求中的所有调用生成应答数组的元素，
+// 可以打乱顺序、丢弃或替换应答
+type batchServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	sizes []int // 每个HTTP请求中的调用数
+}
+
+func newBatchServer(t *testing.T, respond func(reqs []*syntheticRequest) []string) *batchServer {
+	server := &batchServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqs := []*syntheticRequest{}
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		server.mu.Lock()
+		server.sizes = append(server.sizes, len(reqs))
+		server.mu.Unlock()
+		fmt.Fprint(w, "["+strings.Join(respond(reqs), ",")+"]")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// batchSizes 返回每个HTTP请求中的调用数
+func (server *batchServer) batchSizes() string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return fmt.Sprint(server.sizes)
+}
+
+// echoResult 将请求的第一个参数作为结果返回
+func echoResult(req *syntheticRequest) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, req.Params[0])
+}
+
+// echoCalls 创建 n 个结果为字符串的调用，参数为调用的序号
+func echoCalls(n int) ([]*BatchCall, []string) {
+	results := make([]string, n)
+	calls := make([]*BatchCall, n)
+	for i := range calls {
+		calls[i] = NewBatchCall(&results[i], "echo", strconv.Itoa(i))
+	}
+	return calls, results
+}
+
+func TestBatchOutOfOrder(t *testing.T) {
+	// 应答的顺序与请求相反
+	server := newBatchServer(t, func(reqs []*syntheticRequest) []string {
+		items := make([]string, len(reqs))
+		for i, req := range reqs {
+			items[len(reqs)-1-i] = echoResult(req)
+		}
+		return items
+	})
+	calls, results := echoCalls(4)
+	if err := NewClient(server.URL).Batch(context.Background(), calls); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(results) != "[0 1 2 3]" {
+		t.Fatalf("results %v", results)
+	}
+}
+
+func TestBatchMissingResponse(t *testing.T) {
+	// 没有返回第二个调用的应答
+	server := newBatchServer(t, func(reqs []*syntheticRequest) []string {
+		return []string{echoResult(reqs[2]), echoResult(reqs[0])}
+	})
+	calls, results := echoCalls(3)
+	err := NewClient(server.URL).Batch(context.Background(), calls)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 3 || batchErr.Errors[0] != nil || batchErr.Errors[2] != nil {
+		t.Fatalf("expected a BatchError for the second call, got %v", err)
+	}
+	var decodeErr *DecodeError
+	if !errors.As(calls[1].Error, &decodeErr) || batchErr.Errors[1] != calls[1].Error {
+		t.Fatalf("expected DecodeError, got %v", calls[1].Error)
+	}
+	if results[0] != "0" || results[1] != "" || results[2] != "2" {
+		t.Fatalf("results %q", results)
+	}
+}
+
+func TestBatchSplit(t *testing.T) {
+	// 每批的应答顺序打乱，第二批的最后一个调用返回错误
+	server := newBatchServer(t, func(reqs []*syntheticRequest) []string {
+		items := []string{}
+		for i := len(reqs) - 1; i >= 0; i-- {
+			if string(reqs[i].Params[0]) == `"3"` {
+				items = append(items, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-100,"message":"Unknown block"}}`, reqs[i].ID))
+				continue
+			}
+			items = append(items, echoResult(reqs[i]))
+		}
+		return items
+	})
+	calls, results := echoCalls(5)
+	err := NewClient(server.URL, WithBatchSize(2)).Batch(context.Background(), calls)
+	if sizes := server.batchSizes(); sizes != "[2 2 1]" {
+		t.Fatalf("batch sizes %v", sizes)
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || !IsRPCError(batchErr.Errors[3], ErrCodeUnknownItem) {
+		t.Fatalf("expected a BatchError for call 3, got %v", err)
+	}
+	for i, e := range batchErr.Errors {
+		if i != 3 && e != nil {
+			t.Fatalf("call %v: %v", i, e)
+		}
+	}
+	if fmt.Sprintf("%q", results) != `["0" "1" "2" "" "4"]` {
+		t.Fatalf("results %q", results)
+	}
+
+	// 批量大小小于1时在一个HTTP请求中发送所有调用
+	server = newBatchServer(t, func(reqs []*syntheticRequest) []string {
+		items := make([]string, len(reqs))
+		for i, req := range reqs {
+			items[i] = echoResult(req)
+		}
+		return items
+	})
+	calls, _ = echoCalls(DefaultBatchSize + 1)
+	if err = NewClient(server.URL, WithBatchSize(0)).Batch(context.Background(), calls); err != nil {
+		t.Fatal(err)
+	}
+	if sizes := server.batchSizes(); sizes != fmt.Sprintf("[%v]", DefaultBatchSize+1) {
+		t.Fatalf("batch sizes %v", sizes)
+	}
+}
+
+func TestBatchNullIDError(t *testing.T) {
+	// 节点无法解析其中一个调用时返回 id 为 null 的错误，无法对应到调用
+	server := newBatchServer(t, func(reqs []*syntheticRequest) []string {
+		return []string{echoResult(reqs[0]), `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid Request"}}`}
+	})
+	calls, results := echoCalls(2)
+	err := NewClient(server.URL).Batch(context.Background(), calls)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Errors[0] != nil || !IsRPCError(batchErr.Errors[1], -32600) {
+		t.Fatalf("expected the null id error for the second call, got %v", err)
+	}
+	if results[0] != "0" {
+		t.Fatalf("results %q", results)
+	}
+}
+
+func TestBatchWholeRequestError(t *testing.T) {
+	tests := []struct {
+		name  string
+		serve func(w http.ResponseWriter)
+		check func(err error) bool
+	}{
+		{
+			// 节点对整个请求返回一个错误对象，例如不支持批量调用
+			name: "RPCError",
+			serve: func(w http.ResponseWriter) {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`)
+			},
+			check: func(err error) bool { return IsRPCError(err, -32700) },
+		},
+		{
+			name:  "HTTPStatus",
+			serve: func(w http.ResponseWriter) { http.Error(w, "bad gateway", http.StatusBadGateway) },
+			check: func(err error) bool {
+				var transportErr *TransportError
+				return errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusBadGateway
+			},
+		},
+		{
+			name:  "BrokenJSON",
+			serve: func(w http.ResponseWriter) { fmt.Fprint(w, `[{"jsonrpc":"2.0","id":1,`) },
+			check: func(err error) bool {
+				var decodeErr *DecodeError
+				return errors.As(err, &decodeErr)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { tt.serve(w) }))
+			defer server.Close()
+			calls, _ := echoCalls(2)
+			err := NewClient(server.URL).Batch(context.Background(), calls)
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("expected BatchError, got %v", err)
+			}
+			for i, call := range calls {
+				if !tt.check(call.Error) || batchErr.Errors[i] != call.Error {
+					t.Fatalf("call %v error %v", i, call.Error)
+				}
+			}
+		})
+	}
+}
+
+// testBlockJSON 返回高度为 height 的 getblock verbose 应答，只包含一个 MinerTransaction
+func testBlockJSON(height uint64) string {
+	return fmt.Sprintf(`{"hash":"0x%064x","size":686,"version":0,"previousblockhash":"0x%064x",`+
+		`"merkleroot":"0x%064x","time":1476647382,"index":%d,"nonce":"7fd2ab3d1d59bd7a",`+
+		`"nextconsensus":"APyEx5f4Zm4oCHwFWiSTaph1fPBxZacYVR","script":{"invocation":"40a1b2c3d4","verification":"51ae"},`+
+		`"tx":[{"txid":"0x%064x","size":10,"type":"MinerTransaction","version":0,"attributes":[],"vin":[],"vout":[],`+
+		`"sys_fee":"0","net_fee":"0","scripts":[],"nonce":%d}],"confirmations":1}`,
+		height+1, height, height+0x1000, height, height+0x2000, height)
+}
+
+// blockNode 返回高度小于 count 的区块，其他高度返回 Unknown block
+func blockNode(t *testing.T, count uint64) *syntheticNode {
+	node := newSyntheticNode(t, map[string]string{})
+	node.handle("getblock", func(params []json.RawMessage) string {
+		height, err := strconv.ParseUint(string(params[0]), 10, 64)
+		if err != nil || height >= count || string(params[1]) != "1" {
+			return `error:{"code":-100,"message":"Unknown block"}`
+		}
+		return testBlockJSON(height)
+	})
+	return node
+}
+
+func TestGetBlocks(t *testing.T) {
+	node := blockNode(t, 3)
+	blocks, err := GetBlocks(node.URL, []uint64{2, 5, 0})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || !IsRPCError(batchErr.Errors[1], ErrCodeUnknownItem) || !strings.Contains(batchErr.Errors[1].Error(), "GetBlock[5]") {
+		t.Fatalf("expected a BatchError for block 5, got %v", err)
+	}
+	if len(blocks) != 3 || blocks[1] != nil {
+		t.Fatalf("blocks %v", blocks)
+	}
+	for i, height := range []uint64{2, 0} {
+		block := blocks[i*2]
+		if block.Index != uint32(height) || block.Hash.ToHexString() != fmt.Sprintf("%064x", height+1) ||
+			len(block.Transactions) != 1 || block.Transactions[0].Nonce != uint32(height) {
+			t.Fatalf("block %v: %+v", height, block)
+		}
+	}
+	if calls := node.count("getblock"); calls != 3 {
+		t.Fatalf("getblock called %v times", calls)
+	}
+}
+
+func TestFetchBlocks(t *testing.T) {
+	node := blockNode(t, 2)
+	blocks, err := FetchBlocks(node.URL, []uint64{1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0]["index"] != 1.0 || blocks[1]["index"] != 0.0 {
+		t.Fatalf("blocks %v", blocks)
+	}
+	blocks, err = NewClient(node.URL).FetchBlocks(context.Background(), []uint64{0, 2})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Errors[0] != nil || !IsRPCError(batchErr.Errors[1], ErrCodeUnknownItem) {
+		t.Fatalf("expected a BatchError for block 2, got %v", err)
+	}
+	if blocks[0] == nil || blocks[1] != nil {
+		t.Fatalf("blocks %v", blocks)
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	txid := "f4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657"
+	node := newSyntheticNode(t, map[string]string{})
+	node.handle("getrawtransaction", func(params []json.RawMessage) string {
+		switch string(params[0]) {
+		case `"` + txid + `"`:
+			return `{"txid":"0x` + txid + `","size":10,"type":"ContractTransaction","version":0,"attributes":[],"vin":[],` +
+				`"vout":[{"n":0,"asset":"0x602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7","value":"1.5",` +
+				`"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}],"sys_fee":"0","net_fee":"0","scripts":[],` +
+				`"blockhash":"0x4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2","confirmations":10,"blocktime":1476647382}`
+		case `"broken"`:
+			return `{"txid":"0x00","type":"ContractTransaction"}`
+		}
+		return `error:{"code":-100,"message":"Unknown transaction"}`
+	})
+	txs, err := GetTransactions(node.URL, []string{"missing", txid, "broken"})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Errors[1] != nil || !IsRPCError(batchErr.Errors[0], ErrCodeUnknownItem) {
+		t.Fatalf("expected a BatchError for the missing transaction, got %v", err)
+	}
+	var decodeErr *DecodeError
+	if !errors.As(batchErr.Errors[2], &decodeErr) {
+		t.Fatalf("expected DecodeError for the broken transaction, got %v", batchErr.Errors[2])
+	}
+	if txs[0] != nil || txs[2] != nil || txs[1] == nil {
+		t.Fatalf("transactions %v", txs)
+	}
+	if tx := txs[1]; tx.TxID.ToHexString() != txid || len(tx.Outputs) != 1 || tx.Outputs[0].Value != 150000000 || tx.Confirmations != 10 {
+		t.Fatalf("transaction %+v", tx)
+	}
+}
@@ -0,0 +1,324 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// ContractState 已发布的智能合约
+type ContractState struct {
+	Version       int
+	Hash          neoutils.HASH160
+	Script        []byte
+	Parameters    []string // 参数类型，如 String、Array
+	ReturnType    string
+	Name          string
+	CodeVersion   string
+	Author        string
+	Email         string
+	Description   string
+	Storage       bool // 是否使用存储区
+	DynamicInvoke bool // 是否可以动态调用
+}
+
+// AssetState 全局资产（UTXO资产）的信息
+type AssetState struct {
+	Version    int
+	ID         neoutils.HASH256
+	Type       string            // GoverningToken、UtilityToken、Token、Share 等
+	Name       string            // 英文名称，没有时为第一个名称
+	Names      map[string]string // 语言对应的名称，如 en、zh-CN
+	Amount     neoutils.Fixed8   // 总量，为负数时表示不限量
+	Available  neoutils.Fixed8   // 已发行的数量
+	Precision  int
+	Owner      []byte // 所有者公钥
+	Admin      string // 管理员地址
+	Issuer     string // 发行者地址
+	Expiration uint32
+	Frozen     bool
+}
+
+// Validator 共识节点候选人
+type Validator struct {
+	PublicKey []byte
+	Votes     int64 // 得票数，即投票的NEO数量
+	Active    bool  // 是否为当前的共识节点
+}
+
+// GetBestBlockHash 获取主链中最高区块的哈希
+func GetBestBlockHash(url string) (neoutils.HASH256, error) {
+	return NewClient(url).GetBestBlockHash(context.Background())
+}
+
+// GetBestBlockHash 获取主链中最高区块的哈希
+func (c *Client) GetBestBlockHash(ctx context.Context) (neoutils.HASH256, error) {
+	hash := ``
+	if err := c.callResult(ctx, &hash, `getbestblockhash`); err != nil {
+		return nil, fmt.Errorf(`GetBestBlockHash error: %w`, err)
+	}
+	ret, err := neoutils.ParseHASH256(hash)
+	if err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetBestBlockHash %v`, err)}
+	}
+	return ret, nil
+}
+
+// GetBlockHash 获取指定高度的区块哈希
+func GetBlockHash(url string, height uint64) (neoutils.HASH256, error) {
+	return NewClient(url).GetBlockHash(context.Background(), height)
+}
+
+// GetBlockHash 获取指定高度的区块哈希
+func (c *Client) GetBlockHash(ctx context.Context, height uint64) (neoutils.HASH256, error) {
+	hash := ``
+	if err := c.callResult(ctx, &hash, `getblockhash`, height); err != nil {
+		return nil, fmt.Errorf(`GetBlockHash[%v] error: %w`, height, err)
+	}
+	ret, err := neoutils.ParseHASH256(hash)
+	if err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetBlockHash %v`, err)}
+	}
+	return ret, nil
+}
+
+// GetBlockHeader 根据区块哈希获取区块头
+func GetBlockHeader(url string, hash string) (*BlockHeader, error) {
+	return NewClient(url).GetBlockHeader(context.Background(), hash)
+}
+
+// GetBlockSysFee 获取从创世区块到指定高度的区块中所有交易的系统手续费总和
+func GetBlockSysFee(url string, height uint64) (neoutils.Fixed8, error) {
+	return NewClient(url).GetBlockSysFee(context.Background(), height)
+}
+
+// GetBlockSysFee 获取从创世区块到指定高度的区块中所有交易的系统手续费总和
+func (c *Client) GetBlockSysFee(ctx context.Context, height uint64) (neoutils.Fixed8, error) {
+	fee := neoutils.Fixed8(0)
+	if err := c.callResult(ctx, &fee, `getblocksysfee`, height); err != nil {
+		return 0, fmt.Errorf(`GetBlockSysFee[%v] error: %w`, height, err)
+	}
+	return fee, nil
+}
+
+// GetRawMempool 获取节点内存池中未确认的交易
+func GetRawMempool(url string) ([]neoutils.HASH256, error) {
+	return NewClient(url).GetRawMempool(context.Background())
+}
+
+// GetRawMempool 获取节点内存池中未确认的交易
+func (c *Client) GetRawMempool(ctx context.Context) ([]neoutils.HASH256, error) {
+	hashes := make([]string, 0)
+	if err := c.callResult(ctx, &hashes, `getrawmempool`); err != nil {
+		return nil, fmt.Errorf(`GetRawMempool error: %w`, err)
+	}
+	txids := make([]neoutils.HASH256, 0, len(hashes))
+	for _, hash := range hashes {
+		txid, err := neoutils.ParseHASH256(hash)
+		if err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetRawMempool %v`, err)}
+		}
+		txids = append(txids, txid)
+	}
+	return txids, nil
+}
+
+// GetTxOut 获取交易的一个输出，输出已被花费时返回nil
+func GetTxOut(url string, txid string, n uint16) (*TxOutput, error) {
+	return NewClient(url).GetTxOut(context.Background(), txid, n)
+}
+
+// GetTxOut 获取交易的一个输出，输出已被花费时返回nil
+func (c *Client) GetTxOut(ctx context.Context, txid string, n uint16) (*TxOutput, error) {
+	result := &txOutputJSON{}
+	if err := c.callResult(ctx, result, `gettxout`, txid, n); err != nil {
+		if errors.Is(err, errNoResult) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(`GetTxOut[%s:%v] error: %w`, txid, n, err)
+	}
+	output, err := result.toOutput()
+	if err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetTxOut %v`, err)}
+	}
+	return output, nil
+}
+
+// GetStorage 获取合约存储区中 key 对应的值，不存在时返回nil
+func GetStorage(url string, scriptHash neoutils.HASH160, key []byte) ([]byte, error) {
+	return NewClient(url).GetStorage(context.Background(), scriptHash, key)
+}
+
+// GetStorage 获取合约存储区中 key 对应的值，不存在时返回nil
+func (c *Client) GetStorage(ctx context.Context, scriptHash neoutils.HASH160, key []byte) ([]byte, error) {
+	value := ``
+	if err := c.callResult(ctx, &value, `getstorage`, scriptHash.ToHexString(), hex.EncodeToString(key)); err != nil {
+		if errors.Is(err, errNoResult) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(`GetStorage[%s] error: %w`, scriptHash.ToHexString(), err)
+	}
+	ret, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetStorage %v`, err)}
+	}
+	return ret, nil
+}
+
+// GetContractState 获取已发布的智能合约信息
+func GetContractState(url string, scriptHash neoutils.HASH160) (*ContractState, error) {
+	return NewClient(url).GetContractState(context.Background(), scriptHash)
+}
+
+// GetContractState 获取已发布的智能合约信息
+func (c *Client) GetContractState(ctx context.Context, scriptHash neoutils.HASH160) (*ContractState, error) {
+	result := &struct {
+		Version     int      `json:"version"`
+		Hash        string   `json:"hash"`
+		Script      string   `json:"script"`
+		Parameters  []string `json:"parameters"`
+		ReturnType  string   `json:"returntype"`
+		Name        string   `json:"name"`
+		CodeVersion string   `json:"code_version"`
+		Author      string   `json:"author"`
+		Email       string   `json:"email"`
+		Description string   `json:"description"`
+		Properties  struct {
+			Storage       bool `json:"storage"`
+			DynamicInvoke bool `json:"dynamic_invoke"`
+		} `json:"properties"`
+	}{}
+	if err := c.callResult(ctx, result, `getcontractstate`, scriptHash.ToHexString()); err != nil {
+		return nil, fmt.Errorf(`GetContractState[%s] error: %w`, scriptHash.ToHexString(), err)
+	}
+
+	contract := &ContractState{
+		Version:       result.Version,
+		Parameters:    result.Parameters,
+		ReturnType:    result.ReturnType,
+		Name:          result.Name,
+		CodeVersion:   result.CodeVersion,
+		Author:        result.Author,
+		Email:         result.Email,
+		Description:   result.Description,
+		Storage:       result.Properties.Storage,
+		DynamicInvoke: result.Properties.DynamicInvoke,
+	}
+	var err error
+	if contract.Hash, err = neoutils.ParseHASH160(result.Hash); err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetContractState hash %v`, err)}
+	}
+	if contract.Script, err = hex.DecodeString(result.Script); err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetContractState script %v`, err)}
+	}
+	return contract, nil
+}
+
+// GetAssetState 获取全局资产的信息
+func GetAssetState(url string, assetID string) (*AssetState, error) {
+	return NewClient(url).GetAssetState(context.Background(), assetID)
+}
+
+// GetAssetState 获取全局资产的信息，assetID 为大端序十六进制字符串（可以带0x前缀）
+func (c *Client) GetAssetState(ctx context.Context, assetID string) (*AssetState, error) {
+	result := &struct {
+		Version    int             `json:"version"`
+		ID         string          `json:"id"`
+		Type       string          `json:"type"`
+		Name       json.RawMessage `json:"name"`
+		Amount     neoutils.Fixed8 `json:"amount"`
+		Available  neoutils.Fixed8 `json:"available"`
+		Precision  int             `json:"precision"`
+		Owner      string          `json:"owner"`
+		Admin      string          `json:"admin"`
+		Issuer     string          `json:"issuer"`
+		Expiration uint32          `json:"expiration"`
+		Frozen     bool            `json:"frozen"`
+	}{}
+	if err := c.callResult(ctx, result, `getassetstate`, assetID); err != nil {
+		return nil, fmt.Errorf(`GetAssetState[%s] error: %w`, assetID, err)
+	}
+
+	asset := &AssetState{
+		Version:    result.Version,
+		Type:       result.Type,
+		Amount:     result.Amount,
+		Available:  result.Available,
+		Precision:  result.Precision,
+		Admin:      result.Admin,
+		Issuer:     result.Issuer,
+		Expiration: result.Expiration,
+		Frozen:     result.Frozen,
+	}
+	var err error
+	if asset.ID, err = neoutils.ParseHASH256(result.ID); err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetAssetState id %v`, err)}
+	}
+	if asset.Owner, err = hex.DecodeString(result.Owner); err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetAssetState owner %v`, err)}
+	}
+	if asset.Name, asset.Names, err = parseAssetName(result.Name); err != nil {
+		return nil, &DecodeError{Body: result.Name, Err: fmt.Errorf(`GetAssetState name %v`, err)}
+	}
+	return asset, nil
+}
+
+// parseAssetName 解析资产名称，可能是字符串，也可能是 [{"lang": "en", "name": "..."}] 格式的多语言名称
+func parseAssetName(raw json.RawMessage) (string, map[string]string, error) {
+	names := make(map[string]string)
+	name := ``
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return name, names, nil
+	}
+	items := make([]struct {
+		Lang string `json:"lang"`
+		Name string `json:"name"`
+	}, 0)
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return ``, nil, err
+	}
+	for _, item := range items {
+		names[item.Lang] = item.Name
+		if len(name) == 0 {
+			name = item.Name
+		}
+	}
+	if en, ok := names[`en`]; ok {
+		name = en
+	}
+	return name, names, nil
+}
+
+// GetValidators 获取共识节点候选人及其得票
+func GetValidators(url string) ([]*Validator, error) {
+	return NewClient(url).GetValidators(context.Background())
+}
+
+// GetValidators 获取共识节点候选人及其得票
+func (c *Client) GetValidators(ctx context.Context) ([]*Validator, error) {
+	result := make([]struct {
+		PublicKey string      `json:"publickey"`
+		Votes     json.Number `json:"votes"`
+		Active    bool        `json:"active"`
+	}, 0)
+	if err := c.callResult(ctx, &result, `getvalidators`); err != nil {
+		return nil, fmt.Errorf(`GetValidators error: %w`, err)
+	}
+	validators := make([]*Validator, 0, len(result))
+	for _, item := range result {
+		validator := &Validator{Active: item.Active}
+		var err error
+		if validator.PublicKey, err = hex.DecodeString(item.PublicKey); err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetValidators publickey %v`, err)}
+		}
+		if validator.Votes, err = item.Votes.Int64(); err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetValidators votes %v`, err)}
+		}
+		validators = append(validators, validator)
+	}
+	return validators, nil
+}
@@ -0,0 +1,234 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+func TestBlockchainRPC(t *testing.T) {
+	blockHash := "4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2"
+	contract := mustHASH160("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9")
+	output, _ := neotransaction.ParseAddress("AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+
+	runRPCCases(t, []rpcCase{
+		{
+			name:   "GetBestBlockHash",
+			method: "getbestblockhash",
+			result: `"0x773dd2dae4a9c9275290f89b56e67d7363ea4826dfd4fc13cc01cf73a44b0d0e"`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetBestBlockHash(ctx)
+			},
+			want: mustHASH256("773dd2dae4a9c9275290f89b56e67d7363ea4826dfd4fc13cc01cf73a44b0d0e"),
+		},
+		{
+			name:   "GetBlockHash",
+			method: "getblockhash",
+			result: `"0x` + blockHash + `"`,
+			params: `[10000]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetBlockHash(ctx, 10000)
+			},
+			want: mustHASH256(blockHash),
+		},
+		{
+			name:   "GetBlockHeader",
+			method: "getblockheader",
+			result: `{"hash":"0x` + blockHash + `","size":686,"version":0,` +
+				`"previousblockhash":"0x2d8c2b5f4a3e1c0d9b8a7f6e5d4c3b2a1908f7e6d5c4b3a29180f7e6d5c4b3a2",` +
+				`"merkleroot":"0xd6ba8b0f381897a59396394e9ce266a3d1d0857b6b18b9bb9d2d0bc5c3e1a3f1",` +
+				`"time":1476647382,"index":10000,"nonce":"7fd2ab3d1d59bd7a","nextconsensus":"APyEx5f4Zm4oCHwFWiSTaph1fPBxZacYVR",` +
+				`"script":{"invocation":"40a1b2c3d4","verification":"51ae"},"confirmations":3211370,` +
+				`"nextblockhash":"0x8a3fa0a11d1b1f3b43c2a8b1e5d7b9c0f2e4d6a8c0b2d4f6e8a0c2e4f6a8b0c2"}`,
+			params: `["` + blockHash + `",1]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetBlockHeader(ctx, blockHash)
+			},
+			want: &BlockHeader{
+				Hash:          mustHASH256(blockHash),
+				Size:          686,
+				PrevHash:      mustHASH256("2d8c2b5f4a3e1c0d9b8a7f6e5d4c3b2a1908f7e6d5c4b3a29180f7e6d5c4b3a2"),
+				MerkleRoot:    mustHASH256("d6ba8b0f381897a59396394e9ce266a3d1d0857b6b18b9bb9d2d0bc5c3e1a3f1"),
+				Time:          1476647382,
+				Index:         10000,
+				Nonce:         0x7fd2ab3d1d59bd7a,
+				NextConsensus: "APyEx5f4Zm4oCHwFWiSTaph1fPBxZacYVR",
+				Script:        Witness{Invocation: mustHex("40a1b2c3d4"), Verification: mustHex("51ae")},
+				Confirmations: 3211370,
+				NextBlockHash: mustHASH256("8a3fa0a11d1b1f3b43c2a8b1e5d7b9c0f2e4d6a8c0b2d4f6e8a0c2e4f6a8b0c2"),
+			},
+		},
+		{
+			name:   "GetBlockSysFee",
+			method: "getblocksysfee",
+			result: `"1140"`,
+			params: `[10000]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetBlockSysFee(ctx, 10000)
+			},
+			want: 1140 * neoutils.Fixed8One,
+		},
+		{
+			name:   "GetRawMempool",
+			method: "getrawmempool",
+			result: `["0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","0xb488ad25eb474f89d5ca3f985cc047ca96bc7373a6d3da8c0f192722896c1cd7"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetRawMempool(ctx)
+			},
+			want: []neoutils.HASH256{
+				mustHASH256("9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e"),
+				mustHASH256("b488ad25eb474f89d5ca3f985cc047ca96bc7373a6d3da8c0f192722896c1cd7"),
+			},
+		},
+		{
+			name:   "GetRawMempoolEmpty",
+			method: "getrawmempool",
+			result: `[]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetRawMempool(ctx)
+			},
+			want: []neoutils.HASH256{},
+		},
+		{
+			name:   "GetTxOut",
+			method: "gettxout",
+			result: `{"n":1,"asset":"0x602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7","value":"2950.5","address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}`,
+			params: `["f4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657",1]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetTxOut(ctx, "f4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657", 1)
+			},
+			want: &TxOutput{
+				N:          1,
+				AssetID:    mustHASH256(neotransaction.AssetGasID),
+				Value:      295050000000,
+				Address:    "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt",
+				ScriptHash: output.ScripHash,
+			},
+		},
+		{
+			name:   "GetTxOutSpent",
+			method: "gettxout",
+			result: `null`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetTxOut(ctx, "f4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657", 0)
+			},
+			want: (*TxOutput)(nil),
+		},
+		{
+			name:   "GetStorage",
+			method: "getstorage",
+			result: `"00e1f505"`,
+			params: `["ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9","746f74616c537570706c79"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetStorage(ctx, contract, []byte("totalSupply"))
+			},
+			want: mustHex("00e1f505"),
+		},
+		{
+			name:   "GetStorageMissing",
+			method: "getstorage",
+			result: `null`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetStorage(ctx, contract, []byte("missing"))
+			},
+			want: []byte(nil),
+		},
+		{
+			name:   "GetContractState",
+			method: "getcontractstate",
+			result: `{"version":0,"hash":"0xecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9","script":"00c56b6c766b00527ac46c766b00c3616c7566",` +
+				`"parameters":["String","Array"],"returntype":"ByteArray","name":"RPX Sale","code_version":"1","author":"Red Pulse",` +
+				`"email":"rpx@red-pulse.com","description":"RPX Sale","properties":{"storage":true,"dynamic_invoke":false}}`,
+			params: `["ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetContractState(ctx, contract)
+			},
+			want: &ContractState{
+				Hash:        contract,
+				Script:      mustHex("00c56b6c766b00527ac46c766b00c3616c7566"),
+				Parameters:  []string{"String", "Array"},
+				ReturnType:  "ByteArray",
+				Name:        "RPX Sale",
+				CodeVersion: "1",
+				Author:      "Red Pulse",
+				Email:       "rpx@red-pulse.com",
+				Description: "RPX Sale",
+				Storage:     true,
+			},
+		},
+		{
+			name:   "GetAssetStateNEO",
+			method: "getassetstate",
+			result: `{"version":0,"id":"0xc56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b","type":"GoverningToken",` +
+				`"name":[{"lang":"zh-CN","name":"小蚁股"},{"lang":"en","name":"AntShare"}],"amount":"100000000","available":"100000000",` +
+				`"precision":0,"owner":"00","admin":"Abf2qMs1pzQb8kYk9RuxtUb9jtRKJVuBJt","issuer":"Abf2qMs1pzQb8kYk9RuxtUb9jtRKJVuBJt",` +
+				`"expiration":4000000,"frozen":false}`,
+			params: `["` + neotransaction.AssetNeoID + `"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetAssetState(ctx, neotransaction.AssetNeoID)
+			},
+			want: &AssetState{
+				ID:         mustHASH256(neotransaction.AssetNeoID),
+				Type:       "GoverningToken",
+				Name:       "AntShare",
+				Names:      map[string]string{"zh-CN": "小蚁股", "en": "AntShare"},
+				Amount:     100000000 * neoutils.Fixed8One,
+				Available:  100000000 * neoutils.Fixed8One,
+				Owner:      []byte{0},
+				Admin:      "Abf2qMs1pzQb8kYk9RuxtUb9jtRKJVuBJt",
+				Issuer:     "Abf2qMs1pzQb8kYk9RuxtUb9jtRKJVuBJt",
+				Expiration: 4000000,
+			},
+		},
+		{
+			name:   "GetAssetStateToken",
+			method: "getassetstate",
+			result: `{"version":0,"id":"0x025d82f7b00a9ff1cfe709abe3c4741a105d067178e645bc3ebad9bc79af47d4","type":"Token","name":"TestCoin",` +
+				`"amount":"-0.00000001","available":"1234.56789012","precision":8,` +
+				`"owner":"02486fd15702c4490a26703112a5cc1d0923fd697a33406bd5a1c00e0013b09a70",` +
+				`"admin":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt","issuer":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt","expiration":2000000,"frozen":true}`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetAssetState(ctx, "025d82f7b00a9ff1cfe709abe3c4741a105d067178e645bc3ebad9bc79af47d4")
+			},
+			want: &AssetState{
+				ID:         mustHASH256("025d82f7b00a9ff1cfe709abe3c4741a105d067178e645bc3ebad9bc79af47d4"),
+				Type:       "Token",
+				Name:       "TestCoin",
+				Names:      map[string]string{},
+				Amount:     -1,
+				Available:  123456789012,
+				Precision:  8,
+				Owner:      mustHex("02486fd15702c4490a26703112a5cc1d0923fd697a33406bd5a1c00e0013b09a70"),
+				Admin:      "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt",
+				Issuer:     "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt",
+				Expiration: 2000000,
+				Frozen:     true,
+			},
+		},
+		{
+			name:   "GetValidators",
+			method: "getvalidators",
+			result: `[{"publickey":"02486fd15702c4490a26703112a5cc1d0923fd697a33406bd5a1c00e0013b09a70","votes":"46632420","active":true},` +
+				`{"publickey":"024c7b7fb6c310fccf1ba33b082519d82964ea93868d676662d4a59ad548df0e7d","votes":"0","active":false}]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetValidators(ctx)
+			},
+			want: []*Validator{
+				{PublicKey: mustHex("02486fd15702c4490a26703112a5cc1d0923fd697a33406bd5a1c00e0013b09a70"), Votes: 46632420, Active: true},
+				{PublicKey: mustHex("024c7b7fb6c310fccf1ba33b082519d82964ea93868d676662d4a59ad548df0e7d"), Votes: 0},
+			},
+		},
+	})
+}
+
+func TestBlockchainRPCDecodeError(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{"getbestblockhash": `"0x773dd2"`})
+	_, err := NewClient(node.URL).GetBestBlockHash(context.Background())
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError for a short hash, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package neocliapi
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CheckpointStore 保存 BlockFollower 已经处理完的区块高度，重启后从下一个区块继续
+type CheckpointStore interface {
+	// Load 返回最后处理完的区块高度，没有保存过时 ok 为false
+	Load() (height uint64, ok bool, err error)
+	// Save 保存最后处理完的区块高度
+	Save(height uint64) error
+}
+
+// MemoryCheckpoint 保存在内存中的检查点，进程重启后丢失
+type MemoryCheckpoint struct {
+	mu     sync.Mutex
+	height uint64
+	ok     bool
+}
+
+// Load 实现 CheckpointStore
+func (m *MemoryCheckpoint) Load() (uint64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.height, m.ok, nil
+}
+
+// Save 实现 CheckpointStore
+func (m *MemoryCheckpoint) Save(height uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.height = height
+	m.ok = true
+	return nil
+}
+
+// FileCheckpoint 以十进制文本保存在文件中的检查点
+type FileCheckpoint struct {
+	Path string
+}
+
+// Load 实现 CheckpointStore，文件不存在时返回 ok 为false
+func (f *FileCheckpoint) Load() (uint64, bool, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	height, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return height, true, nil
+}
+
+// Save 实现 CheckpointStore，先写入临时文件再改名，避免写入中断时文件损坏
+func (f *FileCheckpoint) Save(height uint64) error {
+	tmp := f.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatUint(height, 10)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.Path)
+}
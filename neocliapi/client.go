@@ -0,0 +1,235 @@
+package neocliapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Client 的默认参数
+const (
+	DefaultTimeout   = 60 * time.Second // 每次调用的超时时间
+	DefaultBatchSize = 100              // 批量调用时每个HTTP请求最多包含的调用数
+)
+
+// legacyTimeout FetchBalance、FetchBlock、FetchBlockHeight、FetchTX 这几个原有的包级别函数一直使用30秒超时，保持不变
+const legacyTimeout = 30 * time.Second
+
+// newLegacyClient 创建原有的30秒超时的包级别函数使用的客户端
+func newLegacyClient(url string) *Client {
+	return NewClient(url, WithTimeout(legacyTimeout), withSharedAssetCache())
+}
+
+// Client neo-cli 节点的 json rpc 客户端，可以在多个goroutine中共享使用
+// 包级别的函数（FetchBlock 等）每次调用都会创建一个新的 Client，超时时间与原有的实现一致，资产元数据的缓存按节点地址共享
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	header     http.Header
+	timeout    time.Duration
+	batchSize  int
+	assets     *assetCache // 资产元数据的缓存
+}
+
+// ClientOption 创建 Client 时的可选配置
+type ClientOption func(c *Client)
+
+// WithHTTPClient 使用指定的 http.Client，用于共享连接池、代理等
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport 使用指定的 http.RoundTripper，例如配置了 mTLS 的 http.Transport
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient = &http.Client{Transport: transport}
+	}
+}
+
+// WithHeader 每次请求都附加的http头，例如需要认证的节点的 Authorization
+func WithHeader(key string, value string) ClientOption {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithBasicAuth 使用 HTTP Basic 认证
+func WithBasicAuth(username string, password string) ClientOption {
+	return func(c *Client) {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, password)
+		c.header.Set(`Authorization`, req.Header.Get(`Authorization`))
+	}
+}
+
+// WithTimeout 每次调用的超时时间，为0时不设置超时（仍然受 context 控制）
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithBatchSize 批量调用时每个HTTP请求最多包含的调用数，小于1时不分批
+func WithBatchSize(size int) ClientOption {
+	return func(c *Client) {
+		c.batchSize = size
+	}
+}
+
+// NewClient 创建一个访问 endpoint 的客户端
+func NewClient(endpoint string, opts ...ClientOption) *Client {
+	c := &Client{
+		endpoint:   endpoint,
+		httpClient: http.DefaultClient,
+		header:     http.Header{},
+		timeout:    DefaultTimeout,
+		batchSize:  DefaultBatchSize,
+		assets:     &assetCache{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Endpoint 返回客户端访问的节点地址
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// errNoResult 应答的 result 为null，部分接口（如 getstorage、gettxout）用null表示不存在
+var errNoResult = errors.New(`no result`)
+
+// requestID 进程内递增的请求id，保证同一进程中发出的每个请求id都不同
+var requestID uint64
+
+// rpcRequest json rpc 请求
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      uint64        `json:"id"`
+}
+
+// newRPCRequest 创建一个分配了新id的请求，params 为nil时编码为空数组
+func newRPCRequest(method string, params []interface{}) *rpcRequest {
+	if params == nil {
+		params = []interface{}{}
+	}
+	return &rpcRequest{
+		JSONRPC: `2.0`,
+		Method:  method,
+		Params:  params,
+		ID:      atomic.AddUint64(&requestID, 1),
+	}
+}
+
+// rpcResponse json rpc 应答
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// checkID 检查应答的id与请求的id是否一致，节点无法解析请求时返回的错误应答id可以为null
+func (r *rpcResponse) checkID(id uint64) error {
+	if string(r.ID) == strconv.FormatUint(id, 10) {
+		return nil
+	}
+	if r.Error != nil && (len(r.ID) == 0 || string(r.ID) == `null`) {
+		return nil
+	}
+	return fmt.Errorf(`response id %s does not match request id %d`, r.ID, id)
+}
+
+// post 向节点发送json rpc请求，返回HTTP状态码和完整的应答数据，网络错误返回 TransportError
+func (c *Client) post(ctx context.Context, body []byte) (int, []byte, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, &TransportError{Endpoint: c.endpoint, Err: err}
+	}
+	for key, values := range c.header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	request.Header.Set(`Content-Type`, `application/json`)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return 0, nil, &TransportError{Endpoint: c.endpoint, Err: err}
+	}
+	defer response.Body.Close()
+
+	buff, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return response.StatusCode, nil, &TransportError{Endpoint: c.endpoint, StatusCode: response.StatusCode, Err: err}
+	}
+	return response.StatusCode, buff, nil
+}
+
+// call 调用节点的 method 方法，返回 result 字段的原始数据
+// 节点返回错误时返回 RPCError，应答无法解析或id不一致时返回 DecodeError，其他情况返回 TransportError
+func (c *Client) call(ctx context.Context, method string, params ...interface{}) (json.RawMessage, error) {
+	request := newRPCRequest(method, params)
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf(`encode %s request: %w`, method, err)
+	}
+	status, buff, err := c.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &rpcResponse{}
+	if err = json.Unmarshal(buff, ret); err != nil {
+		if status != http.StatusOK {
+			return nil, &TransportError{Endpoint: c.endpoint, StatusCode: status, Err: fmt.Errorf(`http status %d`, status)}
+		}
+		return nil, &DecodeError{Body: buff, Err: err}
+	}
+	return c.result(ret, request.ID, status, buff)
+}
+
+// result 检查应答的id并返回 result 字段的原始数据，body 为应答的原始数据，用于错误信息
+func (c *Client) result(ret *rpcResponse, id uint64, status int, body []byte) (json.RawMessage, error) {
+	if err := ret.checkID(id); err != nil {
+		return nil, &DecodeError{Body: body, Err: err}
+	}
+	if ret.Error != nil {
+		return nil, ret.Error
+	}
+	if len(ret.Result) == 0 || string(ret.Result) == `null` {
+		if status != http.StatusOK {
+			return nil, &TransportError{Endpoint: c.endpoint, StatusCode: status, Err: fmt.Errorf(`http status %d`, status)}
+		}
+		return nil, &DecodeError{Body: body, Err: errNoResult}
+	}
+	return ret.Result, nil
+}
+
+// callResult 调用节点的 method 方法并将 result 解析到 v，解析失败返回 DecodeError
+func (c *Client) callResult(ctx context.Context, v interface{}, method string, params ...interface{}) error {
+	result, err := c.call(ctx, method, params...)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(result, v); err != nil {
+		return &DecodeError{Body: result, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientTimeout(t *testing.T) {
+	if c := newLegacyClient("http://localhost"); c.timeout != 30*time.Second {
+		t.Fatalf("legacy helpers timeout %v, want 30s", c.timeout)
+	}
+	if c := NewClient("http://localhost"); c.timeout != DefaultTimeout {
+		t.Fatalf("default timeout %v, want %v", c.timeout, DefaultTimeout)
+	}
+	if c := NewClient("http://localhost", WithTimeout(time.Second)); c.timeout != time.Second {
+		t.Fatalf("timeout %v, want 1s", c.timeout)
+	}
+}
+
+func TestClientCallTimeout(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{"getblockcount": `100`})
+	node.setDelay(5 * time.Second)
+	start := time.Now()
+	_, err := NewClient(node.URL, WithTimeout(50*time.Millisecond)).call(context.Background(), "getblockcount")
+	transportErr := &TransportError{}
+	if !errors.As(err, &transportErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout TransportError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("call returned after %v", elapsed)
+	}
+
+	// 超时为0时只受 ctx 控制
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = NewClient(node.URL, WithTimeout(0)).call(ctx, "getblockcount")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("expected the ctx deadline, got %v after %v", err, time.Since(start))
+	}
+
+	// 每次调用单独计时，前一次调用的耗时不影响下一次
+	node.setDelay(30 * time.Millisecond)
+	client := NewClient(node.URL, WithTimeout(200*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if _, err = client.call(context.Background(), "getblockcount"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// roundTripFunc 记录请求的 http.RoundTripper
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientOptions(t *testing.T) {
+	var mu sync.Mutex
+	headers := []http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+		request := &syntheticRequest{}
+		json.NewDecoder(r.Body).Decode(request)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":100}`, request.ID)
+	}))
+	defer server.Close()
+	lastHeader := func() http.Header {
+		mu.Lock()
+		defer mu.Unlock()
+		return headers[len(headers)-1]
+	}
+
+	trips := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		trips++
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	if _, err := NewClient(server.URL, WithTransport(transport)).call(context.Background(), "getblockcount"); err != nil || trips != 1 {
+		t.Fatalf("WithTransport: %v round trips, %v", trips, err)
+	}
+	httpClient := &http.Client{Transport: transport}
+	if _, err := NewClient(server.URL, WithHTTPClient(httpClient)).call(context.Background(), "getblockcount"); err != nil || trips != 2 {
+		t.Fatalf("WithHTTPClient: %v round trips, %v", trips, err)
+	}
+
+	client := NewClient(server.URL, WithHeader("X-Api-Key", "key1"), WithHeader("X-Api-Key", "key2"), WithBasicAuth("neo", "secret"))
+	if _, err := client.call(context.Background(), "getblockcount"); err != nil {
+		t.Fatal(err)
+	}
+	header := lastHeader()
+	if values := header.Values("X-Api-Key"); len(values) != 2 || values[0] != "key1" || values[1] != "key2" {
+		t.Fatalf("X-Api-Key %v", values)
+	}
+	// "neo:secret" 的 base64
+	if auth := header.Get("Authorization"); auth != "Basic bmVvOnNlY3JldA==" {
+		t.Fatalf("Authorization %v", auth)
+	}
+	if contentType := header.Get("Content-Type"); contentType != "application/json" {
+		t.Fatalf("Content-Type %v", contentType)
+	}
+	// 批量调用同样附加http头
+	calls, _ := echoCalls(1)
+	calls[0].Result = nil
+	client.Batch(context.Background(), calls)
+	if auth := lastHeader().Get("Authorization"); auth != "Basic bmVvOnNlY3JldA==" {
+		t.Fatalf("batch Authorization %v", auth)
+	}
+	// 没有设置时不附加
+	if _, err := NewClient(server.URL).call(context.Background(), "getblockcount"); err != nil {
+		t.Fatal(err)
+	}
+	if auth := lastHeader().Get("Authorization"); auth != "" {
+		t.Fatalf("Authorization %v", auth)
+	}
+}
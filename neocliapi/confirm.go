@@ -0,0 +1,144 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// ErrTransactionDropped 交易曾经在节点的内存池中，之后既不在内存池也不在链上；或者超过 NotFoundTimeout 仍然查不到
+var ErrTransactionDropped = errors.New("transaction dropped")
+
+// ErrApplicationLogUnavailable 交易已经得到足够的确认，但重试 MaxLogRetries 次后仍取不到执行日志，
+// 通常是节点没有安装 ApplicationLogs 插件
+var ErrApplicationLogUnavailable = errors.New("application log unavailable")
+
+// DefaultMaxLogRetries 获取执行日志的默认重试次数
+const DefaultMaxLogRetries = 10
+
+// ConfirmOptions 等待交易确认的参数
+type ConfirmOptions struct {
+	Confirmations   uint32        // 需要的确认数，交易所在的区块为1个确认，为0时按1处理
+	PollInterval    time.Duration // 查询间隔，为0时使用 DefaultPollInterval
+	Timeout         time.Duration // 总的等待时间，为0时只受 ctx 控制
+	NotFoundTimeout time.Duration // 节点一直查不到交易多久后视为已丢弃，为0时一直等待
+	MaxLogRetries   int           // 合约调用交易确认后获取执行日志失败的最大重试次数，为0时使用 DefaultMaxLogRetries
+}
+
+// Confirmation 交易的确认信息
+type Confirmation struct {
+	Transaction   *Transaction
+	Height        uint64
+	BlockHash     neoutils.HASH256
+	Confirmations uint32
+
+	// InvocationTransaction 的执行结果
+	Log            *ApplicationLog
+	Stack          []Argument // 最后一次执行的返回值
+	GasConsumed    neoutils.Fixed8
+	ExecutionError error // 合约执行失败(FAULT)时不为nil
+}
+
+// WaitForConfirmation 等待交易得到 confirmations 个确认，timeout 为0时一直等待
+func WaitForConfirmation(url string, txid string, confirmations uint32, timeout time.Duration) (*Confirmation, error) {
+	return NewClient(url).WaitForConfirmation(context.Background(), txid, ConfirmOptions{
+		Confirmations: confirmations,
+		Timeout:       timeout,
+	})
+}
+
+// WaitForConfirmation 轮询交易直到它所在的区块得到足够的确认，返回区块高度、哈希，以及合约调用交易的执行结果
+// 超时返回包装了 context.DeadlineExceeded 的错误，交易被丢弃返回包装了 ErrTransactionDropped 的错误，
+// 取不到执行日志返回包装了 ErrApplicationLogUnavailable 的错误，即使 Timeout 为0也不会一直等待执行日志
+func (c *Client) WaitForConfirmation(ctx context.Context, txid string, opts ConfirmOptions) (*Confirmation, error) {
+	if opts.Confirmations == 0 {
+		opts.Confirmations = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.MaxLogRetries <= 0 {
+		opts.MaxLogRetries = DefaultMaxLogRetries
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	seen := false
+	logFailures := 0
+	var lastErr error
+	for {
+		confirmation, err := c.checkConfirmation(ctx, txid, opts.Confirmations)
+		switch {
+		case err == nil && confirmation != nil:
+			return confirmation, nil
+		case err == nil:
+			seen = true
+		case IsRPCError(err, ErrCodeUnknownItem):
+			if seen || (opts.NotFoundTimeout > 0 && time.Since(start) >= opts.NotFoundTimeout) {
+				return nil, fmt.Errorf(`WaitForConfirmation[%s]: %w`, txid, ErrTransactionDropped)
+			}
+		case errors.Is(err, ErrApplicationLogUnavailable):
+			logFailures++
+			if logFailures > opts.MaxLogRetries {
+				return nil, fmt.Errorf(`WaitForConfirmation[%s]: %w`, txid, err)
+			}
+			lastErr = err
+		default:
+			lastErr = err
+		}
+
+		if !sleep(ctx, opts.PollInterval) {
+			if lastErr != nil {
+				return nil, fmt.Errorf(`WaitForConfirmation[%s]: %w (last error: %v)`, txid, ctx.Err(), lastErr)
+			}
+			return nil, fmt.Errorf(`WaitForConfirmation[%s]: %w`, txid, ctx.Err())
+		}
+	}
+}
+
+// checkConfirmation 查询一次交易，还没有足够确认时返回nil
+func (c *Client) checkConfirmation(ctx context.Context, txid string, confirmations uint32) (*Confirmation, error) {
+	tx, err := c.GetTransaction(ctx, txid)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.BlockHash) == 0 || tx.Confirmations < confirmations {
+		return nil, nil
+	}
+	header, err := c.GetBlockHeader(ctx, tx.BlockHash.ToHexString())
+	if err != nil {
+		return nil, err
+	}
+	confirmation := &Confirmation{
+		Transaction:   tx,
+		Height:        uint64(header.Index),
+		BlockHash:     tx.BlockHash,
+		Confirmations: tx.Confirmations,
+	}
+	if tx.Type == neotransaction.InvocationTransacton {
+		log, err := c.GetApplicationLog(ctx, txid)
+		if err != nil {
+			// 节点可能还没有生成执行日志，稍后重试；不包装原错误，避免被当作交易不存在
+			return nil, fmt.Errorf(`%w: %v`, ErrApplicationLogUnavailable, err)
+		}
+		confirmation.Log = log
+		for _, execution := range log.Executions {
+			confirmation.Stack = execution.Stack
+			if confirmation.GasConsumed, err = confirmation.GasConsumed.Add(execution.GasConsumed); err != nil {
+				return nil, &DecodeError{Err: fmt.Errorf(`gas_consumed %v`, err)}
+			}
+			if execution.Faulted() && confirmation.ExecutionError == nil {
+				confirmation.ExecutionError = fmt.Errorf(`execution[%s] eval state "%s"`, execution.Trigger, execution.VMState)
+			}
+		}
+	}
+	return confirmation, nil
+}
@@ -0,0 +1,165 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+const (
+	confirmTxID      = "9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e"
+	confirmBlockHash = "4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2"
+)
+
+// confirmTxJSON 返回 getrawtransaction 的应答，confirmations 为0时交易还在内存池中
+func confirmTxJSON(txType string, confirmations uint32) string {
+	extra := ``
+	if txType == "InvocationTransaction" {
+		extra = `"script":"00c1046e616d6567f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec","gas":"0",`
+	}
+	block := ``
+	if confirmations > 0 {
+		block = fmt.Sprintf(`,"blockhash":"0x%s","confirmations":%d,"blocktime":1476647382`, confirmBlockHash, confirmations)
+	}
+	return `{"txid":"0x` + confirmTxID + `","size":60,"type":"` + txType + `","version":1,"attributes":[],"vin":[],"vout":[],` +
+		extra + `"sys_fee":"0","net_fee":"0","scripts":[]` + block + `}`
+}
+
+// confirmNode 返回 getrawtransaction 依次使用 txs 中的应答的节点，最后一个应答一直重复
+func confirmNode(t *testing.T, txs ...string) *syntheticNode {
+	node := newSyntheticNode(t, map[string]string{
+		"getblockheader": `{"hash":"0x` + confirmBlockHash + `","size":686,"version":0,` +
+			`"previousblockhash":"0x2d8c2b5f4a3e1c0d9b8a7f6e5d4c3b2a1908f7e6d5c4b3a29180f7e6d5c4b3a2",` +
+			`"merkleroot":"0xd6ba8b0f381897a59396394e9ce266a3d1d0857b6b18b9bb9d2d0bc5c3e1a3f1",` +
+			`"time":1476647382,"index":10000,"nonce":"7fd2ab3d1d59bd7a","nextconsensus":"APyEx5f4Zm4oCHwFWiSTaph1fPBxZacYVR",` +
+			`"script":{"invocation":"40a1b2c3d4","verification":"51ae"},"confirmations":3}`,
+	})
+	calls := 0
+	node.handle("getrawtransaction", func(params []json.RawMessage) string {
+		tx := txs[len(txs)-1]
+		if calls < len(txs) {
+			tx = txs[calls]
+		}
+		calls++
+		return tx
+	})
+	return node
+}
+
+func waitConfirmation(node *syntheticNode, opts ConfirmOptions) (*Confirmation, error) {
+	opts.PollInterval = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return NewClient(node.URL).WaitForConfirmation(ctx, confirmTxID, opts)
+}
+
+func TestWaitForConfirmationThreshold(t *testing.T) {
+	node := confirmNode(t,
+		confirmTxJSON("ContractTransaction", 0),
+		confirmTxJSON("ContractTransaction", 1),
+		confirmTxJSON("ContractTransaction", 2),
+		confirmTxJSON("ContractTransaction", 3),
+	)
+	confirmation, err := waitConfirmation(node, ConfirmOptions{Confirmations: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confirmation.Confirmations != 3 || confirmation.Height != 10000 || confirmation.BlockHash.ToHexString() != confirmBlockHash ||
+		confirmation.Log != nil || confirmation.ExecutionError != nil {
+		t.Fatalf("confirmation %+v", confirmation)
+	}
+	// 确认数达到要求后才查询区块头
+	if calls := node.count("getrawtransaction"); calls != 4 {
+		t.Fatalf("getrawtransaction called %v times", calls)
+	}
+	if calls := node.count("getblockheader"); calls != 1 {
+		t.Fatalf("getblockheader called %v times", calls)
+	}
+	if calls := node.count("getapplicationlog"); calls != 0 {
+		t.Fatalf("getapplicationlog called %v times", calls)
+	}
+}
+
+func TestWaitForConfirmationDropped(t *testing.T) {
+	// 交易先在内存池中，之后节点查不到了
+	node := confirmNode(t,
+		confirmTxJSON("ContractTransaction", 0),
+		confirmTxJSON("ContractTransaction", 0),
+		`error:{"code":-100,"message":"Unknown transaction"}`,
+	)
+	_, err := waitConfirmation(node, ConfirmOptions{})
+	if !errors.Is(err, ErrTransactionDropped) {
+		t.Fatalf("expected ErrTransactionDropped, got %v", err)
+	}
+	if calls := node.count("getrawtransaction"); calls != 3 {
+		t.Fatalf("getrawtransaction called %v times", calls)
+	}
+
+	// 从没见过的交易在 NotFoundTimeout 之前继续等待
+	node = confirmNode(t,
+		`error:{"code":-100,"message":"Unknown transaction"}`,
+		`error:{"code":-100,"message":"Unknown transaction"}`,
+		confirmTxJSON("ContractTransaction", 1),
+	)
+	if _, err = waitConfirmation(node, ConfirmOptions{NotFoundTimeout: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	node = confirmNode(t, `error:{"code":-100,"message":"Unknown transaction"}`)
+	if _, err = waitConfirmation(node, ConfirmOptions{NotFoundTimeout: 10 * time.Millisecond}); !errors.Is(err, ErrTransactionDropped) {
+		t.Fatalf("expected ErrTransactionDropped after NotFoundTimeout, got %v", err)
+	}
+}
+
+func TestWaitForConfirmationFault(t *testing.T) {
+	node := confirmNode(t, confirmTxJSON("InvocationTransaction", 1))
+	node.set("getapplicationlog", `{"txid":"0x`+confirmTxID+`","executions":[`+
+		`{"trigger":"Application","contract":"0x3f6a0e4d0e4b6de28ab8d4e7d3e7c4b4f9c8b8a4","vmstate":"FAULT, BREAK","gas_consumed":"2.855",`+
+		`"stack":[{"type":"Integer","value":"1"}],"notifications":[]}]}`)
+	confirmation, err := waitConfirmation(node, ConfirmOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 执行失败不是 WaitForConfirmation 的错误，交易已经上链并消耗了GAS
+	if confirmation.ExecutionError == nil || confirmation.Log == nil || !confirmation.Log.Faulted() {
+		t.Fatalf("FAULT not reported: %+v", confirmation)
+	}
+	if confirmation.GasConsumed.String() != "2.855" || len(confirmation.Stack) != 1 || confirmation.Stack[0].Value != "1" {
+		t.Fatalf("execution result %+v", confirmation)
+	}
+
+	node.set("getapplicationlog", `{"txid":"0x`+confirmTxID+`","executions":[`+
+		`{"trigger":"Application","contract":"0x3f6a0e4d0e4b6de28ab8d4e7d3e7c4b4f9c8b8a4","vmstate":"HALT, BREAK","gas_consumed":"0.1",`+
+		`"stack":[],"notifications":[]}]}`)
+	if confirmation, err = waitConfirmation(node, ConfirmOptions{}); err != nil || confirmation.ExecutionError != nil {
+		t.Fatalf("HALT execution: %+v %v", confirmation, err)
+	}
+}
+
+func TestWaitForConfirmationLogUnavailable(t *testing.T) {
+	// 节点没有安装 ApplicationLogs 插件，Timeout 为0时也不会一直等待
+	node := confirmNode(t, confirmTxJSON("InvocationTransaction", 1))
+	_, err := waitConfirmation(node, ConfirmOptions{MaxLogRetries: 2})
+	if !errors.Is(err, ErrApplicationLogUnavailable) || errors.Is(err, ErrTransactionDropped) || IsRPCError(err, ErrCodeMethodNotFound) {
+		t.Fatalf("expected ErrApplicationLogUnavailable, got %v", err)
+	}
+	if calls := node.count("getapplicationlog"); calls != 3 {
+		t.Fatalf("getapplicationlog called %v times", calls)
+	}
+
+	// 执行日志稍后生成时继续等待
+	node = confirmNode(t, confirmTxJSON("InvocationTransaction", 1))
+	logs := 0
+	node.handle("getapplicationlog", func(params []json.RawMessage) string {
+		logs++
+		if logs < 3 {
+			return `error:{"code":-100,"message":"Unknown transaction"}`
+		}
+		return `{"txid":"0x` + confirmTxID + `","executions":[{"trigger":"Application","vmstate":"HALT","gas_consumed":"0","stack":[],"notifications":[]}]}`
+	})
+	if _, err = waitConfirmation(node, ConfirmOptions{MaxLogRetries: 2}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,279 @@
+package neocliapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// AddressSet 一组需要监听的地址（ScriptHash），可以在监听过程中随时修改
+type AddressSet struct {
+	mu     sync.RWMutex
+	hashes map[string]struct{}
+}
+
+// NewAddressSet 使用一组 ScriptHash 创建地址集合
+func NewAddressSet(hashes ...neoutils.HASH160) *AddressSet {
+	set := &AddressSet{hashes: make(map[string]struct{})}
+	set.Add(hashes...)
+	return set
+}
+
+// Add 添加地址
+func (s *AddressSet) Add(hashes ...neoutils.HASH160) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hash := range hashes {
+		s.hashes[string(hash)] = struct{}{}
+	}
+}
+
+// AddAddress 添加地址
+func (s *AddressSet) AddAddress(addrs ...*neotransaction.Address) {
+	for _, addr := range addrs {
+		s.Add(addr.ScripHash)
+	}
+}
+
+// Remove 删除地址
+func (s *AddressSet) Remove(hashes ...neoutils.HASH160) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hash := range hashes {
+		delete(s.hashes, string(hash))
+	}
+}
+
+// Replace 用新的一组地址替换集合中的所有地址，用于重新加载地址列表
+func (s *AddressSet) Replace(hashes ...neoutils.HASH160) {
+	m := make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		m[string(hash)] = struct{}{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hashes = m
+}
+
+// Contains 判断地址是否在集合中
+func (s *AddressSet) Contains(hash neoutils.HASH160) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.hashes[string(hash)]
+	return ok
+}
+
+// Len 返回集合中的地址数
+func (s *AddressSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.hashes)
+}
+
+// Deposit 监听地址收到的一笔资产
+type Deposit struct {
+	TxID          neoutils.HASH256
+	Height        uint64
+	BlockHash     neoutils.HASH256
+	BlockTime     uint32
+	Confirmations uint32 // 获取区块时的确认数
+	ScriptHash    neoutils.HASH160
+	Address       string
+	NEP5          bool
+	AssetID       neoutils.HASH256 // UTXO资产（NEO、GAS等）的资产ID
+	Contract      neoutils.HASH160 // NEP-5资产的合约ScriptHash
+	Amount        *big.Int         // UTXO资产以 10e-8 为单位，NEP-5资产为合约的最小单位
+	Index         int              // UTXO资产为输出序号，NEP-5资产为通知序号
+}
+
+// DepositEventType 充值事件类型
+type DepositEventType int
+
+// 充值事件类型
+const (
+	DepositReceived DepositEventType = iota // 收到充值
+	DepositReverted                         // 充值所在的区块被回滚，需要撤销
+)
+
+func (t DepositEventType) String() string {
+	switch t {
+	case DepositReceived:
+		return "DepositReceived"
+	case DepositReverted:
+		return "DepositReverted"
+	}
+	return fmt.Sprintf("DepositEventType(%d)", int(t))
+}
+
+// DepositEvent 充值事件
+type DepositEvent struct {
+	Type    DepositEventType
+	Deposit *Deposit
+}
+
+// DepositHandler 处理充值事件，返回错误时会等待一段时间后从当前区块重新处理
+type DepositHandler func(ctx context.Context, event *DepositEvent) error
+
+// DepositSource 充值监听的数据来源，Client 和 EndpointPool 都实现了此接口
+type DepositSource interface {
+	BlockSource
+	GetNEP5Transfers(ctx context.Context, txid string) ([]*NEP5Transfer, error)
+}
+
+// DepositWatcher 监听一组地址收到的UTXO资产和NEP-5资产
+// 使用 BlockFollower 逐个扫描区块，区块回滚时对其中的充值发出 DepositReverted 事件
+// 用于回滚的充值只保存在内存中，最多保存跟踪器最近 MaxReorgDepth 个区块的充值；
+// 进程重启后从检查点继续时，重启前处理的区块被回滚不会发出 DepositReverted 事件，
+// 需要设置足够的 Confirmations，或由 handler 自行记录充值所在的区块哈希并核对
+type DepositWatcher struct {
+	Source    DepositSource
+	Addresses *AddressSet
+	Follower  *BlockFollower // 可以设置起始高度、确认数、检查点等
+	ScanNEP5  bool           // 是否扫描 InvocationTransaction 的NEP-5 transfer通知，需要节点安装 ApplicationLogs 插件
+
+	mu       sync.Mutex
+	deposits map[uint64][]*Deposit // 最近区块中的充值，用于回滚
+}
+
+// NewDepositWatcher 创建一个从 startHeight 开始监听 addresses 的充值监听器
+func NewDepositWatcher(source DepositSource, addresses *AddressSet, startHeight uint64) *DepositWatcher {
+	return &DepositWatcher{
+		Source:    source,
+		Addresses: addresses,
+		Follower:  NewBlockFollower(source, startHeight),
+		ScanNEP5:  true,
+	}
+}
+
+// scan 扫描区块中转入监听地址的资产
+func (w *DepositWatcher) scan(ctx context.Context, height uint64, block *Block) ([]*Deposit, error) {
+	deposits := make([]*Deposit, 0)
+	newDeposit := func(tx *Transaction, scriptHash neoutils.HASH160) *Deposit {
+		deposit := &Deposit{
+			TxID:          tx.TxID,
+			Height:        height,
+			BlockHash:     block.Hash,
+			BlockTime:     block.Time,
+			Confirmations: block.Confirmations,
+			ScriptHash:    scriptHash,
+		}
+		if addr, err := neotransaction.ParseAddressHash(scriptHash); err == nil {
+			deposit.Address = addr.Addr
+		}
+		return deposit
+	}
+
+	for _, tx := range block.Transactions {
+		for _, output := range tx.Outputs {
+			if !w.Addresses.Contains(output.ScriptHash) {
+				continue
+			}
+			deposit := newDeposit(tx, output.ScriptHash)
+			deposit.AssetID = output.AssetID
+			deposit.Amount = output.Value.BigInt()
+			deposit.Index = int(output.N)
+			deposits = append(deposits, deposit)
+		}
+
+		if !w.ScanNEP5 || tx.Type != neotransaction.InvocationTransacton {
+			continue
+		}
+		transfers, err := w.Source.GetNEP5Transfers(ctx, tx.TxID.ToHexString())
+		if err != nil {
+			return nil, err
+		}
+		for _, transfer := range transfers {
+			if len(transfer.To) == 0 || !w.Addresses.Contains(transfer.To) {
+				continue
+			}
+			deposit := newDeposit(tx, transfer.To)
+			deposit.NEP5 = true
+			deposit.Contract = transfer.Contract
+			deposit.Amount = transfer.Amount
+			deposit.Index = transfer.Index
+			deposits = append(deposits, deposit)
+		}
+	}
+	return deposits, nil
+}
+
+// handle 将区块事件转换为充值事件
+func (w *DepositWatcher) handle(ctx context.Context, event *BlockEvent, handler DepositHandler) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.deposits == nil {
+		w.deposits = make(map[uint64][]*Deposit)
+	}
+
+	if event.Type == BlockRollback {
+		deposits := w.deposits[event.Height]
+		for i := len(deposits) - 1; i >= 0; i-- {
+			if err := handler(ctx, &DepositEvent{Type: DepositReverted, Deposit: deposits[i]}); err != nil {
+				// 已经撤销的充值不再重复发出
+				w.deposits[event.Height] = deposits[:i+1]
+				return err
+			}
+		}
+		delete(w.deposits, event.Height)
+		return nil
+	}
+
+	deposits, err := w.scan(ctx, event.Height, event.Block)
+	if err != nil {
+		return err
+	}
+	for _, deposit := range deposits {
+		if err := handler(ctx, &DepositEvent{Type: DepositReceived, Deposit: deposit}); err != nil {
+			return err
+		}
+	}
+	w.deposits[event.Height] = deposits
+	// 只保留跟踪器还可以回滚的区块中的充值
+	depth := uint64(w.Follower.reorgDepth())
+	for height := range w.deposits {
+		if height+depth <= event.Height {
+			delete(w.deposits, height)
+		}
+	}
+	return nil
+}
+
+// Run 阻塞地监听充值，直到 ctx 被取消或跟踪器停止，停止的条件见 BlockFollower.Run
+// 同一个区块中的充值在 handler 出错后会整体重新发出，handler 需要按 TxID 和 Index 去重
+func (w *DepositWatcher) Run(ctx context.Context, handler DepositHandler) error {
+	return w.Follower.Run(ctx, func(ctx context.Context, event *BlockEvent) error {
+		return w.handle(ctx, event, handler)
+	})
+}
+
+// Start 在新的goroutine中监听充值，返回事件和错误的channel，ctx 被取消或跟踪器停止后两个channel都会关闭
+// 错误channel有缓冲，消费不及时的错误会被丢弃，但导致跟踪器停止的错误一定会发送
+func (w *DepositWatcher) Start(ctx context.Context) (<-chan *DepositEvent, <-chan error) {
+	events := make(chan *DepositEvent)
+	errs := make(chan error, 16)
+	go func() {
+		defer close(events)
+		defer close(errs)
+		err := w.Follower.run(ctx, func(ctx context.Context, event *BlockEvent) error {
+			return w.handle(ctx, event, func(ctx context.Context, event *DepositEvent) error {
+				select {
+				case events <- event:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+		sendStopError(ctx, errs, err)
+	}()
+	return events, errs
+}
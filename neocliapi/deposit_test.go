@@ -0,0 +1,212 @@
+package neocliapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// depositChain 在 memoryChain 的区块中加入交易和 NEP-5 transfer 通知
+type depositChain struct {
+	*memoryChain
+	transfers map[string][]*NEP5Transfer // key 为交易ID
+}
+
+func newDepositChain(count int) *depositChain {
+	chain := &depositChain{memoryChain: &memoryChain{}, transfers: make(map[string][]*NEP5Transfer)}
+	chain.extend(0, count, 0)
+	return chain
+}
+
+// addTx 在高度为 height 的区块中加入交易
+func (c *depositChain) addTx(height uint64, tx *Transaction, transfers ...*NEP5Transfer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	block := c.blocks[height]
+	block.Transactions = append(block.Transactions, tx)
+	c.transfers[tx.TxID.ToHexString()] = transfers
+}
+
+func (c *depositChain) GetNEP5Transfers(ctx context.Context, txid string) ([]*NEP5Transfer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.transfers[txid], nil
+}
+
+func mustAddressHash(addr string) neoutils.HASH160 {
+	address, err := neotransaction.ParseAddress(addr)
+	if err != nil {
+		panic(err)
+	}
+	return address.ScripHash
+}
+
+var (
+	depositAddrA = mustAddressHash("AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	depositAddrB = mustAddressHash("AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt")
+	depositNEO   = mustHASH256(neotransaction.AssetNeoID)
+)
+
+// contractTx 返回向每个 ScriptHash 转入 1 NEO 的交易
+func contractTx(id int, hashes ...neoutils.HASH160) *Transaction {
+	tx := &Transaction{TxID: mustHASH256(fmt.Sprintf("%064x", id)), Type: neotransaction.ContractTransaction}
+	for i, hash := range hashes {
+		tx.Outputs = append(tx.Outputs, TxOutput{N: uint16(i), AssetID: depositNEO, Value: neoutils.Fixed8One, ScriptHash: hash})
+	}
+	return tx
+}
+
+// watchDeposits 运行 watcher，handler 收到第 n 个事件后调用 change(n)，收到 total 个事件后停止
+// 返回每个事件的描述：类型 高度 地址 资产 序号
+func watchDeposits(t *testing.T, watcher *DepositWatcher, total int, change func(n int)) []string {
+	watcher.Follower.PollInterval = time.Millisecond
+	watcher.Follower.MinBackoff = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := []string{}
+	err := watcher.Run(ctx, func(ctx context.Context, event *DepositEvent) error {
+		d := event.Deposit
+		asset := d.AssetID.ToHexString()
+		if d.NEP5 {
+			asset = d.Contract.ToHexString()
+		}
+		asset = asset[:4]
+		events = append(events, fmt.Sprintf("%v %v %v %v/%v %v", event.Type, d.Height, d.Address, asset, d.Amount, d.Index))
+		if change != nil {
+			change(len(events))
+		}
+		if len(events) == total {
+			cancel()
+		}
+		return nil
+	})
+	if len(events) != total {
+		t.Fatalf("got %v events before %v: %v", len(events), err, events)
+	}
+	return events
+}
+
+func checkEvents(t *testing.T, events []string, want []string) {
+	t.Helper()
+	if len(events) != len(want) {
+		t.Fatalf("events\n%v\nwant\n%v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("event %v: %v\nwant %v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestDepositWatcherScan(t *testing.T) {
+	chain := newDepositChain(4)
+	chain.addTx(1, contractTx(1, depositAddrB, depositAddrA))
+	invocation := &Transaction{TxID: mustHASH256(fmt.Sprintf("%064x", 2)), Type: neotransaction.InvocationTransacton}
+	chain.addTx(2, invocation,
+		&NEP5Transfer{Contract: rpxContract, From: depositAddrB, To: depositAddrA, Amount: big.NewInt(5), Index: 0},
+		&NEP5Transfer{Contract: rpxContract, From: depositAddrA, To: depositAddrB, Amount: big.NewInt(7), Index: 1},
+		// 销毁没有接收方
+		&NEP5Transfer{Contract: rpxContract, From: depositAddrA, Amount: big.NewInt(1), Index: 2},
+		&NEP5Transfer{Contract: rpxContract, To: depositAddrA, Amount: big.NewInt(9), Index: 3},
+	)
+	chain.addTx(3, contractTx(3, depositAddrA))
+
+	watcher := NewDepositWatcher(chain, NewAddressSet(depositAddrA), 0)
+	watcher.Follower.MaxReorgDepth = 2
+	events := watchDeposits(t, watcher, 4, nil)
+	checkEvents(t, events, []string{
+		"DepositReceived 1 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 1",
+		"DepositReceived 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt ecc6/5 0",
+		"DepositReceived 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt ecc6/9 3",
+		"DepositReceived 3 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+	})
+
+	// 只保留最近 MaxReorgDepth 个区块的充值
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	if len(watcher.deposits) != 2 || watcher.deposits[2] == nil || watcher.deposits[3] == nil {
+		t.Fatalf("kept deposits of %v blocks: %v", len(watcher.deposits), watcher.deposits)
+	}
+}
+
+func TestDepositWatcherScanUTXOOnly(t *testing.T) {
+	chain := newDepositChain(3)
+	invocation := &Transaction{TxID: mustHASH256(fmt.Sprintf("%064x", 1)), Type: neotransaction.InvocationTransacton}
+	chain.addTx(1, invocation, &NEP5Transfer{Contract: rpxContract, To: depositAddrA, Amount: big.NewInt(5)})
+	chain.addTx(2, contractTx(2, depositAddrA))
+
+	watcher := NewDepositWatcher(chain, NewAddressSet(depositAddrA), 0)
+	watcher.ScanNEP5 = false
+	events := watchDeposits(t, watcher, 1, nil)
+	checkEvents(t, events, []string{"DepositReceived 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0"})
+}
+
+func TestDepositWatcherAddressReload(t *testing.T) {
+	chain := newDepositChain(4)
+	for height := uint64(1); height <= 3; height++ {
+		chain.addTx(height, contractTx(int(height), depositAddrA, depositAddrB))
+	}
+	addresses := NewAddressSet(depositAddrA)
+	watcher := NewDepositWatcher(chain, addresses, 0)
+	// 处理区块1时重新加载地址列表，从区块2开始只监听B
+	events := watchDeposits(t, watcher, 3, func(n int) {
+		if n == 1 {
+			addresses.Replace(depositAddrB)
+		}
+	})
+	checkEvents(t, events, []string{
+		"DepositReceived 1 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+		"DepositReceived 2 AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt c56f/100000000 1",
+		"DepositReceived 3 AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt c56f/100000000 1",
+	})
+	if addresses.Contains(depositAddrA) || !addresses.Contains(depositAddrB) || addresses.Len() != 1 {
+		t.Fatal("address set not replaced")
+	}
+}
+
+func TestDepositWatcherRevert(t *testing.T) {
+	chain := newDepositChain(4)
+	chain.addTx(1, contractTx(1, depositAddrA))
+	chain.addTx(2, contractTx(2, depositAddrA, depositAddrA))
+	chain.addTx(3, contractTx(3, depositAddrA))
+
+	watcher := NewDepositWatcher(chain, NewAddressSet(depositAddrA), 0)
+	watcher.Follower.MaxReorgDepth = 3
+	// 收到区块3的充值后区块2、3被替换，新的区块2中有一笔不同的充值
+	events := watchDeposits(t, watcher, 8, func(n int) {
+		if n == 4 {
+			chain.extend(2, 3, 1)
+			chain.addTx(2, contractTx(4, depositAddrB, depositAddrA))
+		}
+	})
+	// 从高到低撤销，同一个区块中的充值按相反的顺序撤销
+	checkEvents(t, events, []string{
+		"DepositReceived 1 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+		"DepositReceived 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+		"DepositReceived 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 1",
+		"DepositReceived 3 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+		"DepositReverted 3 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+		"DepositReverted 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 1",
+		"DepositReverted 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 0",
+		"DepositReceived 2 AGofsxAUDwt52KjaB664GYsqVAkULYvKNt c56f/100000000 1",
+	})
+}
+
+func TestDepositWatcherDefaultDepth(t *testing.T) {
+	chain := newDepositChain(DefaultMaxReorgDepth + 20)
+	watcher := NewDepositWatcher(chain, NewAddressSet(depositAddrA), 0)
+	watcher.Follower.MaxReorgDepth = 0
+	chain.addTx(DefaultMaxReorgDepth+19, contractTx(1, depositAddrA))
+	watchDeposits(t, watcher, 1, nil)
+	// MaxReorgDepth 为0时不会无限保存区块的充值
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	if len(watcher.deposits) != DefaultMaxReorgDepth {
+		t.Fatalf("kept deposits of %v blocks", len(watcher.deposits))
+	}
+}
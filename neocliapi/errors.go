@@ -0,0 +1,95 @@
+package neocliapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// neo-cli 2.x 返回的 json rpc 错误码
+const (
+	ErrCodeParseError     = -32700 // 请求不是合法的json
+	ErrCodeInvalidRequest = -32600 // 请求不是合法的json rpc请求
+	ErrCodeMethodNotFound = -32601 // 方法不存在，或者节点没有安装提供此方法的插件
+	ErrCodeInvalidParams  = -32602 // 参数错误
+	ErrCodeInternalError  = -32603 // 节点内部错误
+
+	ErrCodeUnknownItem  = -100 // 交易、区块、资产或合约不存在
+	ErrCodeAccessDenied = -400 // 节点没有打开钱包
+
+	// sendrawtransaction 中继交易失败的原因
+	ErrCodeRelayUnknown   = -500 // 未知原因
+	ErrCodeAlreadyExists  = -501 // 交易已经在内存池或者链上
+	ErrCodeOutOfMemory    = -502 // 内存池已满，可以稍后重试
+	ErrCodeUnableToVerify = -503 // 无法验证，通常是引用的输入不存在
+	ErrCodeInvalid        = -504 // 交易无效，例如双花、签名错误、输入输出不平衡
+	ErrCodePolicyFail     = -505 // 不满足节点策略，例如手续费不足
+)
+
+// RPCError 节点返回的 json rpc 错误
+type RPCError struct {
+	Code    int64           `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// TransportError 请求没有得到节点的 json rpc 应答，例如网络错误、超时、HTTP状态码错误
+type TransportError struct {
+	Endpoint   string
+	StatusCode int // 没有收到HTTP应答时为0
+	Err        error
+}
+
+func (e *TransportError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("transport error %s: http status %d", e.Endpoint, e.StatusCode)
+	}
+	return fmt.Sprintf("transport error %s: %v", e.Endpoint, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError 节点的应答无法解析为期望的结果
+type DecodeError struct {
+	Body []byte
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode error: %v %s", e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError 批量调用中有部分调用失败，Errors 与调用一一对应，成功的调用为nil
+type BatchError struct {
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	failed := 0
+	var first error
+	for _, err := range e.Errors {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d batch calls failed, first error: %v", failed, len(e.Errors), first)
+}
+
+// IsRPCError 判断 err 是否为错误码为 code 的 RPCError（可以是被包装过的错误）
+func IsRPCError(err error, code int64) bool {
+	rpcErr := &RPCError{}
+	return errors.As(err, &rpcErr) && rpcErr.Code == code
+}
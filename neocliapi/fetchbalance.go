@@ -1,85 +1,104 @@
 package neocliapi
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"io/ioutil"
-	"net/http"
-	"strconv"
-	"strings"
-	"time"
+	"fmt"
+	"math/big"
 
-	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
 )
 
-// NeoBalance 用户NEO账户余额
-type NeoBalance map[string]float64
+// Balance 账户持有的一种资产的余额
+type Balance struct {
+	Asset            *AssetInfo
+	Amount           *big.Int // 以资产的最小单位 10^-Asset.Decimals 计
+	LastUpdatedBlock uint32   // NEP-5余额最后变化的区块高度，全局资产为0
+}
 
-// FetchBalance 获取账户余额
-func FetchBalance(url string, addr string) (NeoBalance, error) {
-	reader := strings.NewReader(`{
-		"jsonrpc": "2.0",
-		"method": "getaccountstate",
-		"params": ["` + addr + `"],
-		"id": 1
-	}`)
-	client := http.Client{Timeout: 30 * time.Second}
-	response, err := client.Post(url, "application/json", reader)
+// String 将余额格式化为十进制金额，如 "1.5"
+func (b *Balance) String() string {
+	return neoutils.FormatDecimal(b.Amount, b.Asset.Decimals)
+}
 
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
+// Balances 账户余额，key 为 AssetInfo.Key，即全局资产ID或NEP-5合约的大端序十六进制字符串
+type Balances map[string]*Balance
 
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
+// Asset 返回全局资产的余额，没有持有时为nil
+func (b Balances) Asset(assetID neoutils.HASH256) *Balance {
+	return b[assetID.ToHexString()]
+}
 
-	ret := make(map[string]interface{})
-	if err = json.Unmarshal(buff, &ret); err != nil {
-		return nil, err
-	}
+// NEP5 返回NEP-5资产的余额，没有持有时为nil
+func (b Balances) NEP5(contract neoutils.HASH160) *Balance {
+	return b[contract.ToHexString()]
+}
 
-	result, ok := ret[`result`].(map[string]interface{})
-	if !ok {
-		return nil, errors.New(`no result`)
-	}
+// accountStateJSON getaccountstate 的返回结果
+type accountStateJSON struct {
+	Balances []struct {
+		Asset string          `json:"asset"`
+		Value neoutils.Fixed8 `json:"value"`
+	} `json:"balances"`
+}
+
+// FetchBalance 获取账户持有的全局资产（NEO、GAS等UTXO资产）余额
+func FetchBalance(url string, addr string) (Balances, error) {
+	return newLegacyClient(url).FetchBalance(context.Background(), addr)
+}
 
-	balances, ok := result[`balances`].([]interface{})
-	if !ok {
-		return nil, errors.New(`no balances`)
+// FetchBalance 获取账户持有的全局资产余额，资产的元数据通过 GetAssetInfo 获取并缓存
+func (c *Client) FetchBalance(ctx context.Context, addr string) (Balances, error) {
+	result := &accountStateJSON{}
+	if err := c.callResult(ctx, result, `getaccountstate`, addr); err != nil {
+		return nil, err
 	}
+	return c.parseBalance(ctx, result)
+}
 
-	neobalance := make(map[string]float64)
-	neobalance[`NEO`] = 0
-	neobalance[`GAS`] = 0
+// parseBalance 解析 getaccountstate 的结果
+func (c *Client) parseBalance(ctx context.Context, result *accountStateJSON) (Balances, error) {
+	if result.Balances == nil {
+		return nil, &DecodeError{Err: errors.New(`no balances`)}
+	}
 
-	for _, i := range balances {
-		balance, ok := i.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		asset, ok := balance[`asset`].(string)
-		if !ok {
-			continue
-		}
-		value, ok := balance[`value`].(string)
-		if !ok {
-			continue
+	balances := make(Balances, len(result.Balances))
+	for _, item := range result.Balances {
+		assetID, err := neoutils.ParseHASH256(item.Asset)
+		if err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`balance asset %v`, err)}
 		}
-		v, err := strconv.ParseFloat(value, 10)
+		info, err := c.GetAssetInfo(ctx, assetID)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		asset = strings.TrimPrefix(asset, `0x`)
-		switch asset {
-		case neotransaction.AssetNeoID:
-			neobalance[`NEO`] = v
-		case neotransaction.AssetGasID:
-			neobalance[`GAS`] = v
+		amount, err := item.Value.ToDecimals(info.Decimals)
+		if err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`balance of %s %v`, info.Key(), err)}
 		}
+		balances[info.Key()] = &Balance{Asset: info, Amount: amount}
 	}
+	return balances, nil
+}
 
-	return neobalance, nil
+// FetchNEP5Balance 获取账户持有的NEP-5资产余额，需要节点安装 RpcNep5Tracker 插件
+func FetchNEP5Balance(url string, addr string) (Balances, error) {
+	return NewClient(url, withSharedAssetCache()).FetchNEP5Balance(context.Background(), addr)
+}
+
+// FetchNEP5Balance 获取账户持有的NEP-5资产余额，资产的元数据通过 GetNEP5Info 获取并缓存
+func (c *Client) FetchNEP5Balance(ctx context.Context, addr string) (Balances, error) {
+	items, err := c.GetNEP5Balances(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	balances := make(Balances, len(items))
+	for _, item := range items {
+		info, err := c.GetNEP5Info(ctx, item.Contract)
+		if err != nil {
+			return nil, err
+		}
+		balances[info.Key()] = &Balance{Asset: info, Amount: item.Amount, LastUpdatedBlock: item.LastUpdatedBlock}
+	}
+	return balances, nil
 }
@@ -0,0 +1,183 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+)
+
+// testAssetID 测试用的第三种全局资产，精度为2
+const testAssetID = "3a4acd3647086e7c44398aac0349802e6a171129cc5a7d3d7f4bc7d5dd2d1e9c"
+
+// assetStateNode 按资产ID返回 NEO、GAS 和 testAssetID 的 getassetstate 应答
+func assetStateNode(t *testing.T, responses map[string]string) *syntheticNode {
+	node := newSyntheticNode(t, responses)
+	node.handle("getassetstate", func(params []json.RawMessage) string {
+		id := strings.TrimPrefix(strings.Trim(string(params[0]), `"`), "0x")
+		switch id {
+		case neotransaction.AssetNeoID:
+			return `{"version":0,"id":"0x` + id + `","type":"GoverningToken",` +
+				`"name":[{"lang":"zh-CN","name":"小蚁股"},{"lang":"en","name":"AntShare"}],"amount":"100000000","available":"100000000",` +
+				`"precision":0,"owner":"00","admin":"Abf2qMs1pzQb8kYk9RuxtUb9jtRKJVuBJt","issuer":"Abf2qMs1pzQb8kYk9RuxtUb9jtRKJVuBJt",` +
+				`"expiration":4000000,"frozen":false}`
+		case neotransaction.AssetGasID:
+			return `{"version":0,"id":"0x` + id + `","type":"UtilityToken",` +
+				`"name":[{"lang":"zh-CN","name":"小蚁币"},{"lang":"en","name":"AntCoin"}],"amount":"100000000","available":"30244071.37584551",` +
+				`"precision":8,"owner":"00","admin":"AWKECj9RD8rS8RPcpCgYVjk1DeYyHwxZm3","issuer":"AWKECj9RD8rS8RPcpCgYVjk1DeYyHwxZm3",` +
+				`"expiration":4000000,"frozen":false}`
+		case testAssetID:
+			return `{"version":0,"id":"0x` + id + `","type":"Token","name":"TestCoin","amount":"1000000","available":"1000000",` +
+				`"precision":2,"owner":"00","admin":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt","issuer":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt",` +
+				`"expiration":2000000,"frozen":false}`
+		}
+		return `error:{"code":-100,"message":"Unknown asset"}`
+	})
+	return node
+}
+
+// accountState 返回持有 balances 的 getaccountstate 应答，balances 为 资产ID:金额
+func accountState(balances ...string) string {
+	items := make([]string, 0, len(balances))
+	for _, balance := range balances {
+		parts := strings.SplitN(balance, ":", 2)
+		items = append(items, fmt.Sprintf(`{"asset":"0x%s","value":"%s"}`, parts[0], parts[1]))
+	}
+	return `{"version":0,"script_hash":"0x0b4f225d9b691328bae1a275c5c2e5bc3847caae","frozen":false,"votes":[],` +
+		`"balances":[` + strings.Join(items, ",") + `]}`
+}
+
+// balanceStrings 将余额格式化为 符号:金额 便于比较
+func balanceStrings(balances Balances) map[string]string {
+	result := make(map[string]string, len(balances))
+	for _, balance := range balances {
+		result[balance.Asset.Symbol] = balance.String()
+	}
+	return result
+}
+
+func TestFetchBalance(t *testing.T) {
+	node := assetStateNode(t, map[string]string{
+		"getaccountstate": accountState(neotransaction.AssetNeoID+":10", neotransaction.AssetGasID+":1.5", testAssetID+":12.34"),
+	})
+	want := map[string]string{"NEO": "10", "GAS": "1.5", "TestCoin": "12.34"}
+	for i := 0; i < 2; i++ {
+		balances, err := FetchBalance(node.URL, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(balanceStrings(balances)) != fmt.Sprint(want) {
+			t.Fatalf("balances %v, want %v", balanceStrings(balances), want)
+		}
+		gas := balances.Asset(mustHASH256(neotransaction.AssetGasID))
+		if gas == nil || gas.Amount.Int64() != 150000000 || gas.Asset.Decimals != 8 {
+			t.Fatalf("GAS balance %+v", gas)
+		}
+		// 第三种资产没有内置的符号，使用资产名称
+		other := balances.Asset(mustHASH256(testAssetID))
+		if other == nil || other.Amount.Int64() != 1234 || other.Asset.Type != "Token" || other.Asset.IsNEP5() {
+			t.Fatalf("%v balance %+v", testAssetID, other)
+		}
+	}
+	// 包级别的函数按节点地址共享资产元数据的缓存
+	if calls := node.count("getassetstate"); calls != 3 {
+		t.Fatalf("getassetstate called %v times", calls)
+	}
+}
+
+func TestFetchBalanceInvalidAmount(t *testing.T) {
+	// NEO 不可分割，余额有小数时节点数据有误
+	node := assetStateNode(t, map[string]string{
+		"getaccountstate": accountState(neotransaction.AssetNeoID + ":0.5"),
+	})
+	_, err := NewClient(node.URL).FetchBalance(context.Background(), "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+}
+
+func TestFetchBalances(t *testing.T) {
+	node := assetStateNode(t, map[string]string{})
+	node.handle("getaccountstate", func(params []json.RawMessage) string {
+		switch strings.Trim(string(params[0]), `"`) {
+		case "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt":
+			return accountState(neotransaction.AssetNeoID+":10", testAssetID+":0.01")
+		case "AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt":
+			return accountState(neotransaction.AssetGasID + ":0.00000001")
+		}
+		return `error:{"code":-32602,"message":"Invalid params"}`
+	})
+	addrs := []string{"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt", "invalid", "AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt"}
+	balances, err := FetchBalances(node.URL, addrs)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Errors[0] != nil || batchErr.Errors[2] != nil || !IsRPCError(batchErr.Errors[1], -32602) {
+		t.Fatalf("expected a BatchError for the invalid address, got %v", err)
+	}
+	if len(balances) != 3 || balances[1] != nil {
+		t.Fatalf("balances %v", balances)
+	}
+	if got := fmt.Sprint(balanceStrings(balances[0])); got != "map[NEO:10 TestCoin:0.01]" {
+		t.Fatalf("balances of %v %v", addrs[0], got)
+	}
+	if got := fmt.Sprint(balanceStrings(balances[2])); got != "map[GAS:0.00000001]" {
+		t.Fatalf("balances of %v %v", addrs[2], got)
+	}
+	if calls := node.count("getassetstate"); calls != 3 {
+		t.Fatalf("getassetstate called %v times", calls)
+	}
+	if _, err = FetchBalances(node.URL, addrs[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if calls := node.count("getassetstate"); calls != 3 {
+		t.Fatalf("asset info not cached, getassetstate called %v times", calls)
+	}
+}
+
+func TestFetchNEP5Balance(t *testing.T) {
+	rpx := mustHASH160("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9")
+	other := mustHASH160("1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3")
+	node := newSyntheticNode(t, map[string]string{
+		"getnep5balances": `{"balance":[{"asset_hash":"ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9","amount":"50000000000","last_updated_block":251604},` +
+			`{"asset_hash":"1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3","amount":"1000000000000000000000","last_updated_block":251600}],` +
+			`"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}`,
+	})
+	// 脚本中的合约哈希是小端序，按它区分试运行的是哪个合约
+	node.handle("invokescript", func(params []json.RawMessage) string {
+		script := string(params[0])
+		switch {
+		case strings.Contains(script, hex.EncodeToString(rpx)):
+			return `{"script":"","state":"HALT","gas_consumed":"0.424","stack":[` +
+				`{"type":"ByteArray","value":"5265642050756c736520546f6b656e"},{"type":"ByteArray","value":"525058"},` +
+				`{"type":"Integer","value":"8"},{"type":"ByteArray","value":"00e057eb481b"}]}`
+		case strings.Contains(script, hex.EncodeToString(other)):
+			return `{"script":"","state":"HALT","gas_consumed":"0.424","stack":[` +
+				`{"type":"ByteArray","value":"546f6b656e2042"},{"type":"ByteArray","value":"544b42"},` +
+				`{"type":"Integer","value":"18"},{"type":"ByteArray","value":"0000a0dec5adc9353600"}]}`
+		}
+		return `error:{"code":-100,"message":"Unknown contract"}`
+	})
+	for i := 0; i < 2; i++ {
+		balances, err := FetchNEP5Balance(node.URL, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(balanceStrings(balances)); got != "map[RPX:500 TKB:1000]" {
+			t.Fatalf("balances %v", got)
+		}
+		balance := balances.NEP5(other)
+		if balance == nil || balance.Asset.Decimals != 18 || balance.Asset.Name != "Token B" || balance.LastUpdatedBlock != 251600 ||
+			!balance.Asset.IsNEP5() || balance.Asset.Key() != "1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3" {
+			t.Fatalf("balance %+v", balance)
+		}
+	}
+	// 每个合约只试运行一次
+	if calls := node.count("invokescript"); calls != 2 {
+		t.Fatalf("invokescript called %v times", calls)
+	}
+}
@@ -1,45 +1,19 @@
 package neocliapi
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strings"
-	"time"
+	"context"
 )
 
 // FetchTX 获取交易信息
 func FetchTX(url string, txid string) (map[string]interface{}, error) {
-	reader := strings.NewReader(fmt.Sprintf(`{
-		"jsonrpc": "2.0",
-		"method": "getrawtransaction",
-		"params": [%s, 1],
-		"id": 1
-	}`, txid))
-	client := http.Client{Timeout: 30 * time.Second}
-	response, err := client.Post(url, "application/json", reader)
-
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
+	return newLegacyClient(url).FetchTX(context.Background(), txid)
+}
 
-	ret := make(map[string]interface{})
-	if err = json.Unmarshal(buff, &ret); err != nil {
+// FetchTX 获取交易信息
+func (c *Client) FetchTX(ctx context.Context, txid string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if err := c.callResult(ctx, &result, `getrawtransaction`, txid, 1); err != nil {
 		return nil, err
 	}
-
-	result, ok := ret[`result`].(map[string]interface{})
-	if !ok {
-		return nil, errors.New(`no result`)
-	}
-
 	return result, nil
 }
@@ -1,13 +1,10 @@
 package neocliapi
 
 import (
+	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strings"
-	"time"
 
 	"github.com/x-contract/neo-go-sdk/neoutils"
 
@@ -21,54 +18,32 @@ import (
 
 // FetchUTXO 从neo-cli扩展节点的api接口获得一个账户的utxo数据
 func FetchUTXO(url string, address *neotransaction.Address, assetFilter string) ([]*neotransaction.UTXO, error) {
-	client := &http.Client{}
-	client.Timeout = 60 * time.Second
-	response, err := client.Post(url, "application/json", strings.NewReader(`{
-		"jsonrpc": "2.0",
-		"method": "getunspents",
-		"params": ["`+address.Addr+`"],
-		"id": 1
-	}`))
-
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
+	return NewClient(url).FetchUTXO(context.Background(), address, assetFilter)
+}
 
-	ret := &struct {
-		JsonRPC string
-		ID      int
-		Result  *struct {
-			Address string
-			Balance []struct {
-				AssetHash string `json:"asset_hash"`
-				Asset     string
-				Symbol    string `json:"asset_symbol"`
-				Amount    float64
-				Unspent   []struct {
-					TXID  string
-					N     uint16
-					Value float64
-				}
+// FetchUTXO 从neo-cli扩展节点获得一个账户的utxo数据
+func (c *Client) FetchUTXO(ctx context.Context, address *neotransaction.Address, assetFilter string) ([]*neotransaction.UTXO, error) {
+	result := &struct {
+		Address string
+		Balance []struct {
+			AssetHash string `json:"asset_hash"`
+			Asset     string
+			Symbol    string `json:"asset_symbol"`
+			Amount    neoutils.Fixed8
+			Unspent   []struct {
+				TXID  string
+				N     uint16
+				Value neoutils.Fixed8
 			}
 		}
 	}{}
 
-	if err = json.Unmarshal(buff, &ret); err != nil {
-		return nil, err
-	}
-
-	if ret.Result == nil {
-		return nil, fmt.Errorf(`FetchUTXO for address[%v] failed %s`, address.Addr, buff)
+	if err := c.callResult(ctx, result, `getunspents`, address.Addr); err != nil {
+		return nil, fmt.Errorf(`FetchUTXO for address[%v] failed: %w`, address.Addr, err)
 	}
 
 	utxos := make([]*neotransaction.UTXO, 0)
-	for _, asset := range ret.Result.Balance {
+	for _, asset := range result.Balance {
 		for _, txout := range asset.Unspent {
 			utxo := &neotransaction.UTXO{}
 			utxo.TxHash, _ = hex.DecodeString(strings.TrimPrefix(txout.TXID, "0x"))
@@ -76,7 +51,7 @@ func FetchUTXO(url string, address *neotransaction.Address, assetFilter string)
 			utxo.Index = txout.N
 			utxo.AssetID, _ = hex.DecodeString(asset.AssetHash)
 			utxo.AssetID = neoutils.Reverse(utxo.AssetID)
-			utxo.Value = int64(txout.Value * float64(neotransaction.TxOutputValueBase))
+			utxo.Value = txout.Value
 			utxo.ScriptHash = address.ScripHash
 			utxos = append(utxos, utxo)
 		}
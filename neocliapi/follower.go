@@ -0,0 +1,406 @@
+package neocliapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// reorgDepth 记录最近发出的区块数
+func (f *BlockFollower) reorgDepth() int {
+	if f.MaxReorgDepth < 1 {
+		return DefaultMaxReorgDepth
+	}
+	return f.MaxReorgDepth
+}
+
+// retryable 判断同一个区块的第 failures 次失败之后是否还可以重试
+func (f *BlockFollower) retryable(err error, failures int) bool {
+	return f.MaxRetries <= 0 || failures <= f.MaxRetries || errors.As(err, new(*TransportError))
+}
+
+// 区块跟踪器的默认参数
+const (
+	DefaultPollInterval  = 3 * time.Second
+	DefaultMinBackoff    = time.Second
+	DefaultMaxBackoff    = time.Minute
+	DefaultMaxReorgDepth = 100
+)
+
+// ErrReorgTooDeep 区块回滚的深度超过了 MaxReorgDepth，找不到分叉点，跟踪器不发出回滚事件并停止
+// 检查点仍是最后处理的区块，需要人工确认分叉点后再从正确的高度重新开始
+var ErrReorgTooDeep = errors.New("chain reorganization deeper than MaxReorgDepth")
+
+// BlockSource 区块数据来源，Client 和 EndpointPool 都实现了此接口
+type BlockSource interface {
+	FetchBlockHeight(ctx context.Context) (uint64, error)
+	GetBlock(ctx context.Context, height uint64) (*Block, error)
+}
+
+// BlockEventType 区块事件类型
+type BlockEventType int
+
+// 区块事件类型
+const (
+	BlockAdded    BlockEventType = iota // 新区块
+	BlockRollback                       // 之前发出的区块已不在主链上，需要撤销
+)
+
+func (t BlockEventType) String() string {
+	switch t {
+	case BlockAdded:
+		return "BlockAdded"
+	case BlockRollback:
+		return "BlockRollback"
+	}
+	return fmt.Sprintf("BlockEventType(%d)", int(t))
+}
+
+// BlockEvent 区块跟踪器发出的事件
+// 发生链重组时，跟踪器按高度从高到低对每个孤立的区块发出 BlockRollback 事件，之后从分叉点重新发出 BlockAdded 事件
+type BlockEvent struct {
+	Type   BlockEventType
+	Height uint64
+	Block  *Block // BlockRollback 事件中为被孤立的区块
+}
+
+// BlockHandler 处理区块事件，返回错误时跟踪器会等待一段时间后重新发出同一个事件
+type BlockHandler func(ctx context.Context, event *BlockEvent) error
+
+// BlockFollower 区块跟踪器，从 StartHeight（或检查点的下一个区块）开始按高度顺序发出区块
+// 跟踪器记录最近发出的 MaxReorgDepth 个区块，通过比较新区块的 PrevHash 检测链重组
+// 同一进程中可以创建多个互相独立的跟踪器
+type BlockFollower struct {
+	Source        BlockSource
+	Checkpoint    CheckpointStore // 为nil时不保存进度
+	StartHeight   uint64          // 没有检查点时发出的第一个区块高度
+	Confirmations uint64          // 区块得到多少个确认后才发出，最新区块为1个确认，0和1都表示立即发出
+	MaxReorgDepth int             // 记录最近发出的区块数，即可以检测的最大回滚深度，小于1时为 DefaultMaxReorgDepth
+	Workers       int             // 并发获取区块的goroutine数，从较旧的高度追赶时可以调大，小于1时为1
+	PollInterval  time.Duration   // 追上最新区块后查询新区块的间隔
+	MinBackoff    time.Duration   // 出错后第一次重试的等待时间，之后每次加倍
+	MaxBackoff    time.Duration   // 出错后重试的最长等待时间
+	MaxRetries    int             // 同一个区块连续获取或处理失败的最大重试次数，超过后 Run 返回最后的错误；0表示一直重试，TransportError 不计入
+	OnError       func(error)     // Run 遇到错误时的回调，错误会在等待后自动重试
+}
+
+// NewBlockFollower 创建一个从 startHeight 开始跟踪区块的跟踪器，使用默认的轮询和重试间隔
+func NewBlockFollower(source BlockSource, startHeight uint64) *BlockFollower {
+	return &BlockFollower{
+		Source:        source,
+		StartHeight:   startHeight,
+		MaxReorgDepth: DefaultMaxReorgDepth,
+		PollInterval:  DefaultPollInterval,
+		MinBackoff:    DefaultMinBackoff,
+		MaxBackoff:    DefaultMaxBackoff,
+	}
+}
+
+// sleep 等待 d，ctx 被取消时提前返回false
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoff 出错后的重试等待时间
+type backoff struct {
+	min, max, next time.Duration
+}
+
+func (b *backoff) wait(ctx context.Context) bool {
+	if b.next < b.min {
+		b.next = b.min
+	}
+	d := b.next
+	if b.next *= 2; b.max > 0 && b.next > b.max {
+		b.next = b.max
+	}
+	return sleep(ctx, d)
+}
+
+func (b *backoff) reset() {
+	b.next = b.min
+}
+
+// Run 阻塞地跟踪区块，每个事件调用一次 handler，handler 成功返回后保存检查点
+// 获取区块或处理失败时回调 OnError 并在等待后重试，直到 ctx 被取消，返回 ctx.Err()；
+// 同一个区块的失败次数超过 MaxRetries 时停止，返回最后的错误；链重组深度超过 MaxReorgDepth 时停止，返回 ErrReorgTooDeep
+func (f *BlockFollower) Run(ctx context.Context, handler BlockHandler) error {
+	return f.run(ctx, handler, f.OnError)
+}
+
+func (f *BlockFollower) run(ctx context.Context, handler BlockHandler, onError func(error)) error {
+	// 并发获取区块时多个goroutine会同时报告错误
+	reportLock := sync.Mutex{}
+	report := func(err error) {
+		if onError != nil && ctx.Err() == nil {
+			reportLock.Lock()
+			defer reportLock.Unlock()
+			onError(err)
+		}
+	}
+	retry := &backoff{min: f.MinBackoff, max: f.MaxBackoff}
+
+	next := f.StartHeight
+	failures := 0 // 区块 next 连续失败的次数
+	// 最近发出的区块，按高度升序，最后一个的高度为 next-1
+	recent := make([]*Block, 0)
+	if f.Checkpoint != nil {
+		for {
+			height, ok, err := f.Checkpoint.Load()
+			if err == nil {
+				if ok {
+					next = height + 1
+				}
+				break
+			}
+			report(fmt.Errorf("BlockFollower load checkpoint: %w", err))
+			if !retry.wait(ctx) {
+				return ctx.Err()
+			}
+		}
+	}
+
+	for {
+		height, err := f.Source.FetchBlockHeight(ctx)
+		if err != nil {
+			report(fmt.Errorf("BlockFollower fetch block height: %w", err))
+			if !retry.wait(ctx) {
+				return ctx.Err()
+			}
+			continue
+		}
+		retry.reset()
+		// 只发出已经得到足够确认的区块
+		if f.Confirmations > 1 {
+			if height+1 < f.Confirmations {
+				if !sleep(ctx, f.PollInterval) {
+					return ctx.Err()
+				}
+				continue
+			}
+			height -= f.Confirmations - 1
+		}
+		if next > height {
+			if !sleep(ctx, f.PollInterval) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		// 并发预取区块，按顺序处理；出错或回滚后停止预取，从新的 next 重新开始
+		fetchCtx, cancelFetch := context.WithCancel(ctx)
+		blocks := f.fetchBlocks(fetchCtx, next, height, report)
+		err = nil
+		for next <= height {
+			fetched, ok := <-blocks
+			if !ok {
+				break
+			}
+			if fetched.err != nil {
+				// 获取区块的重试次数已经用完
+				cancelFetch()
+				return fmt.Errorf("BlockFollower fetch block[%v]: %w", next, fetched.err)
+			}
+			block := fetched.block
+			if len(recent) > 0 && !bytes.Equal(block.PrevHash, recent[len(recent)-1].Hash) {
+				// 新区块不是接在上一个发出的区块之后，发生了链重组
+				err = f.rollback(ctx, handler, &recent, &next, report)
+				break
+			}
+			if err = handler(ctx, &BlockEvent{Type: BlockAdded, Height: next, Block: block}); err != nil {
+				break
+			}
+			retry.reset()
+			failures = 0
+			f.saveCheckpoint(next, report)
+			if recent = append(recent, block); len(recent) > f.reorgDepth() {
+				recent = recent[len(recent)-f.reorgDepth():]
+			}
+			next++
+		}
+		cancelFetch()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			err = fmt.Errorf("BlockFollower block[%v]: %w", next, err)
+			if errors.Is(err, ErrReorgTooDeep) {
+				return err
+			}
+			if failures++; !f.retryable(err, failures) {
+				return err
+			}
+			report(err)
+			if !retry.wait(ctx) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// fetchedBlock 获取到的区块，重试次数用完时 err 为最后的错误
+type fetchedBlock struct {
+	block *Block
+	err   error
+}
+
+// fetchBlocks 使用 Workers 个goroutine并发获取 [from, to] 的区块，按高度顺序从返回的channel输出
+// 单个区块获取失败时等待后重试，超过 MaxRetries 后输出错误；最多预取 2*Workers 个区块，消费者处理不及时会阻塞获取
+// ctx 被取消后channel关闭
+func (f *BlockFollower) fetchBlocks(ctx context.Context, from uint64, to uint64, report func(error)) <-chan fetchedBlock {
+	workers := f.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	out := make(chan fetchedBlock)
+	// 每个高度一个结果slot，按高度顺序排队
+	slots := make(chan chan fetchedBlock, workers*2)
+
+	go func() {
+		defer close(slots)
+		sem := make(chan struct{}, workers)
+		for height := from; height <= to; height++ {
+			slot := make(chan fetchedBlock, 1)
+			select {
+			case slots <- slot:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(height uint64) {
+				defer func() { <-sem }()
+				retry := &backoff{min: f.MinBackoff, max: f.MaxBackoff}
+				for failures := 1; ; failures++ {
+					block, err := f.Source.GetBlock(ctx, height)
+					if err == nil {
+						slot <- fetchedBlock{block: block}
+						return
+					}
+					if !f.retryable(err, failures) {
+						slot <- fetchedBlock{err: err}
+						return
+					}
+					report(fmt.Errorf("BlockFollower fetch block[%v]: %w", height, err))
+					if !retry.wait(ctx) {
+						return
+					}
+				}
+			}(height)
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		for slot := range slots {
+			select {
+			case fetched := <-slot:
+				select {
+				case out <- fetched:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (f *BlockFollower) saveCheckpoint(height uint64, report func(error)) {
+	if f.Checkpoint != nil {
+		if err := f.Checkpoint.Save(height); err != nil {
+			report(fmt.Errorf("BlockFollower save checkpoint[%v]: %w", height, err))
+		}
+	}
+}
+
+// rollback 从最近发出的区块中找到仍在主链上的分叉点，对之后的区块从高到低发出 BlockRollback 事件
+// 最近发出的区块都不在主链上时返回 ErrReorgTooDeep
+// 每个事件处理成功后立即更新 recent、next 和检查点，出错时可以直接重试
+func (f *BlockFollower) rollback(ctx context.Context, handler BlockHandler, recent *[]*Block, next *uint64, report func(error)) error {
+	fork := -1
+	for i := len(*recent) - 1; i >= 0; i-- {
+		old := (*recent)[i]
+		block, err := f.Source.GetBlock(ctx, uint64(old.Index))
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(block.Hash, old.Hash) {
+			fork = i
+			break
+		}
+	}
+	if fork == len(*recent)-1 {
+		// 上一个区块仍在链上，节点返回的数据前后不一致，稍后重试
+		return fmt.Errorf("previous block hash mismatch")
+	}
+	if fork < 0 {
+		return ErrReorgTooDeep
+	}
+	for len(*recent) > fork+1 {
+		orphan := (*recent)[len(*recent)-1]
+		height := uint64(orphan.Index)
+		if err := handler(ctx, &BlockEvent{Type: BlockRollback, Height: height, Block: orphan}); err != nil {
+			return err
+		}
+		*recent = (*recent)[:len(*recent)-1]
+		*next = height
+		if height > 0 {
+			f.saveCheckpoint(height-1, report)
+		}
+	}
+	return nil
+}
+
+// Start 在新的goroutine中跟踪区块，返回事件和错误的channel，ctx 被取消或跟踪器停止后两个channel都会关闭
+// 事件被接收即视为处理完成并保存检查点；错误channel有缓冲，消费不及时的错误会被丢弃，
+// 但导致跟踪器停止的错误一定会发送
+func (f *BlockFollower) Start(ctx context.Context) (<-chan *BlockEvent, <-chan error) {
+	events := make(chan *BlockEvent)
+	errs := make(chan error, 16)
+	go func() {
+		defer close(events)
+		defer close(errs)
+		err := f.run(ctx, func(ctx context.Context, event *BlockEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+		sendStopError(ctx, errs, err)
+	}()
+	return events, errs
+}
+
+// sendStopError 将导致跟踪器停止的错误发送到 errs，ctx 被取消时不发送
+func sendStopError(ctx context.Context, errs chan<- error, err error) {
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	select {
+	case errs <- err:
+	case <-ctx.Done():
+	}
+}
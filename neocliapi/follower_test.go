@@ -0,0 +1,340 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// memoryChain 内存中的区块链，可以替换某个高度之后的区块模拟链重组
+type memoryChain struct {
+	mu     sync.Mutex
+	blocks []*Block
+	fail   map[uint64]error         // 获取这些高度的区块时返回的错误
+	calls  map[uint64]int           // 每个高度的 GetBlock 调用次数
+	delay  map[uint64]time.Duration // 获取这些高度的区块时的延迟
+	active int                      // 正在进行的 GetBlock 调用数
+	peak   int                      // 同时进行的 GetBlock 调用数的最大值
+}
+
+// testBlockHash 区块哈希，fork 不同的区块哈希不同
+func testBlockHash(height uint64, fork int) neoutils.HASH256 {
+	hash, _ := neoutils.ParseHASH256(fmt.Sprintf("%032x%032x", fork, height+1))
+	return hash
+}
+
+// extend 将链从高度 from 开始替换为 fork 的 count 个区块
+func (c *memoryChain) extend(from uint64, count int, fork int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks = c.blocks[:from]
+	for height := from; height < from+uint64(count); height++ {
+		block := &Block{}
+		block.Index = uint32(height)
+		block.Hash = testBlockHash(height, fork)
+		block.PrevHash = make(neoutils.HASH256, 32)
+		if height > 0 {
+			block.PrevHash = c.blocks[height-1].Hash
+		}
+		c.blocks = append(c.blocks, block)
+	}
+}
+
+// FetchBlockHeight 与 Client.FetchBlockHeight 一致，返回最新区块的高度，即区块数减1
+func (c *memoryChain) FetchBlockHeight(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.blocks) == 0 {
+		return 0, &DecodeError{Err: errors.New("block count is 0")}
+	}
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *memoryChain) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	c.mu.Lock()
+	if c.calls == nil {
+		c.calls = map[uint64]int{}
+	}
+	c.calls[height]++
+	c.active++
+	if c.active > c.peak {
+		c.peak = c.active
+	}
+	delay := c.delay[height]
+	c.mu.Unlock()
+	ok := sleep(ctx, delay)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active--
+	if !ok {
+		return nil, &TransportError{Endpoint: "memory", Err: ctx.Err()}
+	}
+	if err := c.fail[height]; err != nil {
+		return nil, err
+	}
+	if height >= uint64(len(c.blocks)) {
+		return nil, &RPCError{Code: -100, Message: "Unknown block"}
+	}
+	return c.blocks[height], nil
+}
+
+// followEvents 运行跟踪器，在收到第 pause 个事件后调用 change，返回收到的所有事件和 Run 的返回值
+// 收到 total 个事件后取消跟踪
+func followEvents(chain *memoryChain, depth int, pause int, change func(), total int) ([]string, error) {
+	follower := NewBlockFollower(chain, 0)
+	follower.MaxReorgDepth = depth
+	follower.PollInterval = time.Millisecond
+	follower.MinBackoff = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := []string{}
+	err := follower.Run(ctx, func(ctx context.Context, event *BlockEvent) error {
+		kind := "add"
+		if event.Type == BlockRollback {
+			kind = "rollback"
+		}
+		// 哈希按小端存储，第16个字节是 fork 的最低字节
+		events = append(events, fmt.Sprintf("%v %v %x", kind, event.Height, event.Block.Hash[16:17]))
+		if len(events) == pause {
+			change()
+		}
+		if len(events) == total {
+			cancel()
+		}
+		return nil
+	})
+	return events, err
+}
+
+func TestBlockFollowerRollback(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 5, 0)
+	// 发出区块0~4后，区块4被替换并增加区块5
+	events, err := followEvents(chain, 2, 5, func() { chain.extend(4, 2, 1) }, 8)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+	want := []string{"add 0 00", "add 1 00", "add 2 00", "add 3 00", "add 4 00", "rollback 4 00", "add 4 01", "add 5 01"}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Fatalf("events %v, want %v", events, want)
+	}
+}
+
+func TestBlockFollowerReorgTooDeep(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 5, 0)
+	// 区块2之后都被替换，分叉点超出了最近记录的2个区块
+	events, err := followEvents(chain, 2, 5, func() { chain.extend(2, 4, 1) }, 0)
+	if !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("expected ErrReorgTooDeep, got %v", err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("follower continued after a too deep reorg: %v", events)
+	}
+}
+
+func TestBlockFollowerStartStopError(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 3, 0)
+	follower := NewBlockFollower(chain, 0)
+	follower.MaxReorgDepth = 1
+	follower.PollInterval = time.Millisecond
+	events, errs := follower.Start(context.Background())
+	for i := 0; i < 3; i++ {
+		<-events
+	}
+	chain.extend(1, 3, 1)
+	var stopErr error
+	for err := range errs {
+		stopErr = err
+	}
+	if !errors.Is(stopErr, ErrReorgTooDeep) {
+		t.Fatalf("expected ErrReorgTooDeep, got %v", stopErr)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("events channel not closed")
+	}
+}
+
+func TestBlockFollowerMaxRetries(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 5, 0)
+	// 区块3存在但节点一直返回错误
+	broken := &RPCError{Code: -32603, Message: "Internal error"}
+	chain.fail = map[uint64]error{3: broken}
+	follower := NewBlockFollower(chain, 0)
+	follower.MaxRetries = 2
+	follower.PollInterval = time.Millisecond
+	follower.MinBackoff = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	heights := []uint64{}
+	err := follower.Run(ctx, func(ctx context.Context, event *BlockEvent) error {
+		heights = append(heights, event.Height)
+		return nil
+	})
+	if !errors.Is(err, broken) {
+		t.Fatalf("expected the block error, got %v", err)
+	}
+	if fmt.Sprint(heights) != "[0 1 2]" {
+		t.Fatalf("handled blocks %v", heights)
+	}
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	// 首次获取加上2次重试
+	if chain.calls[3] != 3 {
+		t.Fatalf("block 3 fetched %v times", chain.calls[3])
+	}
+	if chain.calls[4] > 1 {
+		t.Fatalf("block 4 fetched %v times", chain.calls[4])
+	}
+}
+
+func TestBlockFollowerMaxRetriesTransportError(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 2, 0)
+	// 网络错误不计入重试次数，一直重试直到 ctx 被取消
+	chain.fail = map[uint64]error{1: &TransportError{Endpoint: "memory", Err: errors.New("connection refused")}}
+	follower := NewBlockFollower(chain, 0)
+	follower.MaxRetries = 1
+	follower.PollInterval = time.Millisecond
+	follower.MinBackoff = time.Millisecond
+	follower.MaxBackoff = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := follower.Run(ctx, func(ctx context.Context, event *BlockEvent) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the follower to retry until canceled, got %v", err)
+	}
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	if chain.calls[1] <= 2 {
+		t.Fatalf("block 1 fetched %v times", chain.calls[1])
+	}
+}
+
+// randomDelays 为高度 [0, count) 的区块设置固定种子的随机延迟
+func randomDelays(count int, max time.Duration) map[uint64]time.Duration {
+	r := rand.New(rand.NewSource(1))
+	delays := make(map[uint64]time.Duration, count)
+	for height := 0; height < count; height++ {
+		delays[uint64(height)] = time.Duration(r.Int63n(int64(max)))
+	}
+	return delays
+}
+
+func TestFetchBlocksInOrder(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 40, 0)
+	// 每个区块的延迟不同，后面的区块可能先获取到
+	chain.delay = randomDelays(40, 5*time.Millisecond)
+	follower := NewBlockFollower(chain, 0)
+	follower.Workers = 4
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	next := uint64(5)
+	for fetched := range follower.fetchBlocks(ctx, 5, 39, func(err error) { t.Error(err) }) {
+		if fetched.err != nil {
+			t.Fatal(fetched.err)
+		}
+		if uint64(fetched.block.Index) != next {
+			t.Fatalf("got block %v, want %v", fetched.block.Index, next)
+		}
+		next++
+	}
+	if next != 40 {
+		t.Fatalf("channel closed before block %v", next)
+	}
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	if chain.peak > follower.Workers {
+		t.Fatalf("%v concurrent GetBlock calls with %v workers", chain.peak, follower.Workers)
+	}
+	if chain.calls[4] != 0 {
+		t.Fatal("block before from fetched")
+	}
+}
+
+func TestFetchBlocksBackpressure(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 100, 0)
+	follower := NewBlockFollower(chain, 0)
+	follower.Workers = 3
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	blocks := follower.fetchBlocks(ctx, 0, 99, func(err error) { t.Error(err) })
+	fetched := func() int {
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return len(chain.calls)
+	}
+	// 消费者没有读取时，除了等待发送的一个区块，最多预取 2*Workers 个区块
+	limit := 2*follower.Workers + 1
+	for received := 0; received < 3; received++ {
+		time.Sleep(20 * time.Millisecond)
+		if n := fetched(); n > received+limit {
+			t.Fatalf("%v blocks fetched after receiving %v", n, received)
+		}
+		<-blocks
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := fetched(); n != 3+limit {
+		t.Fatalf("%v blocks fetched after receiving 3, want %v", n, 3+limit)
+	}
+}
+
+func TestFetchBlocksCancel(t *testing.T) {
+	chain := &memoryChain{}
+	chain.extend(0, 50, 0)
+	chain.delay = randomDelays(50, 5*time.Millisecond)
+	// 区块10一直等到 ctx 被取消
+	chain.delay[10] = time.Hour
+	follower := NewBlockFollower(chain, 0)
+	follower.Workers = 4
+	ctx, cancel := context.WithCancel(context.Background())
+	blocks := follower.fetchBlocks(ctx, 0, 49, func(err error) {})
+	for height := 0; height < 10; height++ {
+		fetched := <-blocks
+		if fetched.err != nil || fetched.block.Index != uint32(height) {
+			t.Fatalf("block %v: %+v", height, fetched)
+		}
+	}
+	cancel()
+	select {
+	case fetched, ok := <-blocks:
+		if ok {
+			t.Fatalf("got block %+v after cancel", fetched)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	// 取消后不再获取新的区块，所有获取区块的goroutine都已退出
+	deadline := time.Now().Add(time.Second)
+	for {
+		chain.mu.Lock()
+		active, calls := chain.active, len(chain.calls)
+		chain.mu.Unlock()
+		if active == 0 {
+			if calls > 10+2*follower.Workers+1 {
+				t.Fatalf("%v blocks fetched", calls)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%v GetBlock calls still running after cancel", active)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	if chain.calls[10] != 1 {
+		t.Fatalf("block 10 fetched %v times", chain.calls[10])
+	}
+}
@@ -1,86 +1,137 @@
 package neocliapi
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
-	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
 )
 
-// GetApplicationLog 向一个neo-cli节点获取一次合约调用交易(InvocationTransaction)的执行结果
-func GetApplicationLog(url string, txid string) ([]Argument, int64, error) {
-
-	client := &http.Client{}
-	client.Timeout = 60 * time.Second
-	response, err := client.Post(url, "application/json", strings.NewReader(`{
-		"jsonrpc": "2.0",
-		"method": "getapplicationlog",
-		"params": ["`+txid+`"],
-		"id": 1
-	}`))
+// Notification 合约执行过程中通过 Runtime.Notify 发出的通知
+type Notification struct {
+	Contract neoutils.HASH160 // 发出通知的合约
+	State    StackItem        // 通知的内容，一般为 Array
+}
 
-	if err != nil {
-		return nil, 0, fmt.Errorf(`GetApplicationLog error: %v`, err)
-	}
-	defer response.Body.Close()
+// Execution 交易的一次执行，neo-cli 2.9 之后一个交易可能有多次执行（如 Verification 和 Application 触发器）
+type Execution struct {
+	Trigger       string           // 触发器，旧版本节点的日志中为空
+	Contract      neoutils.HASH160 // 执行的脚本的ScriptHash，旧版本节点的日志中为空
+	VMState       string
+	GasConsumed   neoutils.Fixed8
+	Stack         []StackItem
+	Notifications []*Notification
+}
 
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, 0, fmt.Errorf(`GetApplicationLog error: %v`, err)
-	}
+// Faulted 判断执行是否失败，失败的执行中发出的通知不生效
+func (e *Execution) Faulted() bool {
+	return strings.Contains(e.VMState, `FAULT`)
+}
 
-	ret := make(map[string]interface{})
-	err = json.Unmarshal(buff, &ret)
-	if err != nil {
-		return nil, 0, fmt.Errorf(`GetApplicationLog error: %v`, err)
-	}
+// ApplicationLog 交易的执行日志，需要节点安装 ApplicationLogs 插件
+type ApplicationLog struct {
+	TxID       neoutils.HASH256
+	Executions []*Execution
+}
 
-	result, ok := ret[`result`].(map[string]interface{})
-	if !ok {
-		return nil, 0, fmt.Errorf(`GetApplicationLog error: return value contains no result or result is not a object`)
+// Faulted 判断是否有执行失败
+func (log *ApplicationLog) Faulted() bool {
+	for _, e := range log.Executions {
+		if e.Faulted() {
+			return true
+		}
 	}
+	return false
+}
 
-	state, ok := result[`vmstate`].(string)
-	if !ok {
-		return nil, 0, fmt.Errorf(`GetApplicationLog error: no state return`)
+// Notifications 返回所有执行成功的执行中发出的通知
+func (log *ApplicationLog) Notifications() []*Notification {
+	notifications := make([]*Notification, 0)
+	for _, e := range log.Executions {
+		if !e.Faulted() {
+			notifications = append(notifications, e.Notifications...)
+		}
 	}
+	return notifications
+}
 
-	if state != `HALT, BREAK` {
-		return nil, 0, fmt.Errorf(`GetApplicationLog error: eval state "%s"`, string(buff))
-	}
+type notificationJSON struct {
+	Contract string    `json:"contract"`
+	State    StackItem `json:"state"`
+}
 
-	gas := int64(0)
+type executionJSON struct {
+	Trigger       string             `json:"trigger"`
+	Contract      string             `json:"contract"`
+	VMState       string             `json:"vmstate"`
+	GasConsumed   neoutils.Fixed8    `json:"gas_consumed"`
+	Stack         []StackItem        `json:"stack"`
+	Notifications []notificationJSON `json:"notifications"`
+}
 
-	gasconsumed, ok := result[`gas_consumed`].(string)
-	if !ok {
-		v, err := strconv.ParseFloat(gasconsumed, 10)
-		if err == nil {
-			gas = int64(v * float64(neotransaction.TxOutputValueBase))
+func (e *executionJSON) toExecution() (*Execution, error) {
+	execution := &Execution{
+		Trigger:       e.Trigger,
+		VMState:       e.VMState,
+		GasConsumed:   e.GasConsumed,
+		Stack:         e.Stack,
+		Notifications: make([]*Notification, 0, len(e.Notifications)),
+	}
+	var err error
+	if len(e.Contract) > 0 {
+		if execution.Contract, err = neoutils.ParseHASH160(e.Contract); err != nil {
+			return nil, err
 		}
 	}
-
-	stack, ok := result[`stack`].([]interface{})
-	if !ok {
-		return nil, gas, fmt.Errorf(`GetApplicationLog error: no stack in eval result`)
+	for _, n := range e.Notifications {
+		notification := &Notification{State: n.State}
+		if notification.Contract, err = neoutils.ParseHASH160(n.Contract); err != nil {
+			return nil, err
+		}
+		execution.Notifications = append(execution.Notifications, notification)
 	}
+	return execution, nil
+}
 
-	args := make([]Argument, 0, len(stack))
+// GetApplicationLog 向一个neo-cli节点获取一次合约调用交易(InvocationTransaction)的执行日志，包括返回值和发出的通知
+func GetApplicationLog(url string, txid string) (*ApplicationLog, error) {
+	return NewClient(url).GetApplicationLog(context.Background(), txid)
+}
 
-	for _, v := range stack {
-		arg, ok := v.(map[string]interface{})
-		if !ok {
-			return nil, gas, fmt.Errorf(`GetApplicationLog error: stack element[%v] parse failed`, v)
-		}
-		a := Argument{}
-		a.Type, ok = arg[`type`].(string)
-		a.Value, ok = arg[`value`].(string)
-		args = append(args, a)
+// GetApplicationLog 向节点获取一次合约调用交易(InvocationTransaction)的执行日志
+// 执行失败(FAULT)不会返回错误，需要通过 Faulted 判断
+func (c *Client) GetApplicationLog(ctx context.Context, txid string) (*ApplicationLog, error) {
+	result := &struct {
+		TxID string `json:"txid"`
+		executionJSON
+		Executions []executionJSON `json:"executions"`
+	}{}
+	if err := c.callResult(ctx, result, `getapplicationlog`, txid); err != nil {
+		return nil, fmt.Errorf(`GetApplicationLog error: %w`, err)
 	}
 
-	return args, gas, nil
+	log := &ApplicationLog{Executions: make([]*Execution, 0, len(result.Executions))}
+	var err error
+	if len(result.TxID) > 0 {
+		if log.TxID, err = neoutils.ParseHASH256(result.TxID); err != nil {
+			return nil, fmt.Errorf(`GetApplicationLog error: %w`, err)
+		}
+	}
+	// neo-cli 2.9 之前的日志没有 executions 字段，执行结果直接在日志中
+	executions := result.Executions
+	if len(executions) == 0 {
+		if len(result.VMState) == 0 {
+			return nil, fmt.Errorf(`GetApplicationLog error: no state return`)
+		}
+		executions = []executionJSON{result.executionJSON}
+	}
+	for i := range executions {
+		execution, err := executions[i].toExecution()
+		if err != nil {
+			return nil, fmt.Errorf(`GetApplicationLog error: %w`, err)
+		}
+		log.Executions = append(log.Executions, execution)
+	}
+	return log, nil
 }
@@ -0,0 +1,108 @@
+package neocliapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// transferNotification 返回 transfer 通知的json，from、to 为小端序脚本哈希的16进制，增发、销毁时为空
+func transferNotification(contract string, from string, to string, amount string) string {
+	return `{"contract":"0x` + contract + `","state":{"type":"Array","value":[` +
+		`{"type":"ByteArray","value":"7472616e73666572"},{"type":"ByteArray","value":"` + from + `"},` +
+		`{"type":"ByteArray","value":"` + to + `"},` + amount + `]}}`
+}
+
+func TestGetApplicationLog(t *testing.T) {
+	txid := "df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58"
+	from := "0b4f225d9b691328bae1a275c5c2e5bc3847caae"
+	to := "4fa7f7f52eaeb6c2a85d9ce178eca3cd77d27f78"
+	runRPCCases(t, []rpcCase{
+		{
+			// neo-cli 2.9 之后每个触发器一次执行
+			name:   "Executions",
+			method: "getapplicationlog",
+			result: `{"txid":"0x` + txid + `","executions":[` +
+				`{"trigger":"Application","contract":"0x3f6a0e4d0e4b6de28ab8d4e7d3e7c4b4f9c8b8a4","vmstate":"HALT, BREAK","gas_consumed":"2.855",` +
+				`"stack":[{"type":"Integer","value":"1"}],"notifications":[` +
+				transferNotification("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9", from, to, `{"type":"ByteArray","value":"00e1f505"}`) + `]}]}`,
+			params: `["` + txid + `"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetApplicationLog(ctx, txid)
+			},
+			want: &ApplicationLog{
+				TxID: mustHASH256(txid),
+				Executions: []*Execution{{
+					Trigger:     "Application",
+					Contract:    mustHASH160("3f6a0e4d0e4b6de28ab8d4e7d3e7c4b4f9c8b8a4"),
+					VMState:     "HALT, BREAK",
+					GasConsumed: 285500000,
+					Stack:       []StackItem{{Type: "Integer", Value: "1"}},
+					Notifications: []*Notification{{
+						Contract: rpxContract,
+						State: StackItem{Type: "Array", Items: []StackItem{
+							{Type: "ByteArray", Value: "7472616e73666572"},
+							{Type: "ByteArray", Value: from},
+							{Type: "ByteArray", Value: to},
+							{Type: "ByteArray", Value: "00e1f505"},
+						}},
+					}},
+				}},
+			},
+		},
+		{
+			// neo-cli 2.9 之前的日志只有一次执行，没有 trigger 和 contract
+			name:   "Legacy",
+			method: "getapplicationlog",
+			result: `{"txid":"0x` + txid + `","vmstate":"FAULT, BREAK","gas_consumed":"0.1","stack":[],"notifications":[]}`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetApplicationLog(ctx, txid)
+			},
+			want: &ApplicationLog{
+				TxID: mustHASH256(txid),
+				Executions: []*Execution{{
+					VMState:       "FAULT, BREAK",
+					GasConsumed:   10000000,
+					Stack:         []StackItem{},
+					Notifications: []*Notification{},
+				}},
+			},
+		},
+	})
+}
+
+func TestGetApplicationLogErrors(t *testing.T) {
+	for _, result := range []string{
+		// 没有执行结果
+		`{"txid":"0xdf7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58","executions":[]}`,
+		`{"txid":"0x00","executions":[{"vmstate":"HALT","gas_consumed":"0","stack":[],"notifications":[]}]}`,
+		`{"executions":[{"vmstate":"HALT","gas_consumed":"0","stack":[],"notifications":[{"contract":"0x01","state":{"type":"Array","value":[]}}]}]}`,
+		`{"executions":[{"vmstate":"HALT","gas_consumed":"0.000000001","stack":[],"notifications":[]}]}`,
+	} {
+		node := newSyntheticNode(t, map[string]string{"getapplicationlog": result})
+		if log, err := NewClient(node.URL).GetApplicationLog(context.Background(), "00"); err == nil {
+			t.Errorf("%v parsed: %+v", result, log)
+		} else if !strings.HasPrefix(err.Error(), "GetApplicationLog error: ") {
+			t.Errorf("error not wrapped: %v", err)
+		}
+	}
+}
+
+func TestApplicationLogFaulted(t *testing.T) {
+	notification := &Notification{Contract: rpxContract, State: StackItem{Type: "Array"}}
+	log := &ApplicationLog{Executions: []*Execution{
+		{VMState: "FAULT, BREAK", Notifications: []*Notification{notification}},
+		{VMState: "HALT, BREAK", Notifications: []*Notification{notification, notification}},
+	}}
+	if !log.Faulted() || log.Executions[1].Faulted() {
+		t.Fatal("Faulted mismatch")
+	}
+	// 失败的执行中发出的通知不生效
+	if n := len(log.Notifications()); n != 2 {
+		t.Fatalf("%v notifications", n)
+	}
+	log.Executions = log.Executions[1:]
+	if log.Faulted() {
+		t.Fatal("HALT log faulted")
+	}
+}
@@ -0,0 +1,251 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+///////////////////////////////////////////////////////////////////////////
+/// getclaimable and getunclaimed are provided by the RpcSystemAssetTracker
+/// plugin of neo-cli 2.10
+///////////////////////////////////////////////////////////////////////////
+
+// Claimable 一笔可以用来提取GAS的已花费NEO输出
+type Claimable struct {
+	TxHash      neoutils.HASH256
+	Index       uint16
+	Value       neoutils.Fixed8 // NEO 数量
+	StartHeight uint32
+	EndHeight   uint32
+	Generated   neoutils.Fixed8 // 持有期间产生的GAS，超出8位的小数四舍五入
+	SysFee      neoutils.Fixed8 // 持有期间分得的系统手续费，超出8位的小数四舍五入
+	Unclaimed   neoutils.Fixed8 // 可提取的GAS总数，超出8位的小数四舍五入
+
+	exactUnclaimed *big.Rat // 节点返回的精确值，用于多笔求和
+}
+
+// exactAmount 节点返回的精确的十进制数量，可以是字符串或数字
+type exactAmount struct {
+	*big.Rat
+}
+
+// UnmarshalJSON 解析十进制字符串或数字，如 "0.000000123"、1e-7
+func (amount *exactAmount) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	r, ok := new(big.Rat).SetString(number.String())
+	if !ok {
+		return fmt.Errorf(`invalid number %s`, data)
+	}
+	amount.Rat = r
+	return nil
+}
+
+// roundedAmount 节点返回的GAS数量，超出8位的小数四舍五入
+// RpcSystemAssetTracker 按精确值计算，返回的小数可能超过8位，neoutils.ParseFixed8 会拒绝这样的数量
+type roundedAmount struct {
+	neoutils.Fixed8
+}
+
+// UnmarshalJSON 解析十进制字符串或数字并四舍五入到8位小数
+func (amount *roundedAmount) UnmarshalJSON(data []byte) error {
+	if string(data) == `null` {
+		return nil
+	}
+	exact := exactAmount{}
+	if err := exact.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	v, err := neoutils.Fixed8FromRat(exact.Rat)
+	if err != nil {
+		return err
+	}
+	amount.Fixed8 = v
+	return nil
+}
+
+// exactUnclaimedValue 返回精确的可提取数量，没有时使用 Unclaimed
+func (claim *Claimable) exactUnclaimedValue() *big.Rat {
+	if claim.exactUnclaimed != nil {
+		return claim.exactUnclaimed
+	}
+	return new(big.Rat).SetFrac(claim.Unclaimed.BigInt(), neoutils.Fixed8One.BigInt())
+}
+
+// Input 返回用于 ClaimTransaction 的输入引用
+func (claim *Claimable) Input() neotransaction.TxInput {
+	return neotransaction.TxInput{PrevHash: claim.TxHash, PrevIndex: claim.Index}
+}
+
+// ClaimInputs 返回一组 Claimable 对应的输入引用以及可提取的GAS总数
+// 总数由节点返回的精确值求和后再舍入到8位小数，与节点计算整体可提取数量的方式一致
+func ClaimInputs(claims []*Claimable) ([]neotransaction.TxInput, neoutils.Fixed8, error) {
+	inputs := make([]neotransaction.TxInput, 0, len(claims))
+	total := new(big.Rat)
+	for _, claim := range claims {
+		inputs = append(inputs, claim.Input())
+		total.Add(total, claim.exactUnclaimedValue())
+	}
+	amount, err := neoutils.Fixed8FromRat(total)
+	if err != nil {
+		return nil, 0, fmt.Errorf(`ClaimInputs %v`, err)
+	}
+	return inputs, amount, nil
+}
+
+// Unclaimed 账户未提取的GAS，各数量分别四舍五入到8位小数，Available 与 Unavailable 之和可能与 Unclaimed 相差 0.00000001
+type Unclaimed struct {
+	Available   neoutils.Fixed8 `json:"available"`   // 已花费的NEO产生的GAS，可以立即提取
+	Unavailable neoutils.Fixed8 `json:"unavailable"` // 未花费的NEO产生的GAS，需要先花费NEO（例如转给自己）才能提取
+	Unclaimed   neoutils.Fixed8 `json:"unclaimed"`   // 两者之和
+}
+
+// GetClaimable 获取账户可以提取GAS的已花费NEO输出
+func GetClaimable(url string, address string) ([]*Claimable, error) {
+	return NewClient(url).GetClaimable(context.Background(), address)
+}
+
+// GetClaimable 获取账户可以提取GAS的已花费NEO输出
+func (c *Client) GetClaimable(ctx context.Context, address string) ([]*Claimable, error) {
+	result := &struct {
+		Claimable []struct {
+			TXID        string
+			N           uint16
+			Value       neoutils.Fixed8
+			StartHeight uint32 `json:"start_height"`
+			EndHeight   uint32 `json:"end_height"`
+			Generated   roundedAmount
+			SysFee      roundedAmount `json:"sys_fee"`
+			Unclaimed   exactAmount
+		}
+		Unclaimed *exactAmount // 所有 claimable 的 unclaimed 之和
+	}{}
+
+	err := c.callResult(ctx, result, `getclaimable`, address)
+	if err != nil {
+		return nil, fmt.Errorf(`GetClaimable for address[%v] failed: %w`, address, err)
+	}
+
+	claims := make([]*Claimable, 0, len(result.Claimable))
+	total := new(big.Rat)
+	for _, item := range result.Claimable {
+		if item.Unclaimed.Rat == nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetClaimable unclaimed of txid[%v] missing`, item.TXID)}
+		}
+		claim := &Claimable{
+			Index:          item.N,
+			Value:          item.Value,
+			StartHeight:    item.StartHeight,
+			EndHeight:      item.EndHeight,
+			Generated:      item.Generated.Fixed8,
+			SysFee:         item.SysFee.Fixed8,
+			exactUnclaimed: item.Unclaimed.Rat,
+		}
+		if claim.Unclaimed, err = neoutils.Fixed8FromRat(item.Unclaimed.Rat); err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetClaimable unclaimed of txid[%v] %v`, item.TXID, err)}
+		}
+		if claim.TxHash, err = neoutils.ParseHASH256(item.TXID); err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetClaimable txid[%v] invalid: %v`, item.TXID, err)}
+		}
+		total.Add(total, item.Unclaimed.Rat)
+		claims = append(claims, claim)
+	}
+	// 核对节点给出的总数
+	if result.Unclaimed != nil && result.Unclaimed.Rat != nil && result.Unclaimed.Cmp(total) != 0 {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetClaimable unclaimed total %v differs from the sum %v of claimables`,
+			result.Unclaimed.FloatString(2*neoutils.Fixed8Decimals), total.FloatString(2*neoutils.Fixed8Decimals))}
+	}
+
+	return claims, nil
+}
+
+// GetUnclaimed 获取账户未提取的GAS数量
+func GetUnclaimed(url string, address string) (*Unclaimed, error) {
+	return NewClient(url).GetUnclaimed(context.Background(), address)
+}
+
+// GetUnclaimed 获取账户未提取的GAS数量
+func (c *Client) GetUnclaimed(ctx context.Context, address string) (*Unclaimed, error) {
+	result := &struct {
+		Available   roundedAmount `json:"available"`
+		Unavailable roundedAmount `json:"unavailable"`
+		Unclaimed   roundedAmount `json:"unclaimed"`
+	}{}
+	if err := c.callResult(ctx, result, `getunclaimed`, address); err != nil {
+		return nil, fmt.Errorf(`GetUnclaimed for address[%v] failed: %w`, address, err)
+	}
+	return &Unclaimed{
+		Available:   result.Available.Fixed8,
+		Unavailable: result.Unavailable.Fixed8,
+		Unclaimed:   result.Unclaimed.Fixed8,
+	}, nil
+}
+
+// GetUnclaimedGas 获取节点打开的钱包中可以提取的GAS数量
+func GetUnclaimedGas(url string) (neoutils.Fixed8, error) {
+	return NewClient(url).GetUnclaimedGas(context.Background())
+}
+
+// GetUnclaimedGas 获取节点打开的钱包中可以提取的GAS数量
+// 需要节点开启钱包，查询任意账户请使用 GetUnclaimed
+func (c *Client) GetUnclaimedGas(ctx context.Context) (neoutils.Fixed8, error) {
+	gas := neoutils.Fixed8(0)
+	if err := c.callResult(ctx, &gas, `getunclaimedgas`); err != nil {
+		return 0, fmt.Errorf(`GetUnclaimedGas failed: %w`, err)
+	}
+	return gas, nil
+}
+
+// MaxClaimsPerTransaction 一个提取GAS交易中最多包含的 Claims 数量，与neo-cli一致
+const MaxClaimsPerTransaction = 50
+
+// ClaimGas 将 key 对应基本账户所有可立即提取的GAS提取到此账户，返回发送的交易ID
+// Claims 较多时会拆分为多个交易；没有可提取的GAS时返回空数组
+func ClaimGas(url string, key *neotransaction.KeyPair) ([]string, error) {
+	return NewClient(url).ClaimGas(context.Background(), key)
+}
+
+// ClaimGas 将 key 对应基本账户所有可立即提取的GAS提取到此账户，参见 ClaimGas 函数
+func (c *Client) ClaimGas(ctx context.Context, key *neotransaction.KeyPair) ([]string, error) {
+	addr := key.CreateBasicAddress()
+	claims, err := c.GetClaimable(ctx, addr.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	txids := make([]string, 0)
+	for start := 0; start < len(claims); start += MaxClaimsPerTransaction {
+		end := start + MaxClaimsPerTransaction
+		if end > len(claims) {
+			end = len(claims)
+		}
+		inputs, amount, err := ClaimInputs(claims[start:end])
+		if err != nil {
+			return txids, err
+		}
+		if amount <= 0 {
+			continue
+		}
+		tx, err := neotransaction.CreateClaimTransaction(inputs, addr, amount)
+		if err != nil {
+			return txids, err
+		}
+		tx.AppendBasicSignWitness(key)
+		ok, err := c.SendRawTransaction(ctx, tx.RawTransactionString())
+		if err != nil {
+			return txids, fmt.Errorf(`ClaimGas send transaction[%s] failed: %w`, tx.TXID(), err)
+		}
+		if !ok {
+			return txids, fmt.Errorf(`ClaimGas send transaction[%s] rejected`, tx.TXID())
+		}
+		txids = append(txids, tx.TXID())
+	}
+	return txids, nil
+}
@@ -0,0 +1,108 @@
+package neocliapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// getclaimable 的合成应答，generated、sys_fee 和 unclaimed 带有超过8位的小数
+const claimableResult = `{"claimable":[` +
+	`{"txid":"0x52ba70ef18e879785572c917795cd81422c3820b8cf44c24846a30ee7376fd77","n":1,"value":800000,"start_height":476496,"end_height":488154,"generated":746.912,"sys_fee":3.92,"unclaimed":750.832},` +
+	`{"txid":"0x52ba70ef18e879785572c917795cd81422c3820b8cf44c24846a30ee7376fd77","n":2,"value":1,"start_height":1,"end_height":2,"generated":0.1234567812,"sys_fee":0.0000000038,"unclaimed":0.123456785},` +
+	`{"txid":"0xf6b2a2a1c4f8b0b3b21ba4c1f0e6b5a0a8e3a3a5d9c0b8a4e1b6e2d3c1a0b9c8","n":0,"value":1,"start_height":3,"end_height":4,"generated":0.000000005,"sys_fee":0,"unclaimed":0.000000005}` +
+	`],"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt","unclaimed":750.95545679}`
+
+func TestGetClaimable(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{"getclaimable": claimableResult})
+	claims, err := GetClaimable(node.URL, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims) != 3 {
+		t.Fatalf("claims %v", len(claims))
+	}
+	txid, _ := neoutils.ParseHASH256("52ba70ef18e879785572c917795cd81422c3820b8cf44c24846a30ee7376fd77")
+	if !bytes.Equal(claims[0].TxHash, txid) || claims[0].Index != 1 || claims[0].Value != 800000*neoutils.Fixed8One ||
+		claims[0].Generated != 74691200000 || claims[0].SysFee != 392000000 || claims[0].Unclaimed != 75083200000 {
+		t.Fatalf("claim %+v", claims[0])
+	}
+	// 单笔的数量四舍五入到8位小数
+	if claims[1].Unclaimed != 12345679 || claims[2].Unclaimed != 1 {
+		t.Fatalf("rounded unclaimed %v %v", claims[1].Unclaimed, claims[2].Unclaimed)
+	}
+	if claims[1].Generated != 12345678 || claims[1].SysFee != 0 || claims[2].Generated != 1 || claims[2].SysFee != 0 {
+		t.Fatalf("rounded generated %v %v sys_fee %v %v", claims[1].Generated, claims[2].Generated, claims[1].SysFee, claims[2].SysFee)
+	}
+
+	// 总数由精确值求和后再舍入，与节点的总数一致，而不是各笔舍入后的和 750.95545680
+	inputs, amount, err := ClaimInputs(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 3 || amount != 75095545679 {
+		t.Fatalf("inputs %v amount %v", len(inputs), amount)
+	}
+
+	// 手工构造的 Claimable 使用 Unclaimed
+	_, amount, err = ClaimInputs([]*Claimable{{Unclaimed: 5}, {Unclaimed: 7}})
+	if err != nil || amount != 12 {
+		t.Fatalf("amount %v err %v", amount, err)
+	}
+}
+
+func TestGetClaimableTotalMismatch(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{
+		"getclaimable": `{"claimable":[{"txid":"0x52ba70ef18e879785572c917795cd81422c3820b8cf44c24846a30ee7376fd77","n":1,"value":1,"start_height":1,"end_height":2,"generated":1,"sys_fee":0,"unclaimed":1}],"unclaimed":2}`,
+	})
+	_, err := GetClaimable(node.URL, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+}
+
+func TestGetUnclaimed(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{
+		"getunclaimed":    `{"available":750.032,"unavailable":0.29,"unclaimed":750.322}`,
+		"getunclaimedgas": `"12.5"`,
+	})
+	unclaimed, err := GetUnclaimed(node.URL, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unclaimed.Available != 75003200000 || unclaimed.Unavailable != 29000000 || unclaimed.Unclaimed != 75032200000 {
+		t.Fatalf("unclaimed %+v", unclaimed)
+	}
+
+	// 超过8位的小数四舍五入，ParseFixed8 会拒绝这样的数量
+	node.set("getunclaimed", `{"available":"0.123456785","unavailable":0.0000000049,"unclaimed":1.2345678e-1}`)
+	if unclaimed, err = GetUnclaimed(node.URL, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"); err != nil {
+		t.Fatal(err)
+	}
+	if unclaimed.Available != 12345679 || unclaimed.Unavailable != 0 || unclaimed.Unclaimed != 12345678 {
+		t.Fatalf("rounded unclaimed %+v", unclaimed)
+	}
+	gas, err := GetUnclaimedGas(node.URL)
+	if err != nil || gas != 1250000000 {
+		t.Fatalf("unclaimed gas %v err %v", gas, err)
+	}
+}
+
+func TestClaimGas(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{
+		"getclaimable":       claimableResult,
+		"sendrawtransaction": `true`,
+	})
+	txids, err := NewClient(node.URL).ClaimGas(context.Background(), neotransaction.GenerateKeyPair())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txids) != 1 || node.count("sendrawtransaction") != 1 {
+		t.Fatalf("txids %v", txids)
+	}
+}
@@ -1,35 +1,52 @@
 package neocliapi
 
 import (
+	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/x-contract/neo-go-sdk/neotransaction"
 	"github.com/x-contract/neo-go-sdk/neoutils"
 )
 
-// Argument 调用智能合约的参数和智能合约返回值的参数类型
-type Argument struct {
-	Type  string
-	Value string
+// invokeResultJSON invoke、invokescript 的返回结果
+type invokeResultJSON struct {
+	State       string          `json:"state"`
+	GasConsumed neoutils.Fixed8 `json:"gas_consumed"`
+	Stack       []StackItem     `json:"stack"`
 }
 
-func serializeParamString(params []interface{}) (string, error) {
-	paramString := ``
+// parse 检查执行状态并转换消耗的GAS，FAULT 时仍返回栈中的元素
+func (r *invokeResultJSON) parse() ([]Argument, neoutils.Fixed8, error) {
+	if len(r.State) == 0 {
+		return nil, 0, fmt.Errorf(`InvokeScript error: no state return`)
+	}
+	gas := r.GasConsumed
+	if r.Stack == nil {
+		return nil, gas, fmt.Errorf(`InvokeScript error: no stack in eval result`)
+	}
+	if strings.Contains(r.State, `FAULT`) {
+		return r.Stack, gas, fmt.Errorf(`InvokeScript error: eval state "%s"`, r.State)
+	}
+	return r.Stack, gas, nil
+}
+
+// contractParam invoke 接口的合约参数
+type contractParam struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// contractParams 将调用参数转换为合约参数，nil 参数被忽略，非字节数组的切片转换为 Array
+func contractParams(params []interface{}) ([]contractParam, error) {
+	ret := make([]contractParam, 0, len(params))
 	for _, param := range params {
 		if param == nil {
 			continue
 		}
-		if len(paramString) > 0 {
-			paramString += `,`
-		}
 
 		v := reflect.ValueOf(param)
 		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
@@ -37,183 +54,90 @@ func serializeParamString(params []interface{}) (string, error) {
 			for i := 0; i < v.Len(); i++ {
 				sub[i] = v.Index(i).Interface()
 			}
-			substring, err := serializeParamString(sub)
+			items, err := contractParams(sub)
 			if err != nil {
-				return ``, err
+				return nil, err
 			}
-			paramString += `{ "type": "Array", "value": [` + substring + `]}`
+			ret = append(ret, contractParam{Type: `Array`, Value: items})
 			continue
 		}
 
 		switch v := param.(type) {
 		case string:
-			paramString += `{ "type": "String", "value": "` + v + `"}`
+			ret = append(ret, contractParam{Type: `String`, Value: v})
 		case bool:
-			paramString += `{ "type": "Boolean", "value": "` + strconv.FormatBool(v) + `"}`
-		case int32, int64, uint32, uint64:
-			paramString += `{ "type": "Integer", "value": "` + fmt.Sprint(v) + `"}`
+			ret = append(ret, contractParam{Type: `Boolean`, Value: strconv.FormatBool(v)})
+		case int, uint, int32, int64, uint32, uint64, *big.Int:
+			ret = append(ret, contractParam{Type: `Integer`, Value: fmt.Sprint(v)})
 		case []byte:
-			paramString += `{ "type": "ByteArray", "value": "` + hex.EncodeToString(v) + `"}`
+			ret = append(ret, contractParam{Type: `ByteArray`, Value: hex.EncodeToString(v)})
 		case neoutils.HASH256:
-			paramString += `{ "type": "Hash256", "value": "` + hex.EncodeToString(v) + `"}`
+			// 节点用 UInt256.Parse、UInt160.Parse 解析哈希参数，需要大端序的字符串；
+			// 原有实现直接编码小端序的字节，节点得到的是反序的哈希
+			ret = append(ret, contractParam{Type: `Hash256`, Value: v.ToHexString()})
 		case neoutils.HASH160:
-			paramString += `{ "type": "Hash160", "value": "` + hex.EncodeToString(v) + `"}`
+			ret = append(ret, contractParam{Type: `Hash160`, Value: v.ToHexString()})
 		default:
-			return ``, fmt.Errorf(`InvokeScript error: params contains invalid type[%T]`, v)
+			return nil, fmt.Errorf(`InvokeScript error: params contains invalid type[%T]`, v)
 		}
 	}
-	return paramString, nil
+	return ret, nil
 }
 
 // Invoke 向一个neo-cli节点调用一个已发布的智能合约
 // 注意：这种调用方法只能调用一个查询类接口，不修改智能合约内存储数据，结果也不会上链，只是在本地节点上模拟运行
 // 如果需要上链的调用，需要拼一个 InvocationTransaction 然后调用 InvokeScript 接口广播此交易
-func Invoke(url string, scriptHashString string, params []interface{}) ([]Argument, int64, error) {
+func Invoke(url string, scriptHashString string, params []interface{}) ([]Argument, neoutils.Fixed8, error) {
+	return NewClient(url).Invoke(context.Background(), scriptHashString, params)
+}
 
-	paramString, err := serializeParamString(params)
-	//log.Println(paramString)
+// Invoke 向节点调用一个已发布的智能合约，参见 Invoke 函数
+func (c *Client) Invoke(ctx context.Context, scriptHashString string, params []interface{}) ([]Argument, neoutils.Fixed8, error) {
+	args, err := contractParams(params)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	client := &http.Client{}
-	client.Timeout = 60 * time.Second
-	response, err := client.Post(url, "application/json", strings.NewReader(`{
-		"jsonrpc": "2.0",
-		"method": "invoke",
-		"params": ["`+scriptHashString+`", [`+paramString+`]],
-		"id": 1
-	}`))
-
-	if err != nil {
-		return nil, 0, fmt.Errorf(`InvokeScript error: %v`, err)
-	}
-	defer response.Body.Close()
-
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, 0, fmt.Errorf(`InvokeScript error: %v`, err)
+	result := &invokeResultJSON{}
+	if err := c.callResult(ctx, result, `invoke`, scriptHashString, args); err != nil {
+		return nil, 0, fmt.Errorf(`InvokeScript error: %w`, err)
 	}
-
-	ret := make(map[string]interface{})
-	err = json.Unmarshal(buff, &ret)
+	stack, gas, err := result.parse()
 	if err != nil {
-		return nil, 0, fmt.Errorf(`InvokeScript error: %v`, err)
-	}
-
-	result, ok := ret[`result`].(map[string]interface{})
-	if !ok {
-		return nil, 0, fmt.Errorf(`InvokeScript error: return value contains no result or result is not a object`)
-	}
-
-	state, ok := result[`state`].(string)
-	if !ok {
-		return nil, 0, fmt.Errorf(`InvokeScript error: no state return`)
-	}
-
-	if state != `HALT, BREAK` {
-		return nil, 0, fmt.Errorf(`InvokeScript error: eval state "%s"`, string(buff))
-	}
-
-	gas := int64(0)
-
-	gasconsumed, ok := result[`gas_consumed`].(string)
-	if !ok {
-		v, err := strconv.ParseFloat(gasconsumed, 10)
-		if err == nil {
-			gas = int64(v * float64(neotransaction.TxOutputValueBase))
-		}
-	}
-
-	stack, ok := result[`stack`].([]interface{})
-	if !ok {
-		return nil, gas, fmt.Errorf(`InvokeScript error: no stack in eval result`)
-	}
-
-	args := make([]Argument, 0, len(stack))
-
-	for _, v := range stack {
-		arg, ok := v.(map[string]interface{})
-		if !ok {
-			return nil, gas, fmt.Errorf(`InvokeScript error: stack element[%v] parse failed`, v)
-		}
-		a := Argument{}
-		a.Type, ok = arg[`type`].(string)
-		a.Value, ok = arg[`value`].(string)
-		args = append(args, a)
+		return nil, gas, err
 	}
-
-	return args, gas, nil
+	return stack, gas, nil
 }
 
-// InvokeScript ...
-func InvokeScript(url string, script []byte) ([]Argument, int64, error) {
-	client := &http.Client{}
-	client.Timeout = 60 * time.Second
-	response, err := client.Post(url, "application/json", strings.NewReader(`{
-		"jsonrpc": "2.0",
-		"method": "invokescript",
-		"params": ["`+hex.EncodeToString(script)+`"],
-		"id": 1
-	}`))
-
-	if err != nil {
-		return nil, 0, fmt.Errorf(`InvokeScript error: %v`, err)
-	}
-	defer response.Body.Close()
-
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, 0, fmt.Errorf(`InvokeScript error: %v`, err)
-	}
+// InvokeFunction 向一个neo-cli节点调用一个已发布的智能合约的 operation 方法，结果不会上链
+func InvokeFunction(url string, scriptHashString string, operation string, params []interface{}) ([]Argument, neoutils.Fixed8, error) {
+	return NewClient(url).InvokeFunction(context.Background(), scriptHashString, operation, params)
+}
 
-	ret := make(map[string]interface{})
-	err = json.Unmarshal(buff, &ret)
+// InvokeFunction 向节点调用一个已发布的智能合约的 operation 方法，params 与 Invoke 相同
+func (c *Client) InvokeFunction(ctx context.Context, scriptHashString string, operation string, params []interface{}) ([]Argument, neoutils.Fixed8, error) {
+	args, err := contractParams(params)
 	if err != nil {
-		return nil, 0, fmt.Errorf(`InvokeScript error: %v`, err)
-	}
-
-	result, ok := ret[`result`].(map[string]interface{})
-	if !ok {
-		return nil, 0, fmt.Errorf(`InvokeScript error: return value contains no result or result is not a object`)
-	}
-
-	state, ok := result[`state`].(string)
-	if !ok {
-		return nil, 0, fmt.Errorf(`InvokeScript error: no state return`)
+		return nil, 0, err
 	}
 
-	gas := int64(0)
-
-	gasconsumed, ok := result[`gas_consumed`].(string)
-	if !ok {
-		v, err := strconv.ParseFloat(gasconsumed, 10)
-		if err == nil {
-			gas = int64(v * float64(neotransaction.TxOutputValueBase))
-		}
+	result := &invokeResultJSON{}
+	if err := c.callResult(ctx, result, `invokefunction`, scriptHashString, operation, args); err != nil {
+		return nil, 0, fmt.Errorf(`InvokeFunction error: %w`, err)
 	}
+	return result.parse()
+}
 
-	stack, ok := result[`stack`].([]interface{})
-	if !ok {
-		return nil, gas, fmt.Errorf(`InvokeScript error: no stack in eval result`)
-	}
-
-	args := make([]Argument, 0, len(stack))
-
-	for _, v := range stack {
-		arg, ok := v.(map[string]interface{})
-		if !ok {
-			return nil, gas, fmt.Errorf(`InvokeScript error: stack element[%v] parse failed`, v)
-		}
-		a := Argument{}
-		a.Type, ok = arg[`type`].(string)
-		a.Value, ok = arg[`value`].(string)
-		args = append(args, a)
-	}
+// InvokeScript 向一个neo-cli节点试运行一段脚本
+func InvokeScript(url string, script []byte) ([]Argument, neoutils.Fixed8, error) {
+	return NewClient(url).InvokeScript(context.Background(), script)
+}
 
-	if state != `HALT, BREAK` {
-		return args, gas, fmt.Errorf(`InvokeScript error: eval state "%s"`, state)
+// InvokeScript 向节点试运行一段脚本，结果不会上链
+func (c *Client) InvokeScript(ctx context.Context, script []byte) ([]Argument, neoutils.Fixed8, error) {
+	result := &invokeResultJSON{}
+	if err := c.callResult(ctx, result, `invokescript`, hex.EncodeToString(script)); err != nil {
+		return nil, 0, fmt.Errorf(`InvokeScript error: %w`, err)
 	}
-
-	return args, gas, nil
+	return result.parse()
 }
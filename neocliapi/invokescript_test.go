@@ -0,0 +1,73 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+func TestInvokeFunction(t *testing.T) {
+	owner, _ := neotransaction.ParseAddress("AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	runRPCCases(t, []rpcCase{
+		{
+			name:   "Halt",
+			method: "invokefunction",
+			result: `{"script":"140b4f225d9b691328bae1a275c5c2e5bc3847caae51c10962616c616e63654f6667f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec",` +
+				`"state":"HALT","gas_consumed":"0.338","stack":[{"type":"ByteArray","value":"00e1f505"}]}`,
+			params: `["ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9","balanceOf",[{"type":"Hash160","value":"aeca4738bce5c2c575a2e1ba2813699b5d224f0b"}]]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				stack, gas, err := c.InvokeFunction(ctx, "ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9", "balanceOf", []interface{}{owner.ScripHash})
+				return []interface{}{stack, gas}, err
+			},
+			want: []interface{}{[]Argument{{Type: "ByteArray", Value: "00e1f505"}}, neoutils.Fixed8(33800000)},
+		},
+		{
+			name:   "NoParams",
+			method: "invokefunction",
+			result: `{"script":"00c1046e616d6567f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec","state":"HALT","gas_consumed":"0.126",` +
+				`"stack":[{"type":"ByteArray","value":"5265642050756c736520546f6b656e"}]}`,
+			params: `["ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9","name",[]]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				stack, gas, err := c.InvokeFunction(ctx, "ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9", "name", nil)
+				return []interface{}{stack, gas}, err
+			},
+			want: []interface{}{[]Argument{{Type: "ByteArray", Value: "5265642050756c736520546f6b656e"}}, neoutils.Fixed8(12600000)},
+		},
+	})
+}
+
+func TestInvokeFunctionHashByteOrder(t *testing.T) {
+	// neo-cli 文档中 invokefunction 的示例请求，Hash160 参数是大端序的字符串，节点用 UInt160.Parse 解析；
+	// 应答的 script 按 neo-cli 的规则构造：参数按小端序（UInt160.ToArray）压栈，APPCALL 小端序的合约哈希
+	contract := "af7c7328eee5a275a3bcaee2bf0cf662b5e739be"
+	account := mustHASH160("91b83e96f2a7c4fdf0c1688441ec61986c7cae26")
+	script := "1426ae7c6c9861ec418468c1f0fdc4a7f2963eb89151c10962616c616e63654f6667be39e7b562f60cbfe2aebca375a2e5ee28737caf"
+	node := newSyntheticNode(t, map[string]string{
+		"invokefunction": `{"script":"` + script + `","state":"HALT","gas_consumed":"0.338","stack":[{"type":"ByteArray","value":"00e1f505"}]}`,
+	})
+	if _, _, err := NewClient(node.URL).InvokeFunction(context.Background(), contract, "balanceOf", []interface{}{account}); err != nil {
+		t.Fatal(err)
+	}
+	want := `["af7c7328eee5a275a3bcaee2bf0cf662b5e739be","balanceOf",[{"type":"Hash160","value":"91b83e96f2a7c4fdf0c1688441ec61986c7cae26"}]]`
+	if params := node.lastParams("invokefunction"); params != want {
+		t.Fatalf("params %v\nwant   %v", params, want)
+	}
+
+	// 本地生成的同一个调用脚本与节点按参数生成的脚本一致，说明参数的字节序正确
+	local, err := neotransaction.BuildCallMethodScript(neoutils.Reverse(mustHASH160(contract)), "balanceOf", []interface{}{account}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(local) != script {
+		t.Fatalf("local script %x\nnode script  %v", local, script)
+	}
+
+	// Hash256 参数同样使用大端序
+	args, err := contractParams([]interface{}{mustHASH256(neotransaction.AssetGasID)})
+	if err != nil || len(args) != 1 || args[0].Type != "Hash256" || args[0].Value != neotransaction.AssetGasID {
+		t.Fatalf("Hash256 param %+v %v", args, err)
+	}
+}
@@ -0,0 +1,426 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// Witness 区块或交易的鉴证人脚本
+type Witness struct {
+	Invocation   []byte
+	Verification []byte
+}
+
+type witnessJSON struct {
+	Invocation   string `json:"invocation"`
+	Verification string `json:"verification"`
+}
+
+func (w *witnessJSON) toWitness() (Witness, error) {
+	invocation, err := hex.DecodeString(w.Invocation)
+	if err != nil {
+		return Witness{}, fmt.Errorf("invalid invocation script: %v", err)
+	}
+	verification, err := hex.DecodeString(w.Verification)
+	if err != nil {
+		return Witness{}, fmt.Errorf("invalid verification script: %v", err)
+	}
+	return Witness{Invocation: invocation, Verification: verification}, nil
+}
+
+// BlockHeader 区块头
+type BlockHeader struct {
+	Hash          neoutils.HASH256
+	Size          int
+	Version       uint32
+	PrevHash      neoutils.HASH256
+	MerkleRoot    neoutils.HASH256
+	Time          uint32
+	Index         uint32
+	Nonce         uint64
+	NextConsensus string
+	Script        Witness
+	Confirmations uint32
+	NextBlockHash neoutils.HASH256 // 最新区块没有下一个区块，为nil
+}
+
+type blockHeaderJSON struct {
+	Hash          string      `json:"hash"`
+	Size          int         `json:"size"`
+	Version       uint32      `json:"version"`
+	PrevHash      string      `json:"previousblockhash"`
+	MerkleRoot    string      `json:"merkleroot"`
+	Time          uint32      `json:"time"`
+	Index         uint32      `json:"index"`
+	Nonce         string      `json:"nonce"`
+	NextConsensus string      `json:"nextconsensus"`
+	Script        witnessJSON `json:"script"`
+	Confirmations uint32      `json:"confirmations"`
+	NextBlockHash string      `json:"nextblockhash"`
+}
+
+func (raw *blockHeaderJSON) toHeader(header *BlockHeader) error {
+	var err error
+	if header.Hash, err = neoutils.ParseHASH256(raw.Hash); err != nil {
+		return fmt.Errorf("block hash %v", err)
+	}
+	if header.PrevHash, err = neoutils.ParseHASH256(raw.PrevHash); err != nil {
+		return fmt.Errorf("block previousblockhash %v", err)
+	}
+	if header.MerkleRoot, err = neoutils.ParseHASH256(raw.MerkleRoot); err != nil {
+		return fmt.Errorf("block merkleroot %v", err)
+	}
+	if raw.NextBlockHash != `` {
+		if header.NextBlockHash, err = neoutils.ParseHASH256(raw.NextBlockHash); err != nil {
+			return fmt.Errorf("block nextblockhash %v", err)
+		}
+	}
+	if header.Nonce, err = strconv.ParseUint(raw.Nonce, 16, 64); err != nil {
+		return fmt.Errorf("block nonce %v", err)
+	}
+	if header.Script, err = raw.Script.toWitness(); err != nil {
+		return fmt.Errorf("block script %v", err)
+	}
+	header.Size = raw.Size
+	header.Version = raw.Version
+	header.Time = raw.Time
+	header.Index = raw.Index
+	header.NextConsensus = raw.NextConsensus
+	header.Confirmations = raw.Confirmations
+	return nil
+}
+
+// UnmarshalJSON 从 getblockheader 的 verbose 结果解析区块头
+func (header *BlockHeader) UnmarshalJSON(data []byte) error {
+	raw := blockHeaderJSON{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return raw.toHeader(header)
+}
+
+// Block 区块
+type Block struct {
+	BlockHeader
+	Transactions []*Transaction
+	Raw          json.RawMessage // 节点返回的原始json
+}
+
+// UnmarshalJSON 从 getblock 的 verbose 结果解析区块
+func (block *Block) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		blockHeaderJSON
+		Tx []*Transaction `json:"tx"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := raw.blockHeaderJSON.toHeader(&block.BlockHeader); err != nil {
+		return err
+	}
+	block.Transactions = raw.Tx
+	block.Raw = append(json.RawMessage{}, data...)
+	return nil
+}
+
+// TxAttribute 交易属性
+type TxAttribute struct {
+	Usage byte
+	Data  []byte
+}
+
+// TxInput 交易输入，引用之前交易的一个输出
+type TxInput struct {
+	PrevHash  neoutils.HASH256
+	PrevIndex uint16
+}
+
+type txInputJSON struct {
+	TxID string `json:"txid"`
+	Vout uint16 `json:"vout"`
+}
+
+func parseTxInputs(raws []txInputJSON) ([]TxInput, error) {
+	inputs := make([]TxInput, 0, len(raws))
+	for _, raw := range raws {
+		hash, err := neoutils.ParseHASH256(raw.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("input txid %v", err)
+		}
+		inputs = append(inputs, TxInput{PrevHash: hash, PrevIndex: raw.Vout})
+	}
+	return inputs, nil
+}
+
+// TxOutput 交易输出，金额以 10e-8 为单位
+type TxOutput struct {
+	N          uint16
+	AssetID    neoutils.HASH256
+	Value      neoutils.Fixed8
+	Address    string
+	ScriptHash neoutils.HASH160
+}
+
+type txOutputJSON struct {
+	N       uint16          `json:"n"`
+	Asset   string          `json:"asset"`
+	Value   neoutils.Fixed8 `json:"value"`
+	Address string          `json:"address"`
+}
+
+func (raw *txOutputJSON) toOutput() (*TxOutput, error) {
+	output := &TxOutput{N: raw.N, Value: raw.Value, Address: raw.Address}
+	var err error
+	if output.AssetID, err = neoutils.ParseHASH256(raw.Asset); err != nil {
+		return nil, fmt.Errorf("output asset %v", err)
+	}
+	addr, err := neotransaction.ParseAddress(raw.Address)
+	if err != nil {
+		return nil, fmt.Errorf("output address %v", err)
+	}
+	output.ScriptHash = addr.ScripHash
+	return output, nil
+}
+
+// RegisteredAsset RegisterTransaction 登记的资产信息
+type RegisteredAsset struct {
+	Type      string
+	Name      json.RawMessage // 可能是字符串，也可能是多语言名称数组
+	Amount    neoutils.Fixed8
+	Precision byte
+	Owner     []byte
+	Admin     string
+}
+
+// StateDescriptor StateTransaction 中的状态描述
+type StateDescriptor struct {
+	Type  string
+	Key   []byte
+	Field string
+	Value []byte
+}
+
+// Transaction 交易，包含所有交易类型的字段，金额均以 10e-8 为单位
+type Transaction struct {
+	TxID       neoutils.HASH256
+	Size       int
+	Type       byte
+	Version    byte
+	Attributes []TxAttribute
+	Inputs     []TxInput
+	Outputs    []TxOutput
+	Scripts    []Witness
+	SysFee     neoutils.Fixed8
+	NetFee     neoutils.Fixed8
+
+	Nonce       uint32            // MinerTransaction
+	Claims      []TxInput         // ClaimTransaction
+	Script      []byte            // InvocationTransaction
+	Gas         neoutils.Fixed8   // InvocationTransaction
+	Asset       *RegisteredAsset  // RegisterTransaction
+	PubKey      []byte            // EnrollmentTransaction
+	Contract    json.RawMessage   // PublishTransaction
+	Descriptors []StateDescriptor // StateTransaction
+
+	// 已经上链的交易才有以下字段
+	BlockHash     neoutils.HASH256
+	Confirmations uint32
+	BlockTime     uint32
+}
+
+// UnmarshalJSON 从 getrawtransaction 的 verbose 结果或区块中的交易解析
+func (tx *Transaction) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		TxID       string `json:"txid"`
+		Size       int    `json:"size"`
+		Type       string `json:"type"`
+		Version    byte   `json:"version"`
+		Attributes []struct {
+			Usage string `json:"usage"`
+			Data  string `json:"data"`
+		} `json:"attributes"`
+		Vin     []txInputJSON   `json:"vin"`
+		Vout    []txOutputJSON  `json:"vout"`
+		SysFee  neoutils.Fixed8 `json:"sys_fee"`
+		NetFee  neoutils.Fixed8 `json:"net_fee"`
+		Scripts []witnessJSON   `json:"scripts"`
+
+		Nonce  uint32          `json:"nonce"`
+		Claims []txInputJSON   `json:"claims"`
+		Script string          `json:"script"`
+		Gas    neoutils.Fixed8 `json:"gas"`
+		Asset  *struct {
+			Type      string          `json:"type"`
+			Name      json.RawMessage `json:"name"`
+			Amount    neoutils.Fixed8 `json:"amount"`
+			Precision byte            `json:"precision"`
+			Owner     string          `json:"owner"`
+			Admin     string          `json:"admin"`
+		} `json:"asset"`
+		PubKey      string          `json:"pubkey"`
+		Contract    json.RawMessage `json:"contract"`
+		Descriptors []struct {
+			Type  string `json:"type"`
+			Key   string `json:"key"`
+			Field string `json:"field"`
+			Value string `json:"value"`
+		} `json:"descriptors"`
+
+		BlockHash     string `json:"blockhash"`
+		Confirmations uint32 `json:"confirmations"`
+		BlockTime     uint32 `json:"blocktime"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var err error
+	if tx.TxID, err = neoutils.ParseHASH256(raw.TxID); err != nil {
+		return fmt.Errorf("transaction txid %v", err)
+	}
+	txType, ok := neotransaction.ParseTransactionType(raw.Type)
+	if !ok {
+		return fmt.Errorf("transaction[%s] type %s unknown", raw.TxID, raw.Type)
+	}
+	tx.Type = txType
+	tx.Size = raw.Size
+	tx.Version = raw.Version
+
+	tx.Attributes = make([]TxAttribute, 0, len(raw.Attributes))
+	for _, attr := range raw.Attributes {
+		usage, ok := neotransaction.ParseAttributeUsage(attr.Usage)
+		if !ok {
+			return fmt.Errorf("transaction[%s] attribute usage %s unknown", raw.TxID, attr.Usage)
+		}
+		attrData, err := hex.DecodeString(attr.Data)
+		if err != nil {
+			return fmt.Errorf("transaction[%s] attribute data %v", raw.TxID, err)
+		}
+		tx.Attributes = append(tx.Attributes, TxAttribute{Usage: usage, Data: attrData})
+	}
+
+	if tx.Inputs, err = parseTxInputs(raw.Vin); err != nil {
+		return fmt.Errorf("transaction[%s] %v", raw.TxID, err)
+	}
+
+	tx.Outputs = make([]TxOutput, 0, len(raw.Vout))
+	for _, vout := range raw.Vout {
+		output, err := vout.toOutput()
+		if err != nil {
+			return fmt.Errorf("transaction[%s] %v", raw.TxID, err)
+		}
+		tx.Outputs = append(tx.Outputs, *output)
+	}
+
+	tx.Scripts = make([]Witness, 0, len(raw.Scripts))
+	for _, script := range raw.Scripts {
+		witness, err := script.toWitness()
+		if err != nil {
+			return fmt.Errorf("transaction[%s] %v", raw.TxID, err)
+		}
+		tx.Scripts = append(tx.Scripts, witness)
+	}
+
+	tx.SysFee = raw.SysFee
+	tx.NetFee = raw.NetFee
+
+	switch txType {
+	case neotransaction.MinerTranscation:
+		tx.Nonce = raw.Nonce
+	case neotransaction.ClaimTransaction:
+		if tx.Claims, err = parseTxInputs(raw.Claims); err != nil {
+			return fmt.Errorf("transaction[%s] claims %v", raw.TxID, err)
+		}
+	case neotransaction.InvocationTransacton:
+		if tx.Script, err = hex.DecodeString(raw.Script); err != nil {
+			return fmt.Errorf("transaction[%s] script %v", raw.TxID, err)
+		}
+		tx.Gas = raw.Gas
+	case neotransaction.RegisterTransaction:
+		if raw.Asset != nil {
+			tx.Asset = &RegisteredAsset{
+				Type:      raw.Asset.Type,
+				Name:      raw.Asset.Name,
+				Amount:    raw.Asset.Amount,
+				Precision: raw.Asset.Precision,
+				Admin:     raw.Asset.Admin,
+			}
+			if tx.Asset.Owner, err = hex.DecodeString(raw.Asset.Owner); err != nil {
+				return fmt.Errorf("transaction[%s] asset owner %v", raw.TxID, err)
+			}
+		}
+	case neotransaction.EnrollmentTransaction:
+		if tx.PubKey, err = hex.DecodeString(raw.PubKey); err != nil {
+			return fmt.Errorf("transaction[%s] pubkey %v", raw.TxID, err)
+		}
+	case neotransaction.PublishTransaction:
+		tx.Contract = raw.Contract
+	case neotransaction.StateTransaction:
+		tx.Descriptors = make([]StateDescriptor, 0, len(raw.Descriptors))
+		for _, d := range raw.Descriptors {
+			descriptor := StateDescriptor{Type: d.Type, Field: d.Field}
+			if descriptor.Key, err = hex.DecodeString(d.Key); err != nil {
+				return fmt.Errorf("transaction[%s] descriptor key %v", raw.TxID, err)
+			}
+			if descriptor.Value, err = hex.DecodeString(d.Value); err != nil {
+				return fmt.Errorf("transaction[%s] descriptor value %v", raw.TxID, err)
+			}
+			tx.Descriptors = append(tx.Descriptors, descriptor)
+		}
+	}
+
+	if raw.BlockHash != `` {
+		if tx.BlockHash, err = neoutils.ParseHASH256(raw.BlockHash); err != nil {
+			return fmt.Errorf("transaction[%s] blockhash %v", raw.TxID, err)
+		}
+	}
+	tx.Confirmations = raw.Confirmations
+	tx.BlockTime = raw.BlockTime
+	return nil
+}
+
+// GetBlock 获取指定高度的区块，解析为 Block 结构体
+func GetBlock(url string, height uint64) (*Block, error) {
+	return NewClient(url).GetBlock(context.Background(), height)
+}
+
+// GetBlock 获取指定高度的区块，解析为 Block 结构体
+func (c *Client) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	block := &Block{}
+	err := c.callResult(ctx, block, `getblock`, height, 1)
+	if err != nil {
+		return nil, fmt.Errorf(`GetBlock[%v] error: %w`, height, err)
+	}
+	return block, nil
+}
+
+// GetBlockHeader 根据区块哈希获取区块头
+func (c *Client) GetBlockHeader(ctx context.Context, hash string) (*BlockHeader, error) {
+	header := &BlockHeader{}
+	err := c.callResult(ctx, header, `getblockheader`, hash, 1)
+	if err != nil {
+		return nil, fmt.Errorf(`GetBlockHeader[%s] error: %w`, hash, err)
+	}
+	return header, nil
+}
+
+// GetTransaction 获取交易，解析为 Transaction 结构体
+func GetTransaction(url string, txid string) (*Transaction, error) {
+	return NewClient(url).GetTransaction(context.Background(), txid)
+}
+
+// GetTransaction 获取交易，解析为 Transaction 结构体
+func (c *Client) GetTransaction(ctx context.Context, txid string) (*Transaction, error) {
+	tx := &Transaction{}
+	err := c.callResult(ctx, tx, `getrawtransaction`, txid, 1)
+	if err != nil {
+		return nil, fmt.Errorf(`GetTransaction[%s] error: %w`, txid, err)
+	}
+	return tx, nil
+}
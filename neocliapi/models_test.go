@@ -0,0 +1,187 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// verboseBlock getblock verbose 格式的合成应答，包含 Miner、Claim、Invocation 和 Contract 交易
+const verboseBlock = `{"hash":"0x4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2","size":1236,"version":0,` +
+	`"previousblockhash":"0x2d8c2b5f4a3e1c0d9b8a7f6e5d4c3b2a1908f7e6d5c4b3a29180f7e6d5c4b3a2",` +
+	`"merkleroot":"0xd6ba8b0f381897a59396394e9ce266a3d1d0857b6b18b9bb9d2d0bc5c3e1a3f1",` +
+	`"time":1554283931,"index":3000000,"nonce":"0000000000000001","nextconsensus":"APyEx5f4Zm4oCHwFWiSTaph1fPBxZacYVR",` +
+	`"script":{"invocation":"40a1b2","verification":"51ae"},` +
+	`"tx":[` +
+	`{"txid":"0x1f1d5d6c3a4c3d5b0cb6c9ad5e2a6e5b1a5b0b7f0c8f2d1e0a9b8c7d6e5f4a3b","size":10,"type":"MinerTransaction","version":0,` +
+	`"attributes":[],"vin":[],"vout":[],"sys_fee":"0","net_fee":"0","scripts":[],"nonce":2736159045},` +
+	`{"txid":"0x2e8f1c4b3a2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f","size":203,"type":"ClaimTransaction","version":0,` +
+	`"attributes":[],"vin":[],"vout":[{"n":0,"asset":"0x602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7","value":"0.0615",` +
+	`"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}],"sys_fee":"0","net_fee":"0",` +
+	`"scripts":[{"invocation":"40aa","verification":"21bbac"}],` +
+	`"claims":[{"txid":"0xf4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657","vout":0},` +
+	`{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","vout":3}]},` +
+	`{"txid":"0x3c7b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b","size":156,"type":"InvocationTransaction","version":1,` +
+	`"attributes":[{"usage":"Script","data":"0b4f225d9b691328bae1a275c5c2e5bc3847caae"},{"usage":"Remark1","data":"68656c6c6f"}],` +
+	`"vin":[],"vout":[],"sys_fee":"1","net_fee":"0.001","scripts":[{"invocation":"40cc","verification":"21ddac"}],` +
+	`"script":"00c1046e616d6567f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec","gas":"1"},` +
+	`{"txid":"0x4d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c","size":202,"type":"ContractTransaction","version":0,` +
+	`"attributes":[],"vin":[{"txid":"0xf4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657","vout":1}],` +
+	`"vout":[{"n":0,"asset":"0xc56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b","value":"10",` +
+	`"address":"AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt"},` +
+	`{"n":1,"asset":"0xc56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b","value":"90",` +
+	`"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}],"sys_fee":"0","net_fee":"0",` +
+	`"scripts":[{"invocation":"40ee","verification":"21ffac"}]}],` +
+	`"confirmations":20,"nextblockhash":"0x8a3fa0a11d1b1f3b43c2a8b1e5d7b9c0f2e4d6a8c0b2d4f6e8a0c2e4f6a8b0c2"}`
+
+func TestBlockUnmarshalJSON(t *testing.T) {
+	block := &Block{}
+	if err := json.Unmarshal([]byte(verboseBlock), block); err != nil {
+		t.Fatal(err)
+	}
+	header := BlockHeader{
+		Hash:          mustHASH256("4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2"),
+		Size:          1236,
+		PrevHash:      mustHASH256("2d8c2b5f4a3e1c0d9b8a7f6e5d4c3b2a1908f7e6d5c4b3a29180f7e6d5c4b3a2"),
+		MerkleRoot:    mustHASH256("d6ba8b0f381897a59396394e9ce266a3d1d0857b6b18b9bb9d2d0bc5c3e1a3f1"),
+		Time:          1554283931,
+		Index:         3000000,
+		Nonce:         1,
+		NextConsensus: "APyEx5f4Zm4oCHwFWiSTaph1fPBxZacYVR",
+		Script:        Witness{Invocation: mustHex("40a1b2"), Verification: mustHex("51ae")},
+		Confirmations: 20,
+		NextBlockHash: mustHASH256("8a3fa0a11d1b1f3b43c2a8b1e5d7b9c0f2e4d6a8c0b2d4f6e8a0c2e4f6a8b0c2"),
+	}
+	if !reflect.DeepEqual(block.BlockHeader, header) {
+		t.Fatalf("header %+v\nwant   %+v", block.BlockHeader, header)
+	}
+	if string(block.Raw) != verboseBlock {
+		t.Fatal("raw json not kept")
+	}
+
+	from := mustAddressHash("AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	to := mustAddressHash("AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt")
+	neo := mustHASH256(neotransaction.AssetNeoID)
+	want := []*Transaction{
+		{
+			TxID:       mustHASH256("1f1d5d6c3a4c3d5b0cb6c9ad5e2a6e5b1a5b0b7f0c8f2d1e0a9b8c7d6e5f4a3b"),
+			Size:       10,
+			Type:       neotransaction.MinerTranscation,
+			Attributes: []TxAttribute{},
+			Inputs:     []TxInput{},
+			Outputs:    []TxOutput{},
+			Scripts:    []Witness{},
+			Nonce:      2736159045,
+		},
+		{
+			TxID:       mustHASH256("2e8f1c4b3a2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f"),
+			Size:       203,
+			Type:       neotransaction.ClaimTransaction,
+			Attributes: []TxAttribute{},
+			Inputs:     []TxInput{},
+			Outputs: []TxOutput{{N: 0, AssetID: mustHASH256(neotransaction.AssetGasID), Value: 6150000,
+				Address: "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt", ScriptHash: from}},
+			Scripts: []Witness{{Invocation: mustHex("40aa"), Verification: mustHex("21bbac")}},
+			Claims: []TxInput{
+				{PrevHash: mustHASH256("f4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657"), PrevIndex: 0},
+				{PrevHash: mustHASH256("9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e"), PrevIndex: 3},
+			},
+		},
+		{
+			TxID:    mustHASH256("3c7b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b"),
+			Size:    156,
+			Type:    neotransaction.InvocationTransacton,
+			Version: 1,
+			Attributes: []TxAttribute{
+				{Usage: neotransaction.UsageScript, Data: mustHex("0b4f225d9b691328bae1a275c5c2e5bc3847caae")},
+				{Usage: neotransaction.UsageRemark + 1, Data: []byte("hello")},
+			},
+			Inputs:  []TxInput{},
+			Outputs: []TxOutput{},
+			Scripts: []Witness{{Invocation: mustHex("40cc"), Verification: mustHex("21ddac")}},
+			SysFee:  neoutils.Fixed8One,
+			NetFee:  100000,
+			Script:  mustHex("00c1046e616d6567f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec"),
+			Gas:     neoutils.Fixed8One,
+		},
+		{
+			TxID:       mustHASH256("4d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c"),
+			Size:       202,
+			Type:       neotransaction.ContractTransaction,
+			Attributes: []TxAttribute{},
+			Inputs:     []TxInput{{PrevHash: mustHASH256("f4250dab094c38d8265acc15c366dc508d2e14bf5699e12d9df26577ed74d657"), PrevIndex: 1}},
+			Outputs: []TxOutput{
+				{N: 0, AssetID: neo, Value: 10 * neoutils.Fixed8One, Address: "AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt", ScriptHash: to},
+				{N: 1, AssetID: neo, Value: 90 * neoutils.Fixed8One, Address: "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt", ScriptHash: from},
+			},
+			Scripts: []Witness{{Invocation: mustHex("40ee"), Verification: mustHex("21ffac")}},
+		},
+	}
+	if len(block.Transactions) != len(want) {
+		t.Fatalf("%v transactions", len(block.Transactions))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(block.Transactions[i], want[i]) {
+			t.Fatalf("transaction %v\ngot  %+v\nwant %+v", i, block.Transactions[i], want[i])
+		}
+	}
+
+	node := newSyntheticNode(t, map[string]string{"getblock": verboseBlock})
+	fetched, err := NewClient(node.URL).GetBlock(context.Background(), 3000000)
+	if err != nil || !reflect.DeepEqual(fetched.Transactions, block.Transactions) {
+		t.Fatalf("GetBlock %+v %v", fetched, err)
+	}
+	if params := node.lastParams("getblock"); params != `[3000000,1]` {
+		t.Fatalf("params %v", params)
+	}
+}
+
+func TestTransactionUnmarshalJSON(t *testing.T) {
+	// getrawtransaction 返回的已上链交易带有区块信息
+	data := `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","size":60,"type":"ContractTransaction","version":0,` +
+		`"attributes":[],"vin":[],"vout":[],"sys_fee":"0","net_fee":"0","scripts":[],` +
+		`"blockhash":"0x4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2","confirmations":5,"blocktime":1554283931}`
+	tx := &Transaction{}
+	if err := json.Unmarshal([]byte(data), tx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.BlockHash.ToHexString() != "4c1e879872344349067c3b1a30781eeb4f9040d3795db7922f513f6f9660b9b2" || tx.Confirmations != 5 || tx.BlockTime != 1554283931 {
+		t.Fatalf("transaction %+v", tx)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"UnknownType", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"UnknownTransaction"}`},
+		{"InvalidTxID", `{"txid":"0x9786","type":"ContractTransaction"}`},
+		{"UnknownUsage", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"ContractTransaction",` +
+			`"attributes":[{"usage":"Unknown","data":""}]}`},
+		{"InvalidInput", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"ContractTransaction",` +
+			`"vin":[{"txid":"0x01","vout":0}]}`},
+		{"InvalidOutputAddress", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"ContractTransaction",` +
+			`"vout":[{"n":0,"asset":"0x602c79718b16e442de58778e148d0b1084e3b2dffd5de6b7b16cee7969282de7","value":"1","address":"invalid"}]}`},
+		{"InvalidClaim", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"ClaimTransaction",` +
+			`"claims":[{"txid":"zz","vout":0}]}`},
+		{"InvalidScript", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"InvocationTransaction",` +
+			`"script":"0g","gas":"0"}`},
+		{"InvalidFee", `{"txid":"0x9786cce0dddb524c40ddbdd5e31a41ed1f6b5c8a683c122f627ca4a007a7cf4e","type":"ContractTransaction",` +
+			`"sys_fee":"0.000000001"}`},
+	}
+	for _, tt := range tests {
+		if err := json.Unmarshal([]byte(tt.data), &Transaction{}); err == nil {
+			t.Errorf("%v: parsed", tt.name)
+		}
+	}
+
+	// 区块中有一个交易无法解析时整个区块解析失败
+	broken := strings.Replace(verboseBlock, `"type":"ClaimTransaction"`, `"type":"UnknownTransaction"`, 1)
+	if err := json.Unmarshal([]byte(broken), &Block{}); err == nil {
+		t.Fatal("block with an unknown transaction type parsed")
+	}
+}
@@ -1,8 +1,12 @@
 package neocliapi
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
-	"time"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
 )
 
 // CurrBlockHeight 当前已经抓取到的区块高度
@@ -14,38 +18,127 @@ var NEOCLIURL = ``
 // NewBlockChan 新区块的Channel
 var NewBlockChan = make(chan map[string]interface{})
 
-// StartSpider 开始监听NEO节点
-func StartSpider(cliurl string, fromHeight uint64) chan map[string]interface{} {
+// spiderMaxRetries StartSpider 中同一个区块连续获取或处理失败的最大重试次数，超过后停止监听
+const spiderMaxRetries = 5
+
+// spiderConfig StartSpider 的配置
+type spiderConfig struct {
+	ctx           context.Context
+	confirmations uint64
+	rollback      chan map[string]interface{}
+}
+
+// SpiderOption StartSpider 的可选配置
+type SpiderOption func(c *spiderConfig)
+
+// WithSpiderConfirmations 区块得到 confirmations 个确认后才发送到 NewBlockChan
+func WithSpiderConfirmations(confirmations uint64) SpiderOption {
+	return func(c *spiderConfig) {
+		c.confirmations = confirmations
+	}
+}
+
+// WithSpiderRollback 发生链重组时将被孤立的区块按高度从高到低发送到 rollback，之后 NewBlockChan 会从分叉点重新发送区块
+// 没有设置时只记录日志，NewBlockChan 同样从分叉点重新发送区块
+func WithSpiderRollback(rollback chan map[string]interface{}) SpiderOption {
+	return func(c *spiderConfig) {
+		c.rollback = rollback
+	}
+}
+
+// WithSpiderContext ctx 被取消后停止监听，没有设置时监听不会停止
+func WithSpiderContext(ctx context.Context) SpiderOption {
+	return func(c *spiderConfig) {
+		c.ctx = ctx
+	}
+}
+
+// StartSpider 开始监听NEO节点，从 fromHeight 的下一个区块开始发送到 NewBlockChan
+// 不能同时监听多个节点，新代码请使用 BlockFollower
+// 同一个区块连续失败超过5次（节点不可达除外）或链重组深度超过 DefaultMaxReorgDepth 时，记录日志并停止监听
+func StartSpider(cliurl string, fromHeight uint64, opts ...SpiderOption) chan map[string]interface{} {
 	NEOCLIURL = cliurl
 	CurrBlockHeight = fromHeight
 	log.Printf("neoapi: fetch init block height[%v]\n", CurrBlockHeight)
-	go func() {
-		for {
-			fetchBlock()
-			time.Sleep(3 * time.Second)
-		}
-	}()
+
+	config := &spiderConfig{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(config)
+	}
+	go runSpider(config.ctx, newSpiderFollower(cliurl, fromHeight, config), config.rollback)
 	return NewBlockChan
 }
 
-func fetchBlock() {
-	height, err := FetchBlockHeight(NEOCLIURL)
-	if err != nil {
-		log.Println(`neoapi: fetch block height error`, err)
-		return
-	}
-	if height <= CurrBlockHeight {
-		return
+// newSpiderFollower 创建 StartSpider 使用的区块跟踪器
+func newSpiderFollower(cliurl string, fromHeight uint64, config *spiderConfig) *BlockFollower {
+	follower := NewBlockFollower(spiderSource{newLegacyClient(cliurl)}, fromHeight+1)
+	follower.Confirmations = config.confirmations
+	follower.MaxRetries = spiderMaxRetries
+	follower.OnError = func(err error) {
+		log.Println(`neoapi:`, err)
 	}
+	return follower
+}
 
-	for i := CurrBlockHeight + 1; i <= height; i++ {
-		block, err := FetchBlock(NEOCLIURL, i)
-		if err != nil {
-			log.Printf("neoapi: fetch block[%v] %v\n", i, err)
-			return
+// runSpider 将 follower 跟踪到的区块发送到 NewBlockChan，被孤立的区块发送到 rollback，follower 停止后返回
+func runSpider(ctx context.Context, follower *BlockFollower, rollback chan map[string]interface{}) error {
+	err := follower.Run(ctx, func(ctx context.Context, event *BlockEvent) error {
+		block := make(map[string]interface{})
+		if err := json.Unmarshal(event.Block.Raw, &block); err != nil {
+			return err
+		}
+		if event.Type == BlockRollback {
+			log.Printf("neoapi: rollback block[%v]\n", event.Height)
+			if rollback != nil {
+				select {
+				case rollback <- block:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			CurrBlockHeight = event.Height - 1
+			return nil
+		}
+		select {
+		case NewBlockChan <- block:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		NewBlockChan <- block
-		CurrBlockHeight = i
+		CurrBlockHeight = event.Height
+		return nil
+	})
+	log.Println(`neoapi: spider stopped:`, err)
+	return err
+}
+
+// spiderSource StartSpider 的区块来源，只解析跟踪区块需要的 hash、previousblockhash 和 index，
+// 其余字段原样放在 Raw 中，与原有实现一样不因为个别交易字段无法解析而阻塞监听
+type spiderSource struct {
+	*Client
+}
+
+// GetBlock 获取高度为 height 的区块
+func (s spiderSource) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	raw := json.RawMessage{}
+	if err := s.callResult(ctx, &raw, `getblock`, height, 1); err != nil {
+		return nil, fmt.Errorf(`GetBlock[%v] error: %w`, height, err)
+	}
+	header := struct {
+		Hash     string `json:"hash"`
+		PrevHash string `json:"previousblockhash"`
+		Index    uint32 `json:"index"`
+	}{}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf(`GetBlock[%v] error: %w`, height, &DecodeError{Body: raw, Err: err})
+	}
+	block := &Block{Raw: raw}
+	var err error
+	if block.Hash, err = neoutils.ParseHASH256(header.Hash); err != nil {
+		return nil, fmt.Errorf(`GetBlock[%v] error: %w`, height, &DecodeError{Body: raw, Err: fmt.Errorf("block hash %v", err)})
+	}
+	if block.PrevHash, err = neoutils.ParseHASH256(header.PrevHash); err != nil {
+		return nil, fmt.Errorf(`GetBlock[%v] error: %w`, height, &DecodeError{Body: raw, Err: fmt.Errorf("block previousblockhash %v", err)})
 	}
-	CurrBlockHeight = height
+	block.Index = header.Index
+	return block, nil
 }
@@ -0,0 +1,172 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// spiderBlock 返回高度为 height 的区块的 getblock 应答，区块哈希为 height+1
+// 交易的字段不完整，BlockFollower 的类型化解析会失败，StartSpider 需要原样传递
+func spiderBlock(params []json.RawMessage) string {
+	height := uint64(0)
+	if err := json.Unmarshal(params[0], &height); err != nil {
+		return `error:{"code":-32602,"message":"Invalid params"}`
+	}
+	return fmt.Sprintf(`{"hash":"0x%064x","previousblockhash":"0x%064x","index":%v,"tx":[{"txid":"0x%064x","type":"MinerTransaction"}]}`,
+		height+1, height, height, height)
+}
+
+func TestStartSpider(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{"getblockcount": `4`})
+	node.handle("getblock", spiderBlock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks := StartSpider(node.URL, 0, WithSpiderContext(ctx))
+	for height := 1; height <= 3; height++ {
+		select {
+		case block := <-blocks:
+			if block["index"] != float64(height) || block["hash"] != fmt.Sprintf("0x%064x", height+1) {
+				t.Fatalf("block %v", block)
+			}
+			if _, ok := block["tx"].([]interface{}); !ok {
+				t.Fatalf("block[%v] tx not passed through: %v", height, block)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block[%v] not received", height)
+		}
+	}
+	// 区块3处理完成后监听会再次查询区块高度
+	for deadline := time.Now().Add(5 * time.Second); node.count("getblockcount") < 2; {
+		if time.Now().After(deadline) {
+			t.Fatal("spider not polling after the last block")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if CurrBlockHeight != 3 {
+		t.Fatalf("CurrBlockHeight %v", CurrBlockHeight)
+	}
+}
+
+func TestSpiderStopsOnBadBlock(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{"getblockcount": `3`})
+	node.handle("getblock", func(params []json.RawMessage) string {
+		if string(params[0]) == "2" {
+			return `"not a block"`
+		}
+		return spiderBlock(params)
+	})
+	follower := newSpiderFollower(node.URL, 0, &spiderConfig{})
+	follower.MinBackoff = time.Millisecond
+	follower.MaxBackoff = time.Millisecond
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runSpider(context.Background(), follower, nil)
+	}()
+	select {
+	case block := <-NewBlockChan:
+		if block["index"] != float64(1) {
+			t.Fatalf("block %v", block)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("block[1] not received")
+	}
+	select {
+	case err := <-done:
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("expected DecodeError, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("spider still retrying an undecodable block")
+	}
+	// 区块1获取一次，区块2首次获取加上 spiderMaxRetries 次重试
+	if calls := node.count("getblock"); calls != 1+1+spiderMaxRetries {
+		t.Fatalf("getblock called %v times", calls)
+	}
+}
+
+// forkingNode 返回区块0~2的节点，fork 后区块2被替换并增加区块3
+func forkingNode(t *testing.T) (*syntheticNode, func()) {
+	node := newSyntheticNode(t, map[string]string{"getblockcount": `3`})
+	forked := int32(0)
+	node.handle("getblock", func(params []json.RawMessage) string {
+		if atomic.LoadInt32(&forked) == 0 || string(params[0]) < "2" {
+			return spiderBlock(params)
+		}
+		height := uint64(0)
+		json.Unmarshal(params[0], &height)
+		prev := fmt.Sprintf("%064x", height)
+		if height > 2 {
+			prev = fmt.Sprintf("%064x", 0xf00+height)
+		}
+		return fmt.Sprintf(`{"hash":"0x%064x","previousblockhash":"0x%s","index":%v,"tx":[]}`, 0xf00+height+1, prev, height)
+	})
+	return node, func() {
+		atomic.StoreInt32(&forked, 1)
+		node.set("getblockcount", `4`)
+	}
+}
+
+// receiveBlock 从 blocks 接收一个区块并返回其哈希
+func receiveBlock(t *testing.T, blocks chan map[string]interface{}) interface{} {
+	select {
+	case block := <-blocks:
+		return block["hash"]
+	case <-time.After(5 * time.Second):
+		t.Fatal("block not received")
+		return nil
+	}
+}
+
+func TestSpiderRollback(t *testing.T) {
+	node, fork := forkingNode(t)
+	rollback := make(chan map[string]interface{})
+	config := &spiderConfig{}
+	WithSpiderRollback(rollback)(config)
+	follower := newSpiderFollower(node.URL, 0, config)
+	follower.PollInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runSpider(ctx, follower, config.rollback)
+
+	receiveBlock(t, NewBlockChan)
+	if hash := receiveBlock(t, NewBlockChan); hash != fmt.Sprintf("0x%064x", 3) {
+		t.Fatalf("block[2] %v", hash)
+	}
+	fork()
+	// 被孤立的区块2先发送到 rollback，之后从分叉点重新发送
+	if hash := receiveBlock(t, rollback); hash != fmt.Sprintf("0x%064x", 3) {
+		t.Fatalf("orphaned block %v", hash)
+	}
+	for height := uint64(2); height <= 3; height++ {
+		if hash := receiveBlock(t, NewBlockChan); hash != fmt.Sprintf("0x%064x", 0xf00+height+1) {
+			t.Fatalf("block[%v] %v", height, hash)
+		}
+	}
+}
+
+func TestSpiderRollbackWithoutChannel(t *testing.T) {
+	node, fork := forkingNode(t)
+	follower := newSpiderFollower(node.URL, 0, &spiderConfig{})
+	follower.PollInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runSpider(ctx, follower, nil)
+
+	receiveBlock(t, NewBlockChan)
+	receiveBlock(t, NewBlockChan)
+	fork()
+	// 与原有实现一样不停止监听，记录日志后从分叉点重新发送区块
+	for height := uint64(2); height <= 3; height++ {
+		if hash := receiveBlock(t, NewBlockChan); hash != fmt.Sprintf("0x%064x", 0xf00+height+1) {
+			t.Fatalf("block[%v] %v", height, hash)
+		}
+	}
+}
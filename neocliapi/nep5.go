@@ -0,0 +1,242 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// NEP5Token 绑定到一个NEP-5合约的客户端，查询接口通过 invokescript 在节点上试运行，不上链
+type NEP5Token struct {
+	Client   *Client
+	Contract neoutils.HASH160 // 合约的ScriptHash，与 ParseHASH160 的结果字节序相同（小端序）
+
+	mu       sync.Mutex
+	decimals *int // 精度不会变化，查询一次后缓存
+}
+
+// NEP5TokenInfo NEP-5资产的基本信息
+type NEP5TokenInfo struct {
+	Name        string
+	Symbol      string
+	Decimals    int
+	TotalSupply *big.Int
+}
+
+// NewNEP5Token 创建一个通过 client 访问 contract 合约的NEP-5客户端
+func NewNEP5Token(client *Client, contract neoutils.HASH160) *NEP5Token {
+	return &NEP5Token{Client: client, Contract: contract}
+}
+
+// appCallHash EmitAppCall 的参数是大端序的合约哈希
+func (t *NEP5Token) appCallHash() neoutils.HASH160 {
+	return neoutils.Reverse(t.Contract)
+}
+
+// invoke 依次试运行多个无参数的合约方法，返回每个方法的返回值
+func (t *NEP5Token) invoke(ctx context.Context, methods []string, args ...interface{}) ([]Argument, error) {
+	script := make([]byte, 0)
+	for _, method := range methods {
+		s, err := neotransaction.BuildCallMethodScript(t.appCallHash(), method, args, false)
+		if err != nil {
+			return nil, err
+		}
+		script = append(script, s...)
+	}
+	stack, _, err := t.Client.InvokeScript(ctx, script)
+	if err != nil {
+		return nil, err
+	}
+	if len(stack) != len(methods) {
+		return nil, fmt.Errorf(`%d items on the stack, want %d`, len(stack), len(methods))
+	}
+	return stack, nil
+}
+
+// call 试运行一个合约方法，返回它的返回值
+func (t *NEP5Token) call(ctx context.Context, method string, args ...interface{}) (Argument, error) {
+	stack, err := t.invoke(ctx, []string{method}, args...)
+	if err != nil {
+		return Argument{}, fmt.Errorf(`NEP5Token[%s] %s error: %w`, t.Contract.ToHexString(), method, err)
+	}
+	return stack[0], nil
+}
+
+// Name 查询资产名称
+func (t *NEP5Token) Name(ctx context.Context) (string, error) {
+	a, err := t.call(ctx, `name`)
+	if err != nil {
+		return ``, err
+	}
+	name, err := a.ToString()
+	if err != nil {
+		return ``, fmt.Errorf(`NEP5Token[%s] name error: %w`, t.Contract.ToHexString(), err)
+	}
+	return name, nil
+}
+
+// Symbol 查询资产符号
+func (t *NEP5Token) Symbol(ctx context.Context) (string, error) {
+	a, err := t.call(ctx, `symbol`)
+	if err != nil {
+		return ``, err
+	}
+	symbol, err := a.ToString()
+	if err != nil {
+		return ``, fmt.Errorf(`NEP5Token[%s] symbol error: %w`, t.Contract.ToHexString(), err)
+	}
+	return symbol, nil
+}
+
+// Decimals 查询资产精度，即金额的小数位数，结果会被缓存
+func (t *NEP5Token) Decimals(ctx context.Context) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.decimals != nil {
+		return *t.decimals, nil
+	}
+	a, err := t.call(ctx, `decimals`)
+	if err != nil {
+		return 0, err
+	}
+	v, err := a.ToBigInt()
+	if err == nil && (!v.IsInt64() || v.Int64() < 0 || v.Int64() > 255) {
+		err = fmt.Errorf(`invalid decimals %v`, v)
+	}
+	if err != nil {
+		return 0, fmt.Errorf(`NEP5Token[%s] decimals error: %w`, t.Contract.ToHexString(), err)
+	}
+	decimals := int(v.Int64())
+	t.decimals = &decimals
+	return decimals, nil
+}
+
+// TotalSupply 查询资产总量，以合约的最小单位计
+func (t *NEP5Token) TotalSupply(ctx context.Context) (*big.Int, error) {
+	a, err := t.call(ctx, `totalSupply`)
+	if err != nil {
+		return nil, err
+	}
+	v, err := a.ToBigInt()
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] totalSupply error: %w`, t.Contract.ToHexString(), err)
+	}
+	return v, nil
+}
+
+// BalanceOf 查询地址的余额，以合约的最小单位计
+func (t *NEP5Token) BalanceOf(ctx context.Context, scriptHash neoutils.HASH160) (*big.Int, error) {
+	a, err := t.call(ctx, `balanceOf`, scriptHash)
+	if err != nil {
+		return nil, err
+	}
+	v, err := a.ToBigInt()
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] balanceOf error: %w`, t.Contract.ToHexString(), err)
+	}
+	return v, nil
+}
+
+// Info 在一次 invokescript 中查询资产的名称、符号、精度和总量
+func (t *NEP5Token) Info(ctx context.Context) (*NEP5TokenInfo, error) {
+	stack, err := t.invoke(ctx, []string{`name`, `symbol`, `decimals`, `totalSupply`})
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] info error: %w`, t.Contract.ToHexString(), err)
+	}
+	info := &NEP5TokenInfo{}
+	info.Name, err = stack[0].ToString()
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] name error: %w`, t.Contract.ToHexString(), err)
+	}
+	info.Symbol, err = stack[1].ToString()
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] symbol error: %w`, t.Contract.ToHexString(), err)
+	}
+	decimals, err := stack[2].ToBigInt()
+	if err == nil && (!decimals.IsInt64() || decimals.Int64() < 0 || decimals.Int64() > 255) {
+		err = fmt.Errorf(`invalid decimals %v`, decimals)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] decimals error: %w`, t.Contract.ToHexString(), err)
+	}
+	info.TotalSupply, err = stack[3].ToBigInt()
+	if err != nil {
+		return nil, fmt.Errorf(`NEP5Token[%s] totalSupply error: %w`, t.Contract.ToHexString(), err)
+	}
+	info.Decimals = int(decimals.Int64())
+
+	t.mu.Lock()
+	t.decimals = &info.Decimals
+	t.mu.Unlock()
+	return info, nil
+}
+
+// ParseAmount 将十进制金额（如 "1.5"）按资产精度转换为合约的最小单位
+func (t *NEP5Token) ParseAmount(ctx context.Context, amount string) (*big.Int, error) {
+	decimals, err := t.Decimals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return neoutils.ParseDecimal(amount, decimals)
+}
+
+// FormatAmount 将合约的最小单位按资产精度格式化为十进制金额
+func (t *NEP5Token) FormatAmount(ctx context.Context, amount *big.Int) (string, error) {
+	decimals, err := t.Decimals(ctx)
+	if err != nil {
+		return ``, err
+	}
+	return neoutils.FormatDecimal(amount, decimals), nil
+}
+
+// CreateTransfer 创建一个未签名的 transfer 调用交易，from 作为 Script 属性加入交易，需要 from 的鉴证人签名
+// 多签地址等复杂账户可以使用 SigningContext 签名
+func (t *NEP5Token) CreateTransfer(from neoutils.HASH160, to neoutils.HASH160, amount *big.Int) (*neotransaction.NeoTransaction, error) {
+	if !from.IsValid() || !to.IsValid() {
+		return nil, errors.New(`NEP5Token transfer error: invalid script hash`)
+	}
+	if amount == nil || amount.Sign() < 0 {
+		return nil, fmt.Errorf(`NEP5Token transfer error: invalid amount %v`, amount)
+	}
+	// 同一金额的转账除随机数外交易内容相同，需要随机数避免交易hash冲突
+	script, err := neotransaction.BuildCallMethodScript(t.appCallHash(), `transfer`, []interface{}{from, to, amount}, true)
+	if err != nil {
+		return nil, err
+	}
+	tx := neotransaction.CreateInvocationTransaction()
+	tx.ExtraData.(*neotransaction.InvocationExtraData).Script = script
+	tx.AppendAttribute(neotransaction.UsageScript, from)
+	return tx, nil
+}
+
+// BuildTransfer 创建一个由 key 的基本账户签名的 transfer 调用交易
+func (t *NEP5Token) BuildTransfer(key *neotransaction.KeyPair, to neoutils.HASH160, amount *big.Int) (*neotransaction.NeoTransaction, error) {
+	tx, err := t.CreateTransfer(key.CreateBasicAddress().ScripHash, to, amount)
+	if err != nil {
+		return nil, err
+	}
+	tx.AppendBasicSignWitness(key)
+	return tx, nil
+}
+
+// Transfer 创建并广播一个由 key 签名的 transfer 调用交易，返回已发送的交易
+// 广播成功只表示交易进入了内存池，合约执行结果需要在交易上链后通过 WaitForConfirmation 确认
+func (t *NEP5Token) Transfer(ctx context.Context, key *neotransaction.KeyPair, to neoutils.HASH160, amount *big.Int) (*neotransaction.NeoTransaction, error) {
+	tx, err := t.BuildTransfer(key, to, amount)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := t.Client.SendRawTransaction(ctx, tx.RawTransactionString())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf(`NEP5Token transfer error: transaction[%s] rejected`, tx.TXID())
+	}
+	return tx, nil
+}
@@ -0,0 +1,162 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+)
+
+// rpxContract Red Pulse Token 的合约哈希
+var rpxContract = mustHASH160("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9")
+
+func TestNEP5TokenScript(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{
+		"invokescript": `{"script":"","state":"HALT","gas_consumed":"0.103","stack":[{"type":"Integer","value":"8"}]}`,
+	})
+	token := NewNEP5Token(NewClient(node.URL), rpxContract)
+	if _, err := token.Decimals(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// PUSH0 PACK PUSHBYTES8 "decimals" APPCALL 小端序的合约哈希，与 neo-gui 调用 decimals 生成的脚本相同
+	if params := node.lastParams("invokescript"); params != `["00c108646563696d616c7367f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec"]` {
+		t.Fatalf("script %v", params)
+	}
+
+	from := mustHASH160("aeca4738bce5c2c575a2e1ba2813699b5d224f0b")
+	to := mustHASH160("787fd277cda3ec78e19c5da8c2b6ae2ef5f7a74f")
+	tx, err := token.CreateTransfer(from, to, big.NewInt(100000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// transfer 的参数 from、to 是小端序的脚本哈希，最后调用合约
+	script := hex.EncodeToString(tx.ExtraData.(*neotransaction.InvocationExtraData).Script)
+	want := "0400e1f505" + "14" + hex.EncodeToString(to) + "14" + hex.EncodeToString(from) +
+		"53c1087472616e7366657267f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec"
+	if !strings.HasSuffix(script, want) {
+		t.Fatalf("transfer script %v\nwant suffix %v", script, want)
+	}
+}
+
+func TestNEP5TokenDecode(t *testing.T) {
+	owner := mustHASH160("aeca4738bce5c2c575a2e1ba2813699b5d224f0b")
+	node := newSyntheticNode(t, map[string]string{})
+	// 按脚本中调用的方法名返回合约的返回值，neo-cli 的 invokescript 应答中合约返回的整数常常是 ByteArray
+	node.handle("invokescript", func(params []json.RawMessage) string {
+		script := string(params[0])
+		switch {
+		case strings.Contains(script, hex.EncodeToString([]byte("balanceOf"))):
+			return `{"script":"","state":"HALT, BREAK","gas_consumed":"0.338","stack":[{"type":"ByteArray","value":"00e057eb481b"}]}`
+		case strings.Contains(script, hex.EncodeToString([]byte("decimals"))):
+			return `{"script":"","state":"HALT, BREAK","gas_consumed":"0.103","stack":[{"type":"ByteArray","value":"08"}]}`
+		case strings.Contains(script, hex.EncodeToString([]byte("symbol"))):
+			return `{"script":"","state":"HALT, BREAK","gas_consumed":"0.103","stack":[{"type":"ByteArray","value":"525058"}]}`
+		}
+		return `{"script":"","state":"FAULT, BREAK","gas_consumed":"0.1","stack":[]}`
+	})
+	token := NewNEP5Token(NewClient(node.URL), rpxContract)
+	ctx := context.Background()
+
+	balance, err := token.BalanceOf(ctx, owner)
+	if err != nil || balance.String() != "30000000000000" {
+		t.Fatalf("BalanceOf %v %v", balance, err)
+	}
+	// 参数是小端序的脚本哈希
+	want := `["14` + hex.EncodeToString(owner) + `51c10962616c616e63654f6667f91d6b7085db7c5aaf09f19eeec1ca3c0db2c6ec"]`
+	if params := node.lastParams("invokescript"); params != want {
+		t.Fatalf("balanceOf script %v\nwant %v", params, want)
+	}
+	for i := 0; i < 2; i++ {
+		if decimals, err := token.Decimals(ctx); err != nil || decimals != 8 {
+			t.Fatalf("Decimals %v %v", decimals, err)
+		}
+	}
+	if calls := node.count("invokescript"); calls != 2 {
+		t.Fatalf("decimals not cached, invokescript called %v times", calls)
+	}
+	if amount, err := token.FormatAmount(ctx, balance); err != nil || amount != "300000" {
+		t.Fatalf("FormatAmount %v %v", amount, err)
+	}
+	if symbol, err := token.Symbol(ctx); err != nil || symbol != "RPX" {
+		t.Fatalf("Symbol %v %v", symbol, err)
+	}
+	// 合约执行失败时返回错误
+	if name, err := token.Name(ctx); err == nil || !strings.Contains(err.Error(), "FAULT") {
+		t.Fatalf("Name %q %v", name, err)
+	}
+}
+
+func TestNEP5TokenDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		call   func(ctx context.Context, token *NEP5Token) error
+	}{
+		{
+			name:   "BalanceOfFault",
+			result: `{"script":"","state":"FAULT, BREAK","gas_consumed":"0.1","stack":[]}`,
+			call: func(ctx context.Context, token *NEP5Token) error {
+				_, err := token.BalanceOf(ctx, mustHASH160("aeca4738bce5c2c575a2e1ba2813699b5d224f0b"))
+				return err
+			},
+		},
+		{
+			name:   "DecimalsFault",
+			result: `{"script":"","state":"FAULT, BREAK","gas_consumed":"0.1","stack":[{"type":"Integer","value":"8"}]}`,
+			call: func(ctx context.Context, token *NEP5Token) error {
+				_, err := token.Decimals(ctx)
+				return err
+			},
+		},
+		{
+			name:   "DecimalsOutOfRange",
+			result: `{"script":"","state":"HALT, BREAK","gas_consumed":"0.103","stack":[{"type":"ByteArray","value":"0001"}]}`,
+			call: func(ctx context.Context, token *NEP5Token) error {
+				_, err := token.Decimals(ctx)
+				return err
+			},
+		},
+		{
+			name:   "DecimalsNegative",
+			result: `{"script":"","state":"HALT, BREAK","gas_consumed":"0.103","stack":[{"type":"Integer","value":"-1"}]}`,
+			call: func(ctx context.Context, token *NEP5Token) error {
+				_, err := token.Decimals(ctx)
+				return err
+			},
+		},
+		{
+			name:   "SymbolNotBytes",
+			result: `{"script":"","state":"HALT, BREAK","gas_consumed":"0.103","stack":[{"type":"Array","value":[]}]}`,
+			call: func(ctx context.Context, token *NEP5Token) error {
+				_, err := token.Symbol(ctx)
+				return err
+			},
+		},
+		{
+			name:   "SymbolEmptyStack",
+			result: `{"script":"","state":"HALT, BREAK","gas_consumed":"0.103","stack":[]}`,
+			call: func(ctx context.Context, token *NEP5Token) error {
+				_, err := token.Symbol(ctx)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newSyntheticNode(t, map[string]string{"invokescript": tt.result})
+			token := NewNEP5Token(NewClient(node.URL), rpxContract)
+			err := tt.call(context.Background(), token)
+			if err == nil || !strings.Contains(err.Error(), "NEP5Token[ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9]") {
+				t.Fatalf("expected an error, got %v", err)
+			}
+			// 失败的结果不会被缓存
+			if token.decimals != nil {
+				t.Fatal("decimals cached after an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+///////////////////////////////////////////////////////////////////////////
+/// getnep5balances and getnep5transfers are provided by the RpcNep5Tracker
+/// plugin of neo-cli 2.10
+///////////////////////////////////////////////////////////////////////////
+
+// NEP5Balance 账户持有的一种NEP-5资产，金额为合约的最小单位
+type NEP5Balance struct {
+	Contract         neoutils.HASH160
+	Amount           *big.Int
+	LastUpdatedBlock uint32
+}
+
+// NEP5TransferRecord 账户的一笔NEP-5转账记录，金额为合约的最小单位
+type NEP5TransferRecord struct {
+	Timestamp           uint32 // 区块时间
+	Contract            neoutils.HASH160
+	TransferAddress     string // 对方地址，增发或销毁时为空
+	Amount              *big.Int
+	BlockIndex          uint32
+	TransferNotifyIndex uint32 // 通知在交易中的序号
+	TxID                neoutils.HASH256
+}
+
+// NEP5TransferHistory 账户转出和转入的NEP-5转账记录
+type NEP5TransferHistory struct {
+	Address  string
+	Sent     []*NEP5TransferRecord
+	Received []*NEP5TransferRecord
+}
+
+// parseBigInt 解析十进制整数字符串
+func parseBigInt(s string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf(`invalid integer %q`, s)
+	}
+	return v, nil
+}
+
+// GetNEP5Balances 获取账户持有的所有NEP-5资产余额
+func GetNEP5Balances(url string, address string) ([]*NEP5Balance, error) {
+	return NewClient(url).GetNEP5Balances(context.Background(), address)
+}
+
+// GetNEP5Balances 获取账户持有的所有NEP-5资产余额
+func (c *Client) GetNEP5Balances(ctx context.Context, address string) ([]*NEP5Balance, error) {
+	result := &struct {
+		Address string `json:"address"`
+		Balance []struct {
+			AssetHash        string      `json:"asset_hash"`
+			Amount           json.Number `json:"amount"`
+			LastUpdatedBlock uint32      `json:"last_updated_block"`
+		} `json:"balance"`
+	}{}
+	if err := c.callResult(ctx, result, `getnep5balances`, address); err != nil {
+		return nil, fmt.Errorf(`GetNEP5Balances for address[%v] failed: %w`, address, err)
+	}
+
+	balances := make([]*NEP5Balance, 0, len(result.Balance))
+	for _, item := range result.Balance {
+		balance := &NEP5Balance{LastUpdatedBlock: item.LastUpdatedBlock}
+		var err error
+		if balance.Contract, err = neoutils.ParseHASH160(item.AssetHash); err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetNEP5Balances asset_hash %v`, err)}
+		}
+		if balance.Amount, err = parseBigInt(item.Amount.String()); err != nil {
+			return nil, &DecodeError{Err: fmt.Errorf(`GetNEP5Balances amount %v`, err)}
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// GetNEP5TransferHistory 获取账户在 [startTime, endTime] 期间的NEP-5转账记录
+func GetNEP5TransferHistory(url string, address string, startTime uint32, endTime uint32) (*NEP5TransferHistory, error) {
+	return NewClient(url).GetNEP5TransferHistory(context.Background(), address, startTime, endTime)
+}
+
+// GetNEP5TransferHistory 通过 getnep5transfers 获取账户在 [startTime, endTime] 期间的NEP-5转账记录
+// 时间为区块时间（秒），startTime 为0时使用节点的默认值（最近7天），endTime 为0时到当前时间
+func (c *Client) GetNEP5TransferHistory(ctx context.Context, address string, startTime uint32, endTime uint32) (*NEP5TransferHistory, error) {
+	type recordJSON struct {
+		Timestamp           uint32      `json:"timestamp"`
+		AssetHash           string      `json:"asset_hash"`
+		TransferAddress     *string     `json:"transfer_address"`
+		Amount              json.Number `json:"amount"`
+		BlockIndex          uint32      `json:"block_index"`
+		TransferNotifyIndex uint32      `json:"transfer_notify_index"`
+		TxHash              string      `json:"tx_hash"`
+	}
+	result := &struct {
+		Address  string       `json:"address"`
+		Sent     []recordJSON `json:"sent"`
+		Received []recordJSON `json:"received"`
+	}{}
+
+	params := []interface{}{address}
+	if startTime > 0 || endTime > 0 {
+		params = append(params, startTime)
+	}
+	if endTime > 0 {
+		params = append(params, endTime)
+	}
+	if err := c.callResult(ctx, result, `getnep5transfers`, params...); err != nil {
+		return nil, fmt.Errorf(`GetNEP5TransferHistory for address[%v] failed: %w`, address, err)
+	}
+
+	parse := func(items []recordJSON) ([]*NEP5TransferRecord, error) {
+		records := make([]*NEP5TransferRecord, 0, len(items))
+		for _, item := range items {
+			record := &NEP5TransferRecord{
+				Timestamp:           item.Timestamp,
+				BlockIndex:          item.BlockIndex,
+				TransferNotifyIndex: item.TransferNotifyIndex,
+			}
+			// 增发和销毁时对方地址为 null，见 RpcNep5Tracker 插件 2.10 的 AddTransfers：
+			// value.UserScriptHash == UInt160.Zero ? null : value.UserScriptHash.ToAddress()
+			if item.TransferAddress != nil {
+				record.TransferAddress = *item.TransferAddress
+			}
+			var err error
+			if record.Contract, err = neoutils.ParseHASH160(item.AssetHash); err != nil {
+				return nil, fmt.Errorf(`asset_hash %v`, err)
+			}
+			if record.Amount, err = parseBigInt(item.Amount.String()); err != nil {
+				return nil, fmt.Errorf(`amount %v`, err)
+			}
+			if record.TxID, err = neoutils.ParseHASH256(item.TxHash); err != nil {
+				return nil, fmt.Errorf(`tx_hash %v`, err)
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+
+	history := &NEP5TransferHistory{Address: result.Address}
+	var err error
+	if history.Sent, err = parse(result.Sent); err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetNEP5TransferHistory sent %v`, err)}
+	}
+	if history.Received, err = parse(result.Received); err != nil {
+		return nil, &DecodeError{Err: fmt.Errorf(`GetNEP5TransferHistory received %v`, err)}
+	}
+	return history, nil
+}
@@ -0,0 +1,87 @@
+package neocliapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+// nep5TransfersResult getnep5transfers 的合成应答，包含一笔增发（对方地址为 null）和超过 int64 的金额
+const nep5TransfersResult = `{"sent":[{"timestamp":1554283931,"asset_hash":"1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3",` +
+	`"transfer_address":"AYwgBNMepiv5ocGcyNT4mA8zPLTQ8pDBis","amount":"100000000000","block_index":368082,"transfer_notify_index":0,` +
+	`"tx_hash":"0x240ab1369712ad2782b99a02a8f9fcaa41d1e96322017ae90d0449a3ba52a564"}],` +
+	`"received":[{"timestamp":1555651816,"asset_hash":"600c4f5200db36177e3e8a09e9f18e2fc7d12a0f",` +
+	`"transfer_address":null,"amount":"1000000000000000000000","block_index":436036,"transfer_notify_index":0,` +
+	`"tx_hash":"0xdf7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58"}],` +
+	`"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}`
+
+func TestNEP5TrackerRPC(t *testing.T) {
+	address := "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"
+	supply, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	history := &NEP5TransferHistory{
+		Address: address,
+		Sent: []*NEP5TransferRecord{{
+			Timestamp:       1554283931,
+			Contract:        mustHASH160("1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3"),
+			TransferAddress: "AYwgBNMepiv5ocGcyNT4mA8zPLTQ8pDBis",
+			Amount:          big.NewInt(100000000000),
+			BlockIndex:      368082,
+			TxID:            mustHASH256("240ab1369712ad2782b99a02a8f9fcaa41d1e96322017ae90d0449a3ba52a564"),
+		}},
+		Received: []*NEP5TransferRecord{{
+			Timestamp:  1555651816,
+			Contract:   mustHASH160("600c4f5200db36177e3e8a09e9f18e2fc7d12a0f"),
+			Amount:     supply,
+			BlockIndex: 436036,
+			TxID:       mustHASH256("df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58"),
+		}},
+	}
+
+	runRPCCases(t, []rpcCase{
+		{
+			name:   "GetNEP5Balances",
+			method: "getnep5balances",
+			result: `{"balance":[{"asset_hash":"a48b6e1291ba24211ad11bb90ae2a10bf1fcd5a8","amount":"50000000000","last_updated_block":251604},` +
+				`{"asset_hash":"1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3","amount":"1000000000000000000000","last_updated_block":251600}],` +
+				`"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"}`,
+			params: `["AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetNEP5Balances(ctx, address)
+			},
+			want: []*NEP5Balance{
+				{Contract: mustHASH160("a48b6e1291ba24211ad11bb90ae2a10bf1fcd5a8"), Amount: big.NewInt(50000000000), LastUpdatedBlock: 251604},
+				{Contract: mustHASH160("1aada0032aba1ef6d1f07bbd8bec1d85f5380fb3"), Amount: supply, LastUpdatedBlock: 251600},
+			},
+		},
+		{
+			name:   "GetNEP5TransferHistory",
+			method: "getnep5transfers",
+			result: nep5TransfersResult,
+			params: `["AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetNEP5TransferHistory(ctx, address, 0, 0)
+			},
+			want: history,
+		},
+		{
+			name:   "GetNEP5TransferHistorySince",
+			method: "getnep5transfers",
+			result: nep5TransfersResult,
+			params: `["AGofsxAUDwt52KjaB664GYsqVAkULYvKNt",1554000000]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetNEP5TransferHistory(ctx, address, 1554000000, 0)
+			},
+			want: history,
+		},
+		{
+			name:   "GetNEP5TransferHistoryRange",
+			method: "getnep5transfers",
+			result: nep5TransfersResult,
+			params: `["AGofsxAUDwt52KjaB664GYsqVAkULYvKNt",0,1556000000]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetNEP5TransferHistory(ctx, address, 0, 1556000000)
+			},
+			want: history,
+		},
+	})
+}
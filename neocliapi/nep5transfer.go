@@ -0,0 +1,94 @@
+package neocliapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// NEP5Transfer 交易执行过程中发出的一个NEP-5 transfer通知，金额为合约的最小单位
+type NEP5Transfer struct {
+	Contract neoutils.HASH160
+	From     neoutils.HASH160 // 增发时为空
+	To       neoutils.HASH160 // 销毁时为空
+	Amount   *big.Int
+	Index    int // 通知在交易所有通知中的序号
+}
+
+// ParseNEP5Transfer 解析 ["transfer", from, to, amount] 格式的通知，不是NEP-5 transfer时返回false
+// from、to 可以是空字节数组（增发、销毁），amount 可以是 ByteArray 或 Integer
+func ParseNEP5Transfer(n *Notification) (*NEP5Transfer, bool) {
+	state := n.State.Items
+	if n.State.Type != `Array` || len(state) != 4 {
+		return nil, false
+	}
+	name, err := state[0].ToString()
+	if err != nil || name != `transfer` {
+		return nil, false
+	}
+	from, err := state[1].ToBytes()
+	if err != nil || (len(from) != 0 && len(from) != 20) {
+		return nil, false
+	}
+	to, err := state[2].ToBytes()
+	if err != nil || (len(to) != 0 && len(to) != 20) {
+		return nil, false
+	}
+	amount, err := state[3].ToBigInt()
+	if err != nil {
+		return nil, false
+	}
+	return &NEP5Transfer{
+		Contract: n.Contract,
+		From:     from,
+		To:       to,
+		Amount:   amount,
+	}, true
+}
+
+// NEP5Transfers 返回执行成功的执行中发出的所有NEP-5 transfer通知，Index 为通知在所有执行的通知中的序号
+func (log *ApplicationLog) NEP5Transfers() []*NEP5Transfer {
+	transfers := make([]*NEP5Transfer, 0)
+	index := 0
+	for _, execution := range log.Executions {
+		if execution.Faulted() {
+			index += len(execution.Notifications)
+			continue
+		}
+		for _, n := range execution.Notifications {
+			if transfer, ok := ParseNEP5Transfer(n); ok {
+				transfer.Index = index
+				transfers = append(transfers, transfer)
+			}
+			index++
+		}
+	}
+	return transfers
+}
+
+// GetNEP5Transfers 获取一个交易执行成功后发出的所有NEP-5 transfer通知，需要节点安装 ApplicationLogs 插件
+func GetNEP5Transfers(url string, txid string) ([]*NEP5Transfer, error) {
+	return NewClient(url).GetNEP5Transfers(context.Background(), txid)
+}
+
+// GetNEP5Transfers 获取一个交易执行成功后发出的所有NEP-5 transfer通知，执行失败(FAULT)的交易返回空数组
+func (c *Client) GetNEP5Transfers(ctx context.Context, txid string) ([]*NEP5Transfer, error) {
+	log, err := c.GetApplicationLog(ctx, txid)
+	if err != nil {
+		return nil, fmt.Errorf(`GetNEP5Transfers[%s] error: %w`, txid, err)
+	}
+	return log.NEP5Transfers(), nil
+}
+
+// GetNEP5Transfers 从最优节点获取交易的NEP-5 transfer通知
+func (p *EndpointPool) GetNEP5Transfers(ctx context.Context, txid string) ([]*NEP5Transfer, error) {
+	var transfers []*NEP5Transfer
+	err := p.Do(ctx, func(c *Client) error {
+		var err error
+		transfers, err = c.GetNEP5Transfers(ctx, txid)
+		return err
+	})
+	return transfers, err
+}
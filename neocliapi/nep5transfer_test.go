@@ -0,0 +1,93 @@
+package neocliapi
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// transferState 返回 transfer 通知的内容
+func transferState(items ...StackItem) StackItem {
+	return StackItem{Type: "Array", Items: items}
+}
+
+func TestParseNEP5Transfer(t *testing.T) {
+	name := StackItem{Type: "ByteArray", Value: hex.EncodeToString([]byte("transfer"))}
+	from := StackItem{Type: "ByteArray", Value: hex.EncodeToString(depositAddrA)}
+	to := StackItem{Type: "ByteArray", Value: hex.EncodeToString(depositAddrB)}
+	null := StackItem{Type: "ByteArray", Value: ""}
+	amount := StackItem{Type: "ByteArray", Value: "00e1f505"}
+	tests := []struct {
+		name  string
+		state StackItem
+		want  string // From To Amount，为空时不是 transfer
+	}{
+		{"Transfer", transferState(name, from, to, amount), "aeca4738bce5c2c575a2e1ba2813699b5d224f0b 787fd27778fd8eb197fc47d1db9b2fa067f9ec79 100000000"},
+		{"IntegerAmount", transferState(name, from, to, StackItem{Type: "Integer", Value: "5"}), "aeca4738bce5c2c575a2e1ba2813699b5d224f0b 787fd27778fd8eb197fc47d1db9b2fa067f9ec79 5"},
+		{"StringName", transferState(StackItem{Type: "String", Value: "transfer"}, from, to, amount), "aeca4738bce5c2c575a2e1ba2813699b5d224f0b 787fd27778fd8eb197fc47d1db9b2fa067f9ec79 100000000"},
+		// 增发时 from 为 null，销毁时 to 为 null，通知中是空字节数组
+		{"Mint", transferState(name, null, to, amount), " 787fd27778fd8eb197fc47d1db9b2fa067f9ec79 100000000"},
+		{"Burn", transferState(name, from, null, amount), "aeca4738bce5c2c575a2e1ba2813699b5d224f0b  100000000"},
+		{"TooFewArguments", transferState(name, from, to), ""},
+		{"TooManyArguments", transferState(name, from, to, amount, amount), ""},
+		{"NotArray", StackItem{Type: "Struct", Items: []StackItem{name, from, to, amount}}, ""},
+		{"OtherEvent", transferState(StackItem{Type: "ByteArray", Value: hex.EncodeToString([]byte("approve"))}, from, to, amount), ""},
+		{"Refund", transferState(StackItem{Type: "ByteArray", Value: hex.EncodeToString([]byte("refund"))}, from, amount), ""},
+		{"ShortAddress", transferState(name, StackItem{Type: "ByteArray", Value: "0b4f"}, to, amount), ""},
+		{"AddressNotBytes", transferState(name, from, StackItem{Type: "Array"}, amount), ""},
+		{"AmountNotInteger", transferState(name, from, to, StackItem{Type: "Array"}), ""},
+	}
+	for _, tt := range tests {
+		transfer, ok := ParseNEP5Transfer(&Notification{Contract: rpxContract, State: tt.state})
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%v parsed as transfer: %+v", tt.name, transfer)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%v not parsed", tt.name)
+			continue
+		}
+		got := fmt.Sprintf("%v %v %v", transfer.From.ToHexString(), transfer.To.ToHexString(), transfer.Amount)
+		if got != tt.want || transfer.Contract.ToHexString() != "ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9" {
+			t.Errorf("%v: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetNEP5Transfers(t *testing.T) {
+	from := hex.EncodeToString(depositAddrA)
+	to := hex.EncodeToString(depositAddrB)
+	amount := `{"type":"Integer","value":"7"}`
+	// 第一次执行失败，其中的通知不生效但计入序号；第二次执行中有一个其他事件
+	node := newSyntheticNode(t, map[string]string{
+		"getapplicationlog": `{"txid":"0xdf7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58","executions":[` +
+			`{"trigger":"Verification","vmstate":"FAULT","gas_consumed":"0","stack":[],"notifications":[` +
+			transferNotification("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9", from, to, amount) + `]},` +
+			`{"trigger":"Application","vmstate":"HALT","gas_consumed":"1","stack":[],"notifications":[` +
+			transferNotification("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9", "", to, amount) + `,` +
+			`{"contract":"0xecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9","state":{"type":"Array","value":[` +
+			`{"type":"ByteArray","value":"617070726f7665"},{"type":"ByteArray","value":"` + from + `"},{"type":"ByteArray","value":"` + to + `"},` + amount + `]}},` +
+			transferNotification("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9", from, "", amount) + `]}]}`,
+	})
+	transfers, err := GetNEP5Transfers(node.URL, "df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("transfers %+v", transfers)
+	}
+	if mint := transfers[0]; mint.Index != 1 || len(mint.From) != 0 || mint.To.ToHexString() != "787fd27778fd8eb197fc47d1db9b2fa067f9ec79" || mint.Amount.Int64() != 7 {
+		t.Fatalf("mint %+v", mint)
+	}
+	if burn := transfers[1]; burn.Index != 3 || len(burn.To) != 0 || burn.From.ToHexString() != "aeca4738bce5c2c575a2e1ba2813699b5d224f0b" {
+		t.Fatalf("burn %+v", burn)
+	}
+
+	node.set("getapplicationlog", `error:{"code":-100,"message":"Unknown transaction"}`)
+	if _, err = NewClient(node.URL).GetNEP5Transfers(context.Background(), "00"); !IsRPCError(err, ErrCodeUnknownItem) {
+		t.Fatalf("expected the node error, got %v", err)
+	}
+}
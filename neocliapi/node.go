@@ -0,0 +1,106 @@
+package neocliapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// Peer 节点连接的一个P2P节点
+type Peer struct {
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+// Peers 节点已连接、未连接和无法连接的P2P节点
+type Peers struct {
+	Unconnected []Peer `json:"unconnected"`
+	Bad         []Peer `json:"bad"`
+	Connected   []Peer `json:"connected"`
+}
+
+// NodeVersion 节点的版本信息
+type NodeVersion struct {
+	Port      int    `json:"port"`
+	Nonce     uint32 `json:"nonce"`
+	UserAgent string `json:"useragent"` // 如 /NEO:2.10.2/
+}
+
+// Plugin 节点安装的插件
+type Plugin struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version"`
+	Interfaces []string `json:"interfaces"`
+}
+
+// GetConnectionCount 获取节点当前的P2P连接数
+func GetConnectionCount(url string) (int, error) {
+	return NewClient(url).GetConnectionCount(context.Background())
+}
+
+// GetConnectionCount 获取节点当前的P2P连接数
+func (c *Client) GetConnectionCount(ctx context.Context) (int, error) {
+	count := 0
+	if err := c.callResult(ctx, &count, `getconnectioncount`); err != nil {
+		return 0, fmt.Errorf(`GetConnectionCount error: %w`, err)
+	}
+	return count, nil
+}
+
+// GetPeers 获取节点的P2P节点列表
+func GetPeers(url string) (*Peers, error) {
+	return NewClient(url).GetPeers(context.Background())
+}
+
+// GetPeers 获取节点的P2P节点列表
+func (c *Client) GetPeers(ctx context.Context) (*Peers, error) {
+	peers := &Peers{}
+	if err := c.callResult(ctx, peers, `getpeers`); err != nil {
+		return nil, fmt.Errorf(`GetPeers error: %w`, err)
+	}
+	return peers, nil
+}
+
+// GetVersion 获取节点的版本信息
+func GetVersion(url string) (*NodeVersion, error) {
+	return NewClient(url).GetVersion(context.Background())
+}
+
+// GetVersion 获取节点的版本信息
+func (c *Client) GetVersion(ctx context.Context) (*NodeVersion, error) {
+	version := &NodeVersion{}
+	if err := c.callResult(ctx, version, `getversion`); err != nil {
+		return nil, fmt.Errorf(`GetVersion error: %w`, err)
+	}
+	return version, nil
+}
+
+// ValidateAddress 由节点验证地址字符串是否有效
+func ValidateAddress(url string, address string) (bool, error) {
+	return NewClient(url).ValidateAddress(context.Background(), address)
+}
+
+// ValidateAddress 由节点验证地址字符串是否有效，本地验证可以使用 neotransaction.ParseAddress
+func (c *Client) ValidateAddress(ctx context.Context, address string) (bool, error) {
+	result := &struct {
+		Address string `json:"address"`
+		IsValid bool   `json:"isvalid"`
+	}{}
+	if err := c.callResult(ctx, result, `validateaddress`, address); err != nil {
+		return false, fmt.Errorf(`ValidateAddress[%s] error: %w`, address, err)
+	}
+	return result.IsValid, nil
+}
+
+// ListPlugins 获取节点安装的插件
+func ListPlugins(url string) ([]*Plugin, error) {
+	return NewClient(url).ListPlugins(context.Background())
+}
+
+// ListPlugins 获取节点安装的插件，可以用来判断节点是否支持 getapplicationlog、getnep5balances 等插件提供的接口
+func (c *Client) ListPlugins(ctx context.Context) ([]*Plugin, error) {
+	plugins := make([]*Plugin, 0)
+	if err := c.callResult(ctx, &plugins, `listplugins`); err != nil {
+		return nil, fmt.Errorf(`ListPlugins error: %w`, err)
+	}
+	return plugins, nil
+}
@@ -0,0 +1,75 @@
+package neocliapi
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNodeRPC(t *testing.T) {
+	runRPCCases(t, []rpcCase{
+		{
+			name:   "GetConnectionCount",
+			method: "getconnectioncount",
+			result: `10`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetConnectionCount(ctx)
+			},
+			want: 10,
+		},
+		{
+			name:   "GetPeers",
+			method: "getpeers",
+			result: `{"unconnected":[{"address":"::ffff:70.73.16.236","port":10333}],"bad":[],` +
+				`"connected":[{"address":"::ffff:139.219.106.33","port":10333},{"address":"::ffff:47.88.53.224","port":10333}]}`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetPeers(ctx)
+			},
+			want: &Peers{
+				Unconnected: []Peer{{Address: "::ffff:70.73.16.236", Port: 10333}},
+				Bad:         []Peer{},
+				Connected:   []Peer{{Address: "::ffff:139.219.106.33", Port: 10333}, {Address: "::ffff:47.88.53.224", Port: 10333}},
+			},
+		},
+		{
+			name:   "GetVersion",
+			method: "getversion",
+			result: `{"port":10333,"nonce":1737322314,"useragent":"/NEO:2.10.2/"}`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetVersion(ctx)
+			},
+			want: &NodeVersion{Port: 10333, Nonce: 1737322314, UserAgent: "/NEO:2.10.2/"},
+		},
+		{
+			name:   "ValidateAddress",
+			method: "validateaddress",
+			result: `{"address":"AGofsxAUDwt52KjaB664GYsqVAkULYvKNt","isvalid":true}`,
+			params: `["AGofsxAUDwt52KjaB664GYsqVAkULYvKNt"]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ValidateAddress(ctx, "AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+			},
+			want: true,
+		},
+		{
+			name:   "ValidateAddressInvalid",
+			method: "validateaddress",
+			result: `{"address":"152f1muMCNa7goXYhYAQC61hxEgGacmncB","isvalid":false}`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ValidateAddress(ctx, "152f1muMCNa7goXYhYAQC61hxEgGacmncB")
+			},
+			want: false,
+		},
+		{
+			name:   "ListPlugins",
+			method: "listplugins",
+			result: `[{"name":"ApplicationLogs","version":"2.10.2.0","interfaces":["IRpcPlugin","IPersistencePlugin"]},` +
+				`{"name":"RpcNep5Tracker","version":"2.10.2.0","interfaces":["IPersistencePlugin","IRpcPlugin"]}]`,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.ListPlugins(ctx)
+			},
+			want: []*Plugin{
+				{Name: "ApplicationLogs", Version: "2.10.2.0", Interfaces: []string{"IRpcPlugin", "IPersistencePlugin"}},
+				{Name: "RpcNep5Tracker", Version: "2.10.2.0", Interfaces: []string{"IPersistencePlugin", "IRpcPlugin"}},
+			},
+		},
+	})
+}
@@ -0,0 +1,334 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoEndpoint 节点池中没有可用的节点
+var ErrNoEndpoint = errors.New("no available endpoint")
+
+// 节点池的默认参数
+const (
+	DefaultMaxLag         = 2
+	DefaultFailThreshold  = 3
+	DefaultRetryInterval  = 30 * time.Second
+	DefaultBroadcastCount = 3
+)
+
+// EndpointStatus 节点池中一个节点的状态
+type EndpointStatus struct {
+	Endpoint string
+	Height   uint64        // 最近一次获取到的区块高度
+	Latency  time.Duration // 平均请求耗时
+	Failures int           // 连续失败次数
+	Healthy  bool          // 是否可用，连续失败达到 FailThreshold 后被剔除
+}
+
+type poolNode struct {
+	client    *Client
+	status    EndpointStatus
+	ejectedAt time.Time
+}
+
+// EndpointPool 多个neo-cli节点组成的节点池
+// 读请求发送到已同步到最新高度且延迟最低的节点，失败时自动切换到下一个节点；
+// 连续失败的节点被剔除，经过 RetryInterval 后由 Refresh 重新探测，恢复后重新加入
+type EndpointPool struct {
+	MaxLag         uint64        // 节点落后最高区块高度超过此值时视为未同步，不优先使用
+	FailThreshold  int           // 连续失败多少次后剔除节点
+	RetryInterval  time.Duration // 节点被剔除后多久重新探测
+	BroadcastCount int           // 广播交易时同时发送的节点数
+
+	mu    sync.Mutex
+	nodes []*poolNode
+}
+
+// NewEndpointPool 使用一组客户端创建节点池，所有节点初始为可用状态
+func NewEndpointPool(clients ...*Client) *EndpointPool {
+	pool := &EndpointPool{
+		MaxLag:         DefaultMaxLag,
+		FailThreshold:  DefaultFailThreshold,
+		RetryInterval:  DefaultRetryInterval,
+		BroadcastCount: DefaultBroadcastCount,
+	}
+	for _, client := range clients {
+		pool.Add(client)
+	}
+	return pool
+}
+
+// NewEndpointPoolFromURLs 使用一组节点地址和相同的客户端配置创建节点池
+func NewEndpointPoolFromURLs(urls []string, opts ...ClientOption) *EndpointPool {
+	clients := make([]*Client, 0, len(urls))
+	for _, url := range urls {
+		clients = append(clients, NewClient(url, opts...))
+	}
+	return NewEndpointPool(clients...)
+}
+
+// Add 向节点池添加一个节点
+func (p *EndpointPool) Add(client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nodes = append(p.nodes, &poolNode{
+		client: client,
+		status: EndpointStatus{Endpoint: client.Endpoint(), Healthy: true},
+	})
+}
+
+// Status 返回所有节点的当前状态
+func (p *EndpointPool) Status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := make([]EndpointStatus, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		status = append(status, node.status)
+	}
+	return status
+}
+
+// Height 返回节点池中可用节点的最高区块高度
+func (p *EndpointPool) Height() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.maxHeight()
+}
+
+func (p *EndpointPool) maxHeight() uint64 {
+	height := uint64(0)
+	for _, node := range p.nodes {
+		if node.status.Healthy && node.status.Height > height {
+			height = node.status.Height
+		}
+	}
+	return height
+}
+
+// record 记录一次请求的结果，只有 TransportError 和 DecodeError 视为节点故障
+// 节点正常返回的 RPCError 说明节点可用
+func (p *EndpointPool) record(node *poolNode, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil && !errors.As(err, new(*RPCError)) {
+		node.status.Failures++
+		if !node.status.Healthy || node.status.Failures >= p.FailThreshold {
+			// 剔除节点，已剔除的节点则推迟下一次探测
+			node.status.Healthy = false
+			node.ejectedAt = time.Now()
+		}
+		return
+	}
+	if node.status.Latency == 0 {
+		node.status.Latency = latency
+	} else {
+		node.status.Latency = (node.status.Latency*7 + latency) / 8
+	}
+	node.status.Failures = 0
+	node.status.Healthy = true
+}
+
+// candidates 按优先级返回节点：可用且已同步的节点按延迟排序，其次是未同步的节点，最后是已剔除的节点
+func (p *EndpointPool) candidates() []*poolNode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	maxHeight := p.maxHeight()
+	rank := func(node *poolNode) int {
+		switch {
+		case !node.status.Healthy:
+			return 2
+		case node.status.Height+p.MaxLag < maxHeight:
+			return 1
+		}
+		return 0
+	}
+	nodes := make([]*poolNode, len(p.nodes))
+	copy(nodes, p.nodes)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ri, rj := rank(nodes[i]), rank(nodes[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return nodes[i].status.Latency < nodes[j].status.Latency
+	})
+	return nodes
+}
+
+// Client 返回当前最优的节点客户端
+func (p *EndpointPool) Client() (*Client, error) {
+	nodes := p.candidates()
+	if len(nodes) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	return nodes[0].client, nil
+}
+
+// Do 使用最优的节点执行 fn，节点故障（TransportError、DecodeError）时依次切换到下一个节点重试
+// 高度低于最高高度的节点返回 ErrCodeUnknownItem 时，区块或交易可能只是还没有同步到，同样切换到下一个节点；
+// 其它 RPCError 和 context 取消会直接返回，不再重试
+func (p *EndpointPool) Do(ctx context.Context, fn func(c *Client) error) error {
+	nodes := p.candidates()
+	if len(nodes) == 0 {
+		return ErrNoEndpoint
+	}
+	maxHeight := p.Height()
+	var err error
+	for _, node := range nodes {
+		start := time.Now()
+		err = fn(node.client)
+		if ctx.Err() != nil {
+			return err
+		}
+		p.record(node, time.Since(start), err)
+		if err == nil {
+			return nil
+		}
+		if errors.As(err, new(*RPCError)) && (!IsRPCError(err, ErrCodeUnknownItem) || !p.behind(node, maxHeight)) {
+			return err
+		}
+	}
+	return err
+}
+
+// behind 判断节点最近一次获取到的区块高度是否低于 height
+func (p *EndpointPool) behind(node *poolNode, height uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return node.status.Height < height
+}
+
+// Refresh 并发获取所有节点的区块高度，更新高度、延迟和可用状态
+// 已剔除的节点在经过 RetryInterval 之后才会重新探测
+func (p *EndpointPool) Refresh(ctx context.Context) {
+	p.mu.Lock()
+	nodes := make([]*poolNode, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		if node.status.Healthy || time.Since(node.ejectedAt) >= p.RetryInterval {
+			nodes = append(nodes, node)
+		}
+	}
+	p.mu.Unlock()
+
+	wg := sync.WaitGroup{}
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *poolNode) {
+			defer wg.Done()
+			start := time.Now()
+			height, err := node.client.FetchBlockHeight(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			p.record(node, time.Since(start), err)
+			if err == nil {
+				p.mu.Lock()
+				node.status.Height = height
+				p.mu.Unlock()
+			}
+		}(node)
+	}
+	wg.Wait()
+}
+
+// Start 立即刷新一次节点状态，之后每隔 interval 刷新一次，直到 ctx 被取消
+func (p *EndpointPool) Start(ctx context.Context, interval time.Duration) {
+	p.Refresh(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Refresh(ctx)
+			}
+		}
+	}()
+}
+
+// FetchBlockHeight 从最优节点获取区块高度
+func (p *EndpointPool) FetchBlockHeight(ctx context.Context) (uint64, error) {
+	height := uint64(0)
+	err := p.Do(ctx, func(c *Client) error {
+		var err error
+		height, err = c.FetchBlockHeight(ctx)
+		return err
+	})
+	return height, err
+}
+
+// GetBlock 从最优节点获取区块
+func (p *EndpointPool) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	var block *Block
+	err := p.Do(ctx, func(c *Client) error {
+		var err error
+		block, err = c.GetBlock(ctx, height)
+		return err
+	})
+	return block, err
+}
+
+// GetTransaction 从最优节点获取交易
+func (p *EndpointPool) GetTransaction(ctx context.Context, txid string) (*Transaction, error) {
+	var tx *Transaction
+	err := p.Do(ctx, func(c *Client) error {
+		var err error
+		tx, err = c.GetTransaction(ctx, txid)
+		return err
+	})
+	return tx, err
+}
+
+// SendRawTransaction 同时向 BroadcastCount 个最优节点广播交易，第一个节点接受交易后立即返回true，
+// 其余节点的请求在后台继续完成；所有节点都拒绝时优先返回节点的 RPCError
+func (p *EndpointPool) SendRawTransaction(ctx context.Context, rawtx string) (bool, error) {
+	nodes := p.candidates()
+	if len(nodes) == 0 {
+		return false, ErrNoEndpoint
+	}
+	count := p.BroadcastCount
+	if count <= 0 || count > len(nodes) {
+		count = len(nodes)
+	}
+
+	type sendResult struct {
+		ok  bool
+		err error
+	}
+	results := make(chan sendResult, count)
+	for _, node := range nodes[:count] {
+		go func(node *poolNode) {
+			start := time.Now()
+			ok, err := node.client.SendRawTransaction(ctx, rawtx)
+			if ctx.Err() == nil {
+				p.record(node, time.Since(start), err)
+			}
+			results <- sendResult{ok: ok, err: err}
+		}(node)
+	}
+
+	// results 有足够的缓冲，提前返回后其余的goroutine不会阻塞
+	var rpcErr, lastErr error
+	for i := 0; i < count; i++ {
+		r := <-results
+		switch {
+		case r.ok:
+			return true, nil
+		case r.err == nil:
+		case errors.As(r.err, new(*RPCError)):
+			if rpcErr == nil {
+				rpcErr = r.err
+			}
+		default:
+			lastErr = r.err
+		}
+	}
+	if rpcErr != nil {
+		return false, rpcErr
+	}
+	return false, lastErr
+}
@@ -0,0 +1,250 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestPool 创建由 nodes 组成的节点池，请求超时为 timeout
+func newTestPool(timeout time.Duration, nodes ...*syntheticNode) *EndpointPool {
+	clients := make([]*Client, 0, len(nodes))
+	for _, node := range nodes {
+		clients = append(clients, NewClient(node.URL, WithTimeout(timeout)))
+	}
+	return NewEndpointPool(clients...)
+}
+
+// poolStatus 返回节点在节点池中的状态
+func poolStatus(t *testing.T, pool *EndpointPool, node *syntheticNode) EndpointStatus {
+	for _, status := range pool.Status() {
+		if status.Endpoint == node.URL {
+			return status
+		}
+	}
+	t.Fatalf("node %v not in pool", node.URL)
+	return EndpointStatus{}
+}
+
+func TestEndpointPoolRouting(t *testing.T) {
+	fast := newSyntheticNode(t, map[string]string{"getblockcount": `101`})
+	slow := newSyntheticNode(t, map[string]string{"getblockcount": `101`})
+	lagging := newSyntheticNode(t, map[string]string{"getblockcount": `91`})
+	fast.setDelay(10 * time.Millisecond)
+	slow.setDelay(60 * time.Millisecond)
+	pool := newTestPool(time.Second, slow, lagging, fast)
+	ctx := context.Background()
+
+	pool.Refresh(ctx)
+	if pool.Height() != 100 {
+		t.Fatalf("pool height %v, want 100", pool.Height())
+	}
+	// 落后超过 MaxLag 的节点即使延迟最低也不优先使用
+	client, err := pool.Client()
+	if err != nil || client.Endpoint() != fast.URL {
+		t.Fatalf("best endpoint %v, want the synced node with lowest latency %v", client.Endpoint(), fast.URL)
+	}
+	if height, err := pool.FetchBlockHeight(ctx); err != nil || height != 100 {
+		t.Fatalf("height %v err %v", height, err)
+	}
+	if fast.count("getblockcount") != 2 || lagging.count("getblockcount") != 1 || slow.count("getblockcount") != 1 {
+		t.Fatal("request not routed to the best node")
+	}
+
+	// 追上最高高度后，延迟最低的节点成为最优节点
+	lagging.set("getblockcount", `101`)
+	pool.Refresh(ctx)
+	if client, _ = pool.Client(); client.Endpoint() != lagging.URL {
+		t.Fatalf("best endpoint %v, want the caught up node %v", client.Endpoint(), lagging.URL)
+	}
+}
+
+func TestEndpointPoolEjectAndReadmit(t *testing.T) {
+	nodes := []*syntheticNode{
+		newSyntheticNode(t, map[string]string{"getblockcount": `101`}),
+		newSyntheticNode(t, map[string]string{"getblockcount": `101`}),
+		newSyntheticNode(t, map[string]string{"getblockcount": `101`}),
+	}
+	nodes[1].setDelay(20 * time.Millisecond)
+	nodes[2].setDelay(20 * time.Millisecond)
+	pool := newTestPool(200*time.Millisecond, nodes...)
+	pool.RetryInterval = 100 * time.Millisecond
+	ctx := context.Background()
+	pool.Refresh(ctx)
+
+	// 节点超时，连续失败 FailThreshold 次后被剔除，期间请求切换到其它节点
+	nodes[0].setDelay(time.Second)
+	for i := 0; i < pool.FailThreshold; i++ {
+		if height, err := pool.FetchBlockHeight(ctx); err != nil || height != 100 {
+			t.Fatalf("height %v err %v", height, err)
+		}
+	}
+	status := poolStatus(t, pool, nodes[0])
+	if status.Healthy || status.Failures != pool.FailThreshold {
+		t.Fatalf("timed out node not ejected: %+v", status)
+	}
+	if client, _ := pool.Client(); client.Endpoint() == nodes[0].URL {
+		t.Fatal("ejected node is still preferred")
+	}
+
+	// 剔除后 RetryInterval 之内不探测
+	calls := nodes[0].count("getblockcount")
+	pool.Refresh(ctx)
+	if nodes[0].count("getblockcount") != calls {
+		t.Fatal("ejected node probed before RetryInterval")
+	}
+
+	// 节点恢复，经过 RetryInterval 之后重新加入
+	nodes[0].setDelay(0)
+	time.Sleep(pool.RetryInterval)
+	pool.Refresh(ctx)
+	if status = poolStatus(t, pool, nodes[0]); !status.Healthy || status.Failures != 0 || status.Height != 100 {
+		t.Fatalf("recovered node not re-admitted: %+v", status)
+	}
+}
+
+func TestEndpointPoolAllDown(t *testing.T) {
+	nodes := []*syntheticNode{
+		newSyntheticNode(t, map[string]string{"getblockcount": `status:502`}),
+		newSyntheticNode(t, map[string]string{"getblockcount": `status:502`}),
+		newSyntheticNode(t, map[string]string{"getblockcount": `status:502`}),
+	}
+	pool := newTestPool(time.Second, nodes...)
+	_, err := pool.FetchBlockHeight(context.Background())
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("expected TransportError, got %v", err)
+	}
+	for _, node := range nodes {
+		if node.count("getblockcount") != 1 {
+			t.Fatal("not every node tried")
+		}
+	}
+
+	// 节点返回的 RPCError 不切换节点
+	for _, node := range nodes {
+		node.set("getblockcount", `error:{"code":-100,"message":"Unknown"}`)
+	}
+	_, err = pool.FetchBlockHeight(context.Background())
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected RPCError, got %v", err)
+	}
+	if nodes[0].count("getblockcount")+nodes[1].count("getblockcount")+nodes[2].count("getblockcount") != 4 {
+		t.Fatal("RPCError retried on other nodes")
+	}
+}
+
+func TestEndpointPoolSendRawTransaction(t *testing.T) {
+	rejected := `error:{"code":-501,"message":"Block or transaction already exists and cannot be sent repeatedly."}`
+	tests := []struct {
+		name      string
+		responses []string
+		accepted  bool
+		rpcError  bool
+	}{
+		{"OneAccepts", []string{rejected, `status:502`, `true`}, true, false},
+		{"AllFail", []string{`status:502`, rejected, `false`}, false, true},
+		{"AllDown", []string{`status:502`, `status:502`, `status:502`}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := make([]*syntheticNode, 0, len(tt.responses))
+			for _, response := range tt.responses {
+				nodes = append(nodes, newSyntheticNode(t, map[string]string{"sendrawtransaction": response}))
+			}
+			pool := newTestPool(time.Second, nodes...)
+			ok, err := pool.SendRawTransaction(context.Background(), "80000000")
+			if ok != tt.accepted {
+				t.Fatalf("accepted %v err %v", ok, err)
+			}
+			if tt.accepted && err != nil {
+				t.Fatalf("accepted with error %v", err)
+			}
+			var rpcErr *RPCError
+			if !tt.accepted && errors.As(err, &rpcErr) != tt.rpcError {
+				t.Fatalf("error %v, want RPCError %v", err, tt.rpcError)
+			}
+			if !tt.accepted && !tt.rpcError && !errors.As(err, new(*TransportError)) {
+				t.Fatalf("error %v, want TransportError", err)
+			}
+			// 接受后立即返回，其余节点的请求可能还在进行
+			deadline := time.Now().Add(time.Second)
+			for _, node := range nodes {
+				for node.count("sendrawtransaction") != 1 {
+					if time.Now().After(deadline) {
+						t.Fatal("transaction not broadcast to every node")
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}
+		})
+	}
+}
+
+func TestEndpointPoolSendRawTransactionFirstAccept(t *testing.T) {
+	fast := newSyntheticNode(t, map[string]string{"sendrawtransaction": `true`})
+	slow := newSyntheticNode(t, map[string]string{"sendrawtransaction": `true`})
+	slow.setDelay(500 * time.Millisecond)
+	pool := newTestPool(time.Second, slow, fast)
+	start := time.Now()
+	ok, err := pool.SendRawTransaction(context.Background(), "80000000")
+	if !ok || err != nil {
+		t.Fatalf("accepted %v err %v", ok, err)
+	}
+	if elapsed := time.Since(start); elapsed >= 400*time.Millisecond {
+		t.Fatalf("waited %v for the slow node", elapsed)
+	}
+	// 慢节点的请求在后台完成
+	for deadline := time.Now().Add(2 * time.Second); slow.count("sendrawtransaction") != 1; {
+		if time.Now().After(deadline) {
+			t.Fatal("transaction not broadcast to the slow node")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEndpointPoolUnknownItemFailover(t *testing.T) {
+	unknown := `error:{"code":-100,"message":"Unknown transaction"}`
+	synced := newSyntheticNode(t, map[string]string{"getblockcount": `101`, "getrawtransaction": `"80000000"`})
+	lagging := newSyntheticNode(t, map[string]string{"getblockcount": `100`, "getrawtransaction": unknown})
+	synced.setDelay(20 * time.Millisecond)
+	pool := newTestPool(time.Second, synced, lagging)
+	ctx := context.Background()
+	pool.Refresh(ctx)
+	getTx := func(c *Client) error {
+		raw := ""
+		return c.callResult(ctx, &raw, "getrawtransaction", "0x01")
+	}
+
+	// 落后1个区块的节点在 MaxLag 之内且延迟更低，交易还没有同步到时切换到最高的节点
+	if client, _ := pool.Client(); client.Endpoint() != lagging.URL {
+		t.Fatalf("best endpoint %v, want %v", client.Endpoint(), lagging.URL)
+	}
+	if err := pool.Do(ctx, getTx); err != nil {
+		t.Fatal(err)
+	}
+	if lagging.count("getrawtransaction") != 1 || synced.count("getrawtransaction") != 1 {
+		t.Fatal("unknown item not retried on the synced node")
+	}
+
+	// 已经同步到最高高度的节点返回 ErrCodeUnknownItem 时交易确实不存在，不再切换
+	lagging.set("getblockcount", `101`)
+	pool.Refresh(ctx)
+	err := pool.Do(ctx, getTx)
+	if !IsRPCError(err, ErrCodeUnknownItem) {
+		t.Fatalf("expected unknown item error, got %v", err)
+	}
+	if synced.count("getrawtransaction") != 1 {
+		t.Fatal("unknown item from a synced node retried")
+	}
+
+	// 其它 RPCError 也不切换
+	synced.set("getblockcount", `102`)
+	pool.Refresh(ctx)
+	lagging.set("getrawtransaction", `error:{"code":-32603,"message":"Internal error"}`)
+	if err = pool.Do(ctx, getTx); !IsRPCError(err, ErrCodeInternalError) || synced.count("getrawtransaction") != 1 {
+		t.Fatalf("RPCError %v retried on other nodes", err)
+	}
+}
@@ -1,50 +1,22 @@
 package neocliapi
 
 import (
-	"encoding/json"
-	"io/ioutil"
-	"log"
-	"net/http"
-	"strings"
-	"time"
+	"context"
+	"fmt"
 )
 
 // SendRawTransaction 向一个neo-cli节点发送原始交易字符串
-func SendRawTransaction(url string, rawtx string) bool {
-	client := &http.Client{}
-	client.Timeout = 60 * time.Second
-	response, err := client.Post(url, "application/json", strings.NewReader(`{
-		"jsonrpc": "2.0",
-		"method": "sendrawtransaction",
-		"params": ["`+rawtx+`"],
-		"id": 1
-	}`))
-
-	if err != nil {
-		log.Println("Try sendRawTransaction", err)
-		return false
-	}
-	defer response.Body.Close()
-
-	buff, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		log.Println("Try sendRawTransaction", err)
-		return false
-	}
+// 节点拒绝交易时返回 RPCError，错误码为 ErrCodeAlreadyExists、ErrCodeInvalid、ErrCodePolicyFail 等
+func SendRawTransaction(url string, rawtx string) (bool, error) {
+	return NewClient(url).SendRawTransaction(context.Background(), rawtx)
+}
 
-	ret := make(map[string]interface{})
-	err = json.Unmarshal(buff, &ret)
+// SendRawTransaction 向节点发送原始交易字符串，节点接受交易时返回true
+func (c *Client) SendRawTransaction(ctx context.Context, rawtx string) (bool, error) {
+	result := false
+	err := c.callResult(ctx, &result, `sendrawtransaction`, rawtx)
 	if err != nil {
-		log.Println("Try sendRawTransaction", err)
-		return false
+		return false, fmt.Errorf(`SendRawTransaction error: %w`, err)
 	}
-
-	result, ok := ret[`result`].(bool)
-	if !ok {
-		//log.Println("Try sendRawTransaction result not a bool")
-		return false
-	}
-
-	//log.Println("Try sendRawTransaction", string(buff))
-	return result
+	return result, nil
 }
@@ -0,0 +1,95 @@
+package neocliapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSendRawTransaction(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{"sendrawtransaction": `true`})
+	ok, err := SendRawTransaction(node.URL, "80000001")
+	if err != nil || !ok {
+		t.Fatalf("SendRawTransaction %v %v", ok, err)
+	}
+	if params := node.lastParams("sendrawtransaction"); params != `["80000001"]` {
+		t.Fatalf("params %v", params)
+	}
+}
+
+func TestSendRawTransactionRelayErrors(t *testing.T) {
+	// neo-cli 2.x 中 RelayResultReason 对应的错误码和消息
+	relayErrors := []struct {
+		code    int64
+		message string
+	}{
+		{ErrCodeRelayUnknown, "Unknown error."},
+		{ErrCodeAlreadyExists, "Block or transaction already exists and cannot be sent repeatedly."},
+		{ErrCodeOutOfMemory, "The memory pool is full and no more transactions can be sent."},
+		{ErrCodeUnableToVerify, "The block cannot be validated."},
+		{ErrCodeInvalid, "Block or transaction validation failed."},
+		{ErrCodePolicyFail, "One of the Policy filters failed."},
+	}
+	node := newSyntheticNode(t, map[string]string{})
+	client := NewClient(node.URL)
+	for _, relay := range relayErrors {
+		node.set("sendrawtransaction", fmt.Sprintf(`error:{"code":%d,"message":%q}`, relay.code, relay.message))
+		ok, err := client.SendRawTransaction(context.Background(), "80000001")
+		if ok {
+			t.Fatalf("code %v: transaction accepted", relay.code)
+		}
+		rpcErr := &RPCError{}
+		if !errors.As(err, &rpcErr) || rpcErr.Code != relay.code || rpcErr.Message != relay.message {
+			t.Fatalf("code %v: expected RPCError, got %v", relay.code, err)
+		}
+		if !IsRPCError(err, relay.code) || IsRPCError(err, relay.code-1) {
+			t.Fatalf("code %v: IsRPCError mismatch for %v", relay.code, err)
+		}
+		if !strings.HasPrefix(err.Error(), "SendRawTransaction error: ") {
+			t.Fatalf("error not wrapped: %v", err)
+		}
+	}
+}
+
+func TestSendRawTransactionFailures(t *testing.T) {
+	node := newSyntheticNode(t, map[string]string{})
+	client := NewClient(node.URL)
+
+	node.set("sendrawtransaction", "status:500")
+	_, err := client.SendRawTransaction(context.Background(), "80000001")
+	transportErr := &TransportError{}
+	if !errors.As(err, &transportErr) || transportErr.StatusCode != http.StatusInternalServerError || transportErr.Endpoint != node.URL {
+		t.Fatalf("expected TransportError, got %v", err)
+	}
+	if IsRPCError(err, ErrCodeInternalError) {
+		t.Fatalf("HTTP status reported as RPCError: %v", err)
+	}
+
+	// 应答不是合法的json
+	node.set("sendrawtransaction", `tru`)
+	_, err = client.SendRawTransaction(context.Background(), "80000001")
+	decodeErr := &DecodeError{}
+	if !errors.As(err, &decodeErr) || !strings.Contains(string(decodeErr.Body), `"result":tru`) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+	if errors.As(err, &transportErr) {
+		t.Fatalf("broken json reported as TransportError: %v", err)
+	}
+
+	// result 不是布尔值
+	node.set("sendrawtransaction", `"true"`)
+	_, err = client.SendRawTransaction(context.Background(), "80000001")
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+
+	// 节点已经关闭
+	node.Close()
+	_, err = client.SendRawTransaction(context.Background(), "80000001")
+	if !errors.As(err, &transportErr) || transportErr.StatusCode != 0 {
+		t.Fatalf("expected TransportError, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package neocliapi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// Argument 调用智能合约的参数和智能合约返回值的参数类型
+type Argument = StackItem
+
+// StackItem 虚拟机栈元素，即合约的返回值和通知的内容
+// Value 为 ByteArray 的十六进制字符串、Integer 的十进制字符串、Boolean 的 true/false 或 String 的原字符串
+// Array、Struct 的元素在 Items 中，Map 的键值对在 Entries 中，InteropInterface 没有值
+type StackItem struct {
+	Type    string
+	Value   string
+	Items   []StackItem
+	Entries []StackMapEntry
+}
+
+// StackMapEntry Map 类型栈元素的一个键值对
+type StackMapEntry struct {
+	Key   StackItem
+	Value StackItem
+}
+
+// UnmarshalJSON 解析节点返回的 {"type": ..., "value": ...} 格式的栈元素
+func (s *StackItem) UnmarshalJSON(data []byte) error {
+	raw := &struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}{}
+	if err := json.Unmarshal(data, raw); err != nil {
+		return err
+	}
+	*s = StackItem{Type: raw.Type}
+	if len(raw.Value) == 0 || string(raw.Value) == `null` {
+		return nil
+	}
+
+	switch raw.Type {
+	case `Array`, `Struct`:
+		return json.Unmarshal(raw.Value, &s.Items)
+	case `Map`:
+		entries := make([]struct {
+			Key   StackItem `json:"key"`
+			Value StackItem `json:"value"`
+		}, 0)
+		if err := json.Unmarshal(raw.Value, &entries); err != nil {
+			return err
+		}
+		s.Entries = make([]StackMapEntry, 0, len(entries))
+		for _, entry := range entries {
+			s.Entries = append(s.Entries, StackMapEntry{Key: entry.Key, Value: entry.Value})
+		}
+		return nil
+	case `Boolean`:
+		b := false
+		if err := json.Unmarshal(raw.Value, &b); err == nil {
+			s.Value = strconv.FormatBool(b)
+			return nil
+		}
+	}
+	// 其它类型的值都是字符串，无法解析的值（如 InteropInterface）忽略
+	if err := json.Unmarshal(raw.Value, &s.Value); err != nil && raw.Type != `InteropInterface` {
+		return fmt.Errorf(`StackItem %s invalid value %s`, raw.Type, raw.Value)
+	}
+	return nil
+}
+
+// ToBytes 将 ByteArray、Integer、Boolean、String 转换为字节数组，Integer 编码为小端序补码
+func (s StackItem) ToBytes() ([]byte, error) {
+	switch s.Type {
+	case `ByteArray`:
+		return hex.DecodeString(s.Value)
+	case `Integer`:
+		v, ok := new(big.Int).SetString(s.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf(`StackItem invalid Integer %q`, s.Value)
+		}
+		return neoutils.BigIntToBytes(v), nil
+	case `Boolean`:
+		if s.Value == `true` {
+			return []byte{1}, nil
+		}
+		return []byte{}, nil
+	case `String`:
+		return []byte(s.Value), nil
+	}
+	return nil, fmt.Errorf(`StackItem %s can not convert to bytes`, s.Type)
+}
+
+// ToBigInt 将栈元素转换为大整数，ByteArray 按小端序补码解码
+func (s StackItem) ToBigInt() (*big.Int, error) {
+	if s.Type == `Integer` {
+		v, ok := new(big.Int).SetString(s.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf(`StackItem invalid Integer %q`, s.Value)
+		}
+		return v, nil
+	}
+	b, err := s.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	return neoutils.BytesToBigInt(b), nil
+}
+
+// ToBool 将栈元素转换为布尔值，ByteArray 和 Integer 不为0时为true
+func (s StackItem) ToBool() (bool, error) {
+	switch s.Type {
+	case `Boolean`:
+		return s.Value == `true`, nil
+	case `Array`, `Struct`, `Map`, `InteropInterface`:
+		return true, nil
+	}
+	b, err := s.ToBytes()
+	if err != nil {
+		return false, err
+	}
+	for _, v := range b {
+		if v != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ToString 将栈元素按UTF-8转换为字符串，如合约返回的名称、符号
+func (s StackItem) ToString() (string, error) {
+	b, err := s.ToBytes()
+	if err != nil {
+		return ``, err
+	}
+	return string(b), nil
+}
+
+// ToHASH160 将20字节的栈元素转换为 HASH160，字节序与 Address.ScripHash 相同
+func (s StackItem) ToHASH160() (neoutils.HASH160, error) {
+	b, err := s.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	hash := neoutils.HASH160(b)
+	if !hash.IsValid() {
+		return nil, fmt.Errorf(`StackItem invalid HASH160 length %d`, len(b))
+	}
+	return hash, nil
+}
+
+// ToAddress 将20字节的栈元素转换为地址
+func (s StackItem) ToAddress() (*neotransaction.Address, error) {
+	hash, err := s.ToHASH160()
+	if err != nil {
+		return nil, err
+	}
+	return neotransaction.ParseAddressHash(hash)
+}
+
+// ToArray 返回 Array 或 Struct 的元素
+func (s StackItem) ToArray() ([]StackItem, error) {
+	if s.Type != `Array` && s.Type != `Struct` {
+		return nil, fmt.Errorf(`StackItem %s is not an array`, s.Type)
+	}
+	return s.Items, nil
+}
+
+// ToMap 将 Map 转换为以键的字节数组为key的map，键不能转换为字节数组时返回错误
+func (s StackItem) ToMap() (map[string]StackItem, error) {
+	if s.Type != `Map` {
+		return nil, fmt.Errorf(`StackItem %s is not a map`, s.Type)
+	}
+	m := make(map[string]StackItem, len(s.Entries))
+	for _, entry := range s.Entries {
+		key, err := entry.Key.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		m[string(key)] = entry.Value
+	}
+	return m, nil
+}
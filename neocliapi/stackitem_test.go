@@ -0,0 +1,217 @@
+package neocliapi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+func TestStackItemUnmarshalNested(t *testing.T) {
+	data := `{"type":"Array","value":[` +
+		`{"type":"Struct","value":[{"type":"Integer","value":"-5"},{"type":"ByteArray","value":"525058"}]},` +
+		`{"type":"Map","value":[` +
+		`{"key":{"type":"ByteArray","value":"6b6579"},"value":{"type":"Array","value":[{"type":"Boolean","value":true}]}},` +
+		`{"key":{"type":"String","value":"name"},"value":{"type":"Map","value":[]}}]},` +
+		`{"type":"Array","value":[]},` +
+		`{"type":"Boolean","value":false},` +
+		`{"type":"InteropInterface"}]}`
+	item := StackItem{}
+	if err := json.Unmarshal([]byte(data), &item); err != nil {
+		t.Fatal(err)
+	}
+	want := StackItem{Type: "Array", Items: []StackItem{
+		{Type: "Struct", Items: []StackItem{{Type: "Integer", Value: "-5"}, {Type: "ByteArray", Value: "525058"}}},
+		{Type: "Map", Entries: []StackMapEntry{
+			{Key: StackItem{Type: "ByteArray", Value: "6b6579"}, Value: StackItem{Type: "Array", Items: []StackItem{{Type: "Boolean", Value: "true"}}}},
+			{Key: StackItem{Type: "String", Value: "name"}, Value: StackItem{Type: "Map", Entries: []StackMapEntry{}}},
+		}},
+		{Type: "Array", Items: []StackItem{}},
+		{Type: "Boolean", Value: "false"},
+		{Type: "InteropInterface"},
+	}}
+	if !reflect.DeepEqual(item, want) {
+		t.Fatalf("got  %+v\nwant %+v", item, want)
+	}
+
+	items, err := item.ToArray()
+	if err != nil || len(items) != 5 {
+		t.Fatalf("ToArray %v %v", items, err)
+	}
+	fields, err := items[0].ToArray()
+	if err != nil || len(fields) != 2 {
+		t.Fatalf("Struct ToArray %v %v", fields, err)
+	}
+	if symbol, err := fields[1].ToString(); err != nil || symbol != "RPX" {
+		t.Fatalf("ToString %q %v", symbol, err)
+	}
+	m, err := items[1].ToMap()
+	if err != nil || len(m) != 2 {
+		t.Fatalf("ToMap %v %v", m, err)
+	}
+	if inner, err := m["key"].ToArray(); err != nil || len(inner) != 1 || inner[0].Value != "true" {
+		t.Fatalf("map value %v %v", inner, err)
+	}
+	if _, ok := m["name"]; !ok {
+		t.Fatal("String key not converted to bytes")
+	}
+	if _, err = items[1].ToArray(); err == nil {
+		t.Fatal("Map converted to array")
+	}
+	if _, err = items[0].ToMap(); err == nil {
+		t.Fatal("Struct converted to map")
+	}
+	// 键为 Array 时无法转换为 map
+	bad := StackItem{Type: "Map", Entries: []StackMapEntry{{Key: StackItem{Type: "Array"}, Value: StackItem{Type: "Integer", Value: "1"}}}}
+	if _, err = bad.ToMap(); err == nil {
+		t.Fatal("Array key accepted")
+	}
+
+	if err = json.Unmarshal([]byte(`{"type":"Integer","value":5}`), &item); err == nil {
+		t.Fatal("Integer value not in a string accepted")
+	}
+}
+
+func TestStackItemToBigInt(t *testing.T) {
+	tests := []struct {
+		item StackItem
+		want string // 为空时期望错误
+	}{
+		// ByteArray 为小端序补码
+		{StackItem{Type: "ByteArray", Value: ""}, "0"},
+		{StackItem{Type: "ByteArray", Value: "01"}, "1"},
+		{StackItem{Type: "ByteArray", Value: "ff"}, "-1"},
+		{StackItem{Type: "ByteArray", Value: "80"}, "-128"},
+		{StackItem{Type: "ByteArray", Value: "ff00"}, "255"},
+		{StackItem{Type: "ByteArray", Value: "0001"}, "256"},
+		{StackItem{Type: "ByteArray", Value: "00ff"}, "-256"},
+		{StackItem{Type: "ByteArray", Value: "00e1f505"}, "100000000"},
+		{StackItem{Type: "ByteArray", Value: "0020f95e44f3"}, "-14000000000000"},
+		{StackItem{Type: "ByteArray", Value: "0g"}, ""},
+		{StackItem{Type: "Integer", Value: "-123456789012345678901234567890"}, "-123456789012345678901234567890"},
+		{StackItem{Type: "Integer", Value: "0x10"}, ""},
+		{StackItem{Type: "Boolean", Value: "true"}, "1"},
+		{StackItem{Type: "Boolean", Value: "false"}, "0"},
+		{StackItem{Type: "String", Value: "\x01"}, "1"},
+		{StackItem{Type: "Array"}, ""},
+		{StackItem{Type: "InteropInterface"}, ""},
+	}
+	for _, tt := range tests {
+		v, err := tt.item.ToBigInt()
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%+v ToBigInt = %v, want error", tt.item, v)
+			}
+			continue
+		}
+		if err != nil || v.String() != tt.want {
+			t.Errorf("%+v ToBigInt = %v, %v; want %v", tt.item, v, err, tt.want)
+		}
+	}
+
+	// Integer 转换为字节数组时同样编码为小端序补码
+	b, err := StackItem{Type: "Integer", Value: "-256"}.ToBytes()
+	if err != nil || hex.EncodeToString(b) != "00ff" {
+		t.Fatalf("Integer ToBytes %x %v", b, err)
+	}
+}
+
+func TestStackItemToBool(t *testing.T) {
+	tests := []struct {
+		item StackItem
+		want bool
+		ok   bool
+	}{
+		{StackItem{Type: "Boolean", Value: "true"}, true, true},
+		{StackItem{Type: "Boolean", Value: "false"}, false, true},
+		// 合约返回的布尔值在 neo-cli 中常常显示为 ByteArray
+		{StackItem{Type: "ByteArray", Value: "01"}, true, true},
+		{StackItem{Type: "ByteArray", Value: ""}, false, true},
+		{StackItem{Type: "ByteArray", Value: "00"}, false, true},
+		{StackItem{Type: "ByteArray", Value: "0000"}, false, true},
+		{StackItem{Type: "ByteArray", Value: "0001"}, true, true},
+		{StackItem{Type: "Integer", Value: "0"}, false, true},
+		{StackItem{Type: "Integer", Value: "-1"}, true, true},
+		{StackItem{Type: "String", Value: ""}, false, true},
+		{StackItem{Type: "Array"}, true, true},
+		{StackItem{Type: "Map"}, true, true},
+		{StackItem{Type: "ByteArray", Value: "zz"}, false, false},
+		{StackItem{Type: "Unknown"}, false, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.item.ToBool()
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%+v ToBool = %v, %v; want %v ok %v", tt.item, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStackItemToAddress(t *testing.T) {
+	owner, _ := neotransaction.ParseAddress("AGofsxAUDwt52KjaB664GYsqVAkULYvKNt")
+	// 通知中的地址是小端序的 ScriptHash
+	item := StackItem{Type: "ByteArray", Value: hex.EncodeToString(owner.ScripHash)}
+	hash, err := item.ToHASH160()
+	if err != nil || hash.ToHexString() != "aeca4738bce5c2c575a2e1ba2813699b5d224f0b" {
+		t.Fatalf("ToHASH160 %v %v", hash, err)
+	}
+	addr, err := item.ToAddress()
+	if err != nil || addr.Addr != owner.Addr {
+		t.Fatalf("ToAddress %v %v", addr, err)
+	}
+	for _, bad := range []StackItem{
+		{Type: "ByteArray", Value: ""},
+		{Type: "ByteArray", Value: "0b4f225d9b691328bae1a275c5c2e5bc3847ca"},
+		{Type: "Array"},
+	} {
+		if _, err = bad.ToAddress(); err == nil {
+			t.Errorf("%+v converted to an address", bad)
+		}
+	}
+}
+
+func TestContractParams(t *testing.T) {
+	hash160 := mustHASH160("ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9")
+	hash256 := mustHASH256(neotransaction.AssetNeoID)
+	tests := []struct {
+		param interface{}
+		want  string // 参数的json，为空时期望错误
+	}{
+		{"transfer", `[{"type":"String","value":"transfer"}]`},
+		{true, `[{"type":"Boolean","value":"true"}]`},
+		{int(-5), `[{"type":"Integer","value":"-5"}]`},
+		{uint(5), `[{"type":"Integer","value":"5"}]`},
+		{int32(-7), `[{"type":"Integer","value":"-7"}]`},
+		{uint64(18446744073709551615), `[{"type":"Integer","value":"18446744073709551615"}]`},
+		{new(big.Int).Lsh(big.NewInt(1), 100), `[{"type":"Integer","value":"1267650600228229401496703205376"}]`},
+		{[]byte{1, 2}, `[{"type":"ByteArray","value":"0102"}]`},
+		{hash160, `[{"type":"Hash160","value":"ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9"}]`},
+		{hash256, `[{"type":"Hash256","value":"` + neotransaction.AssetNeoID + `"}]`},
+		{[]interface{}{1, "a", []int{2}}, `[{"type":"Array","value":[{"type":"Integer","value":"1"},{"type":"String","value":"a"},` +
+			`{"type":"Array","value":[{"type":"Integer","value":"2"}]}]}]`},
+		{[]neoutils.HASH160{hash160}, `[{"type":"Array","value":[{"type":"Hash160","value":"ecc6b20d3ccac1ee9ef109af5a7cdb85706b1df9"}]}]`},
+		{nil, `[]`},
+		{1.5, ""},
+		{[]interface{}{1.5}, ""},
+	}
+	for _, tt := range tests {
+		params, err := contractParams([]interface{}{tt.param})
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("contractParams(%T) accepted", tt.param)
+			}
+			continue
+		}
+		data, _ := json.Marshal(params)
+		if err != nil || string(data) != tt.want {
+			t.Errorf("contractParams(%v) = %s, %v\nwant %s", tt.param, data, err, tt.want)
+		}
+	}
+	if _, err := contractParams([]interface{}{fmt.Stringer(nil)}); err != nil {
+		t.Errorf("nil interface not ignored: %v", err)
+	}
+}
@@ -0,0 +1,361 @@
+package neotransaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// DecodeTransaction 将二进制交易数据解析为交易结构体
+// 数据可以是不包含鉴证人脚本的原始交易（UnsignedRawTransaction的输出），也可以是完整的签名交易
+func DecodeTransaction(data []byte) (*NeoTransaction, error) {
+	r := bytes.NewReader(data)
+	tx := &NeoTransaction{}
+
+	var err error
+	if tx.Type, err = r.ReadByte(); err != nil {
+		return nil, fmt.Errorf("DecodeTransaction: read type failed %v", err)
+	}
+	if tx.Version, err = r.ReadByte(); err != nil {
+		return nil, fmt.Errorf("DecodeTransaction: read version failed %v", err)
+	}
+	if err = tx.decodeExtraData(r); err != nil {
+		return nil, err
+	}
+	if err = tx.decodeAttributes(r); err != nil {
+		return nil, err
+	}
+	if err = tx.decodeInputs(r); err != nil {
+		return nil, err
+	}
+	if err = tx.decodeOutputs(r); err != nil {
+		return nil, err
+	}
+	tx.dirty = true
+
+	// 未签名的交易到此结束
+	if r.Len() == 0 {
+		return tx, nil
+	}
+	if err = tx.decodeScripts(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("DecodeTransaction: %v bytes of trailing data", r.Len())
+	}
+	return tx, nil
+}
+
+// DecodeTransactionString 将十六进制字符串表示的交易解析为交易结构体
+func DecodeTransactionString(rawtx string) (*NeoTransaction, error) {
+	data, err := hex.DecodeString(rawtx)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeTransactionString: %v", err)
+	}
+	return DecodeTransaction(data)
+}
+
+func (tx *NeoTransaction) decodeExtraData(r *bytes.Reader) error {
+	switch tx.Type {
+	case MinerTranscation:
+		nonce, err := neoutils.ReadUint32FromBuffer(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read miner nonce failed %v", err)
+		}
+		tx.ExtraData = &MinerExtraData{Nonce: nonce}
+	case ClaimTransaction:
+		count, err := neoutils.ReadVarIntFromBuffer(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read claims count failed %v", err)
+		}
+		claims, err := decodeTxInputs(r, count)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: claims %v", err)
+		}
+		tx.ExtraData = &ClaimExtraData{ClaimsCount: count, Claims: claims}
+	case IssueTransaction, ContractTransaction:
+		// 没有额外数据
+	case InvocationTransacton:
+		extra := &InvocationExtraData{}
+		script, err := neoutils.ReadVarBytesFromBuffer(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read invocation script failed %v", err)
+		}
+		extra.Script = script
+		extra.ScriptLength.Value = uint64(len(script))
+		if tx.Version >= 1 {
+			gas, err := neoutils.ReadUint64FromBuffer(r)
+			if err != nil {
+				return fmt.Errorf("DecodeTransaction: read gas consumed failed %v", err)
+			}
+			extra.GasConsumed = neoutils.Fixed8(gas)
+		}
+		tx.ExtraData = extra
+	case RegisterTransaction:
+		extra, err := decodeRegisterExtraData(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: register %v", err)
+		}
+		tx.ExtraData = extra
+	case EnrollmentTransaction:
+		pubKey, err := readECPoint(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read enrollment public key failed %v", err)
+		}
+		tx.ExtraData = &EnrollmentExtraData{PublicKey: pubKey}
+	case StateTransaction:
+		extra, err := decodeStateExtraData(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: state %v", err)
+		}
+		tx.ExtraData = extra
+	case PublishTransaction:
+		extra, err := decodePublishExtraData(r, tx.Version)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: publish %v", err)
+		}
+		tx.ExtraData = extra
+	default:
+		return fmt.Errorf("DecodeTransaction: transaction type 0x%02x not supported", tx.Type)
+	}
+	return nil
+}
+
+// readECPoint 读取一个序列化的公钥，0x00 表示无穷远点，0x02/0x03 为压缩格式，0x04 为非压缩格式
+func readECPoint(r *bytes.Reader) ([]byte, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length := 0
+	switch prefix {
+	case 0x00:
+		return []byte{0x00}, nil
+	case 0x02, 0x03:
+		length = 32
+	case 0x04:
+		length = 64
+	default:
+		return nil, fmt.Errorf("invalid public key prefix 0x%02x", prefix)
+	}
+	data, err := neoutils.ReadBytesFromBuffer(r, length)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{prefix}, data...), nil
+}
+
+func decodeRegisterExtraData(r *bytes.Reader) (*RegisterExtraData, error) {
+	extra := &RegisterExtraData{}
+	var err error
+	if extra.AssetType, err = r.ReadByte(); err != nil {
+		return nil, fmt.Errorf("read asset type failed %v", err)
+	}
+	name, err := neoutils.ReadVarBytesFromBuffer(r)
+	if err != nil {
+		return nil, fmt.Errorf("read name failed %v", err)
+	}
+	extra.Name = string(name)
+	amount, err := neoutils.ReadUint64FromBuffer(r)
+	if err != nil {
+		return nil, fmt.Errorf("read amount failed %v", err)
+	}
+	extra.Amount = neoutils.Fixed8(amount)
+	if extra.Precision, err = r.ReadByte(); err != nil {
+		return nil, fmt.Errorf("read precision failed %v", err)
+	}
+	if extra.Owner, err = readECPoint(r); err != nil {
+		return nil, fmt.Errorf("read owner failed %v", err)
+	}
+	if extra.Admin, err = neoutils.ReadBytesFromBuffer(r, 20); err != nil {
+		return nil, fmt.Errorf("read admin failed %v", err)
+	}
+	return extra, nil
+}
+
+func decodeStateExtraData(r *bytes.Reader) (*StateExtraData, error) {
+	count, err := neoutils.ReadVarIntFromBuffer(r)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptors count failed %v", err)
+	}
+	if count.Value > uint64(r.Len()/4) {
+		return nil, fmt.Errorf("descriptors count %v out of range", count.Value)
+	}
+	extra := &StateExtraData{Descriptors: make([]StateDescriptor, 0, count.Value)}
+	for i := uint64(0); i < count.Value; i++ {
+		d := StateDescriptor{}
+		if d.Type, err = r.ReadByte(); err != nil {
+			return nil, fmt.Errorf("read descriptor[%v] type failed %v", i, err)
+		}
+		if d.Key, err = neoutils.ReadVarBytesFromBuffer(r); err != nil {
+			return nil, fmt.Errorf("read descriptor[%v] key failed %v", i, err)
+		}
+		field, err := neoutils.ReadVarBytesFromBuffer(r)
+		if err != nil {
+			return nil, fmt.Errorf("read descriptor[%v] field failed %v", i, err)
+		}
+		d.Field = string(field)
+		if d.Value, err = neoutils.ReadVarBytesFromBuffer(r); err != nil {
+			return nil, fmt.Errorf("read descriptor[%v] value failed %v", i, err)
+		}
+		extra.Descriptors = append(extra.Descriptors, d)
+	}
+	return extra, nil
+}
+
+func decodePublishExtraData(r *bytes.Reader, version byte) (*PublishExtraData, error) {
+	extra := &PublishExtraData{}
+	var err error
+	if extra.Script, err = neoutils.ReadVarBytesFromBuffer(r); err != nil {
+		return nil, fmt.Errorf("read script failed %v", err)
+	}
+	if extra.ParameterList, err = neoutils.ReadVarBytesFromBuffer(r); err != nil {
+		return nil, fmt.Errorf("read parameter list failed %v", err)
+	}
+	if extra.ReturnType, err = r.ReadByte(); err != nil {
+		return nil, fmt.Errorf("read return type failed %v", err)
+	}
+	if version >= 1 {
+		needStorage, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("read need storage failed %v", err)
+		}
+		extra.NeedStorage = needStorage != 0
+	}
+	for _, dst := range []*string{&extra.Name, &extra.CodeVersion, &extra.Author, &extra.Email, &extra.Description} {
+		str, err := neoutils.ReadVarBytesFromBuffer(r)
+		if err != nil {
+			return nil, fmt.Errorf("read contract info failed %v", err)
+		}
+		*dst = string(str)
+	}
+	return extra, nil
+}
+
+func (tx *NeoTransaction) decodeAttributes(r *bytes.Reader) error {
+	count, err := neoutils.ReadVarIntFromBuffer(r)
+	if err != nil {
+		return fmt.Errorf("DecodeTransaction: read attributes count failed %v", err)
+	}
+	if count.Value > uint64(r.Len()) {
+		return fmt.Errorf("DecodeTransaction: attributes count %v out of range", count.Value)
+	}
+	tx.Attributes = make([]Attribute, 0, count.Value)
+	for i := uint64(0); i < count.Value; i++ {
+		usage, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read attribute[%v] usage failed %v", i, err)
+		}
+		var data []byte
+		switch {
+		case usage == UsageContractHash || usage == UsageECDH02 || usage == UsageECDH03 ||
+			usage == UsageVote || (usage >= UsageHash1 && usage <= UsageHash1+14):
+			data, err = neoutils.ReadBytesFromBuffer(r, 32)
+		case usage == UsageScript:
+			data, err = neoutils.ReadBytesFromBuffer(r, 20)
+		case usage == UsageDescriptionURL:
+			var length byte
+			if length, err = r.ReadByte(); err == nil {
+				data, err = neoutils.ReadBytesFromBuffer(r, int(length))
+			}
+		case usage == UsageDescription || usage >= UsageRemark:
+			data, err = neoutils.ReadVarBytesFromBuffer(r)
+		default:
+			return fmt.Errorf("DecodeTransaction: attribute[%v] usage 0x%02x not supported", i, usage)
+		}
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read attribute[%v] data failed %v", i, err)
+		}
+		tx.Attributes = append(tx.Attributes, Attribute{Usage: usage, Data: data})
+	}
+	tx.AttributeCount = count
+	return nil
+}
+
+func (tx *NeoTransaction) decodeInputs(r *bytes.Reader) error {
+	count, err := neoutils.ReadVarIntFromBuffer(r)
+	if err != nil {
+		return fmt.Errorf("DecodeTransaction: read inputs count failed %v", err)
+	}
+	inputs, err := decodeTxInputs(r, count)
+	if err != nil {
+		return fmt.Errorf("DecodeTransaction: %v", err)
+	}
+	tx.Inputs = inputs
+	tx.InputsCount = count
+	return nil
+}
+
+func decodeTxInputs(r *bytes.Reader, count neoutils.VarInt) ([]TxInput, error) {
+	if count.Value > uint64(r.Len()/34) {
+		return nil, fmt.Errorf("inputs count %v out of range", count.Value)
+	}
+	inputs := make([]TxInput, 0, count.Value)
+	for i := uint64(0); i < count.Value; i++ {
+		hash, err := neoutils.ReadBytesFromBuffer(r, 32)
+		if err != nil {
+			return nil, fmt.Errorf("read input[%v] hash failed %v", i, err)
+		}
+		index, err := neoutils.ReadUint16FromBuffer(r)
+		if err != nil {
+			return nil, fmt.Errorf("read input[%v] index failed %v", i, err)
+		}
+		inputs = append(inputs, TxInput{PrevHash: hash, PrevIndex: index})
+	}
+	return inputs, nil
+}
+
+func (tx *NeoTransaction) decodeOutputs(r *bytes.Reader) error {
+	count, err := neoutils.ReadVarIntFromBuffer(r)
+	if err != nil {
+		return fmt.Errorf("DecodeTransaction: read outputs count failed %v", err)
+	}
+	if count.Value > uint64(r.Len()/60) {
+		return fmt.Errorf("DecodeTransaction: outputs count %v out of range", count.Value)
+	}
+	tx.Outputs = make([]TxOutput, 0, count.Value)
+	for i := uint64(0); i < count.Value; i++ {
+		assetID, err := neoutils.ReadBytesFromBuffer(r, 32)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read output[%v] asset failed %v", i, err)
+		}
+		value, err := neoutils.ReadUint64FromBuffer(r)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read output[%v] value failed %v", i, err)
+		}
+		scriptHash, err := neoutils.ReadBytesFromBuffer(r, 20)
+		if err != nil {
+			return fmt.Errorf("DecodeTransaction: read output[%v] script hash failed %v", i, err)
+		}
+		tx.Outputs = append(tx.Outputs, TxOutput{AssetID: assetID, Value: neoutils.Fixed8(value), ScriptHash: scriptHash})
+	}
+	tx.OutputsCount = count
+	return nil
+}
+
+func (tx *NeoTransaction) decodeScripts(r *bytes.Reader) error {
+	count, err := neoutils.ReadVarIntFromBuffer(r)
+	if err != nil {
+		return fmt.Errorf("DecodeTransaction: read scripts count failed %v", err)
+	}
+	if count.Value > uint64(r.Len()/2) {
+		return fmt.Errorf("DecodeTransaction: scripts count %v out of range", count.Value)
+	}
+	tx.Scripts = make([]Script, 0, count.Value)
+	for i := uint64(0); i < count.Value; i++ {
+		script := Script{}
+		if script.InvocationScript, err = neoutils.ReadVarBytesFromBuffer(r); err != nil {
+			return fmt.Errorf("DecodeTransaction: read script[%v] invocation failed %v", i, err)
+		}
+		if script.VerificationScript, err = neoutils.ReadVarBytesFromBuffer(r); err != nil {
+			return fmt.Errorf("DecodeTransaction: read script[%v] verification failed %v", i, err)
+		}
+		script.InvScriptLength.Value = uint64(len(script.InvocationScript))
+		script.VrifScriptLength.Value = uint64(len(script.VerificationScript))
+		tx.Scripts = append(tx.Scripts, script)
+	}
+	tx.ScriptsCount = count
+	return nil
+}
@@ -0,0 +1,157 @@
+package neotransaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// genesisRegister 构造创世区块中登记NEO、GAS的交易
+func genesisRegister(assetType byte, name string, precision byte, admin byte) []byte {
+	buff := new(bytes.Buffer)
+	buff.WriteByte(RegisterTransaction)
+	buff.WriteByte(0)
+	buff.WriteByte(assetType)
+	writeVarBytes(buff, []byte(name))
+	neoutils.WriteUint64ToBuffer(buff, uint64(100000000*neoutils.Fixed8One))
+	buff.WriteByte(precision)
+	buff.WriteByte(0x00) // 无穷远点
+	buff.Write(neoutils.Hash160([]byte{admin}))
+	buff.Write([]byte{0, 0, 0}) // 属性、输入、输出
+	buff.WriteByte(0)           // 鉴证人
+	return buff.Bytes()
+}
+
+func TestDecodeTransactionRoundTrip(t *testing.T) {
+	key := GenerateKeyPair()
+	addr := key.CreateBasicAddress()
+
+	miner := new(bytes.Buffer)
+	miner.Write([]byte{MinerTranscation, 0})
+	neoutils.WriteUint32ToBuffer(miner, 2083236893)
+	miner.Write([]byte{0, 0, 0, 0})
+
+	invocation := CreateInvocationTransaction()
+	invocation.Version = 1
+	invocation.ExtraData.(*InvocationExtraData).Script = []byte{1, 2, 3}
+	invocation.ExtraData.(*InvocationExtraData).ScriptLength.Value = 3
+	invocation.ExtraData.(*InvocationExtraData).GasConsumed = 12345
+	invocation.AppendAttribute(UsageScript, addr.ScripHash)
+	invocation.AppendAttribute(UsageRemark, []byte("hello"))
+	invocation.AppendInputByTxHash(AssetNeoID, 3)
+	invocation.AppendOutput(addr, make([]byte, 32), 99)
+	verify := BuildBasicVerifyScript(key)
+	witness := &Script{InvocationScript: []byte{1, 9}, VerificationScript: verify}
+	witness.InvScriptLength.Value = 2
+	witness.VrifScriptLength.Value = uint64(len(verify))
+	invocation.AppendWitness(witness)
+
+	enrollment := &NeoTransaction{Type: EnrollmentTransaction, dirty: true, ExtraData: &EnrollmentExtraData{PublicKey: key.EncodePubkeyCompressed()}}
+	state := &NeoTransaction{Type: StateTransaction, dirty: true, ExtraData: &StateExtraData{Descriptors: []StateDescriptor{
+		{Type: 0x40, Key: addr.ScripHash, Field: "Votes", Value: append([]byte{1}, key.EncodePubkeyCompressed()...)},
+		{Type: 0x48, Key: key.EncodePubkeyCompressed(), Field: "Registered", Value: []byte{1}},
+	}}}
+	publish := &PublishExtraData{
+		Script:        []byte{0x51, 0x66},
+		ParameterList: []byte{0x07, 0x10},
+		ReturnType:    0x05,
+		NeedStorage:   true,
+		Name:          "name",
+		CodeVersion:   "1.0",
+		Author:        "author",
+		Email:         "email",
+		Description:   "description",
+	}
+	publishV0 := &NeoTransaction{Type: PublishTransaction, dirty: true, ExtraData: publish}
+	publishV1 := &NeoTransaction{Type: PublishTransaction, dirty: true, Version: 1, ExtraData: publish}
+
+	tests := []struct {
+		name string
+		raw  []byte
+		txid string
+	}{
+		{"GenesisMiner", miner.Bytes(), "fb5bd72b2d6792d75dc2f1084ffa9e9f70ca85543c717a6b13d9959b452a57d6"},
+		{"RegisterNEO", genesisRegister(0x00, `[{"lang":"zh-CN","name":"小蚁股"},{"lang":"en","name":"AntShare"}]`, 0, 0x51), AssetNeoID},
+		{"RegisterGAS", genesisRegister(0x01, `[{"lang":"zh-CN","name":"小蚁币"},{"lang":"en","name":"AntCoin"}]`, 8, 0x00), AssetGasID},
+		{"Invocation", invocation.RawTransaction(), invocation.TXID()},
+		{"Enrollment", enrollment.RawTransaction(), enrollment.TXID()},
+		{"State", state.RawTransaction(), state.TXID()},
+		{"PublishV0", publishV0.RawTransaction(), publishV0.TXID()},
+		{"PublishV1", publishV1.RawTransaction(), publishV1.TXID()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := DecodeTransaction(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(tx.RawTransaction(), tt.raw) {
+				t.Fatalf("re-encoded %x, want %x", tx.RawTransaction(), tt.raw)
+			}
+			if tx.TXID() != tt.txid {
+				t.Fatalf("txid %v, want %v", tx.TXID(), tt.txid)
+			}
+			unsigned, err := DecodeTransaction(tx.UnsignedRawTransaction())
+			if err != nil || unsigned.TXID() != tt.txid {
+				t.Fatalf("unsigned txid %v, err %v", unsigned, err)
+			}
+		})
+	}
+}
+
+func TestDecodePublishVersion(t *testing.T) {
+	publish := &PublishExtraData{Script: []byte{0x51}, NeedStorage: true, Name: "n"}
+	v0, err := DecodeTransaction((&NeoTransaction{Type: PublishTransaction, dirty: true, ExtraData: publish}).UnsignedRawTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v0.ExtraData.(*PublishExtraData).NeedStorage {
+		t.Fatal("version 0 publish transaction has no need storage flag")
+	}
+	v1, err := DecodeTransaction((&NeoTransaction{Type: PublishTransaction, dirty: true, Version: 1, ExtraData: publish}).UnsignedRawTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extra := v1.ExtraData.(*PublishExtraData); !extra.NeedStorage || extra.Name != "n" {
+		t.Fatalf("version 1 publish %+v", extra)
+	}
+}
+
+func TestDecodeInvocationVersion(t *testing.T) {
+	invocation := &InvocationExtraData{Script: []byte{0x51}, GasConsumed: neoutils.Fixed8One}
+	if hex.EncodeToString(invocation.Bytes()) != "015100e1f50500000000" {
+		t.Fatalf("invocation extra data %x", invocation.Bytes())
+	}
+	v0 := (&NeoTransaction{Type: InvocationTransacton, dirty: true, ExtraData: invocation}).UnsignedRawTransaction()
+	if hex.EncodeToString(v0) != "d1000151000000" {
+		t.Fatalf("version 0 invocation %x", v0)
+	}
+	v1, err := DecodeTransaction((&NeoTransaction{Type: InvocationTransacton, dirty: true, Version: 1, ExtraData: invocation}).UnsignedRawTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extra := v1.ExtraData.(*InvocationExtraData); extra.GasConsumed != neoutils.Fixed8One {
+		t.Fatalf("version 1 invocation %+v", extra)
+	}
+}
+
+func TestDecodeTransactionErrors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"40",
+		"4000",
+		"20000500",           // 无效的公钥前缀
+		"9000ff",             // 描述数量超出数据长度
+		"ff00000000",         // 未知的交易类型
+		"800000000001ff",     // 截断的输入
+		"d000015100000000",   // 截断的合约信息
+		"800001800161000000", // 节点不支持的 CertUrl 属性
+	} {
+		data, _ := hex.DecodeString(raw)
+		if _, err := DecodeTransaction(data); err == nil {
+			t.Errorf("DecodeTransaction(%q) expected error", raw)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package neotransaction
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// FeePolicy NEO 2.x 网络手续费策略
+// 交易大小超过 MaxFreeSize 时，手续费至少为 交易大小 * FeePerByte + BaseFee，否则可以免费
+// 手续费不低于 BaseFee 的交易会进入高优先级队列
+type FeePolicy struct {
+	MaxFreeSize int             // 免费交易的最大字节数
+	FeePerByte  neoutils.Fixed8 // 每字节手续费
+	BaseFee     neoutils.Fixed8 // 基础手续费
+}
+
+// DefaultFeePolicy neo-cli 2.x 默认的手续费策略：1024字节以内免费，超出部分每字节0.00001 GAS外加0.001 GAS
+var DefaultFeePolicy = FeePolicy{
+	MaxFreeSize: 1024,
+	FeePerByte:  1000,
+	BaseFee:     100000,
+}
+
+// CalculateFee 根据交易大小计算需要的网络手续费，priorityFee 为希望额外支付的优先级手续费
+// 返回值不低于 priorityFee，手续费超出 Fixed8 的范围时返回错误
+func (policy *FeePolicy) CalculateFee(size int, priorityFee neoutils.Fixed8) (neoutils.Fixed8, error) {
+	fee := neoutils.Fixed8(0)
+	if size > policy.MaxFreeSize {
+		perByte, err := policy.FeePerByte.Mul(int64(size))
+		if err == nil {
+			fee, err = perByte.Add(policy.BaseFee)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("CalculateFee %v", err)
+		}
+	}
+	if fee < priorityFee {
+		fee = priorityFee
+	}
+	return fee, nil
+}
+
+// EstimateWitnessSize 估算一个鉴证人脚本序列化后的字节数
+// verificationScript 为nil时按基本账户估算；非标准合约无法预知压栈脚本，只计算鉴权脚本
+func EstimateWitnessSize(verificationScript []byte) int {
+	if verificationScript == nil {
+		// 基本账户: 压栈脚本 1+65 字节，鉴权脚本 1+35 字节
+		return 102
+	}
+	invocationSize := 0
+	if IsBasicVerifyScript(verificationScript) {
+		invocationSize = 65
+	} else if m, _, ok := ParseMultiSigVerifyScript(verificationScript); ok {
+		invocationSize = 65 * m
+	}
+	return neoutils.VarInt{Value: uint64(invocationSize)}.Length() + invocationSize +
+		neoutils.VarInt{Value: uint64(len(verificationScript))}.Length() + len(verificationScript)
+}
+
+// EstimateSize 估算交易签名后的字节数
+// verificationScripts 为将要签名的所有鉴证人的鉴权脚本（元素为nil表示按基本账户估算）；
+// 如果交易已经包含鉴证人脚本，则直接返回完整交易的大小
+func (tx *NeoTransaction) EstimateSize(verificationScripts [][]byte) int {
+	if len(tx.Scripts) > 0 {
+		return len(tx.RawTransaction())
+	}
+	size := len(tx.UnsignedRawTransaction())
+	size += neoutils.VarInt{Value: uint64(len(verificationScripts))}.Length()
+	for _, script := range verificationScripts {
+		size += EstimateWitnessSize(script)
+	}
+	return size
+}
+
+// signerScripts 根据需要鉴证的ScriptHash在 signers 中查找鉴权脚本，找不到的按基本账户估算
+func signerScripts(scriptHashes []neoutils.HASH160, signers []*Address) [][]byte {
+	scripts := make([][]byte, 0, len(scriptHashes))
+	for _, hash := range scriptHashes {
+		var script []byte
+		for _, signer := range signers {
+			if signer.HaveScript() && bytes.Equal(signer.ScripHash, hash) {
+				script = signer.Script
+				break
+			}
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts
+}
+
+// addressScripts 返回一组地址的鉴权脚本
+func addressScripts(signers []*Address) [][]byte {
+	scripts := make([][]byte, 0, len(signers))
+	for _, signer := range signers {
+		scripts = append(scripts, signer.Script)
+	}
+	return scripts
+}
+
+// maxFeeIterations 自动计算手续费时的最大迭代次数
+const maxFeeIterations = 8
+
+// ReserveNetworkFee 按手续费策略计算网络手续费，并从 gasUTXOs 中选择GAS作为额外输入支付，多余的GAS找零到 change
+// signers 为所有将要签名此交易的账户（需要带鉴权脚本以便估算多签账户的大小）；
+// 为空时交易的所有输入都必须能在 gasUTXOs 中找到，按基本账户估算每个需要鉴证的ScriptHash
+// 适用于输入输出已经平衡的交易，例如 InvocationTransaction；返回实际预留的手续费
+func (tx *NeoTransaction) ReserveNetworkFee(gasUTXOs []*UTXO, change *Address, signers []*Address, policy FeePolicy, priorityFee neoutils.Fixed8) (neoutils.Fixed8, error) {
+	gasID, _ := neoutils.ParseHASH256(AssetGasID)
+	inputs := tx.Inputs
+	outputs := tx.Outputs
+
+	candidates := make([]*UTXO, 0)
+	for _, utxo := range uniqueUTXOs(gasUTXOs) {
+		if !bytes.Equal(utxo.AssetID, gasID) {
+			continue
+		}
+		used := false
+		for _, input := range inputs {
+			if input.PrevIndex == utxo.Index && bytes.Equal(input.PrevHash, utxo.TxHash) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			candidates = append(candidates, utxo)
+		}
+	}
+
+	// 没有提供 signers 时，根据交易输入和 UsageScript 属性得到需要鉴证的ScriptHash，均按基本账户估算
+	verificationScripts := func() ([][]byte, error) {
+		if len(signers) > 0 {
+			return addressScripts(signers), nil
+		}
+		hashes, err := tx.ScriptHashesForVerifying(gasUTXOs)
+		if err != nil {
+			return nil, fmt.Errorf("ReserveNetworkFee signers not set and %v", err)
+		}
+		return signerScripts(hashes, nil), nil
+	}
+
+	scripts, err := verificationScripts()
+	if err != nil {
+		return 0, err
+	}
+	fee, err := policy.CalculateFee(tx.EstimateSize(scripts), priorityFee)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < maxFeeIterations; i++ {
+		tx.Inputs = inputs
+		tx.Outputs = outputs
+		tx.dirty = true
+		if fee == 0 {
+			return 0, nil
+		}
+
+		selected := SelectLargestFirst(candidates, fee)
+		if selected == nil {
+			available, err := sumUTXOs(candidates)
+			if err != nil {
+				return 0, fmt.Errorf("ReserveNetworkFee available %v", err)
+			}
+			return 0, &InsufficientFundsError{AssetID: gasID, Required: fee, Available: available}
+		}
+		sum, err := sumUTXOs(selected)
+		if err != nil {
+			return 0, fmt.Errorf("ReserveNetworkFee selected %v", err)
+		}
+		tx.Inputs = append([]TxInput{}, inputs...)
+		for _, utxo := range selected {
+			tx.AppendInput(utxo)
+		}
+		tx.Outputs = append([]TxOutput{}, outputs...)
+		if sum > fee {
+			if change == nil {
+				tx.Inputs, tx.Outputs = inputs, outputs
+				return 0, errors.New("ReserveNetworkFee change address not set")
+			}
+			tx.AppendOutput(change, gasID, sum-fee)
+		}
+
+		if scripts, err = verificationScripts(); err != nil {
+			tx.Inputs, tx.Outputs = inputs, outputs
+			tx.dirty = true
+			return 0, err
+		}
+		required, err := policy.CalculateFee(tx.EstimateSize(scripts), priorityFee)
+		if err != nil {
+			tx.Inputs, tx.Outputs = inputs, outputs
+			tx.dirty = true
+			return 0, err
+		}
+		if required <= fee {
+			return fee, nil
+		}
+		fee = required
+	}
+	tx.Inputs, tx.Outputs = inputs, outputs
+	tx.dirty = true
+	return 0, errors.New("ReserveNetworkFee fee calculation not converged")
+}
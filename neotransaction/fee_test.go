@@ -0,0 +1,127 @@
+package neotransaction
+
+import (
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// feeTestTransaction 构造一个脚本长度为1100字节的调用交易，以及属于 owner 的GAS UTXO
+func feeTestTransaction(owner *Address) (*NeoTransaction, []*UTXO) {
+	gas, _ := neoutils.ParseHASH256(AssetGasID)
+	tx := CreateInvocationTransaction()
+	extra := tx.ExtraData.(*InvocationExtraData)
+	extra.Script = make([]byte, 1100)
+	extra.ScriptLength.Value = uint64(len(extra.Script))
+	utxos := testUTXOs(gas, 3*neoutils.Fixed8One)
+	utxos[0].ScriptHash = owner.ScripHash
+	return tx, utxos
+}
+
+// neoCliFee neo-cli 2.x 对超过1024字节的交易要求的最低网络手续费
+func neoCliFee(size int) neoutils.Fixed8 {
+	return neoutils.Fixed8(size)*1000 + 100000
+}
+
+func TestReserveNetworkFee(t *testing.T) {
+	keys := []*KeyPair{keyPairFromInt(1), keyPairFromInt(2), keyPairFromInt(3)}
+	basic := keys[0].CreateBasicAddress()
+	multiSig, _ := CreateMultiSigAddress(2, keys)
+
+	tests := []struct {
+		name    string
+		owner   *Address
+		signers []*Address
+		sign    func(tx *NeoTransaction)
+		size    int
+	}{
+		{"SingleSigWithoutSigners", basic, nil, func(tx *NeoTransaction) {
+			tx.AppendBasicSignWitness(keys[0])
+		}, 1305},
+		{"SingleSig", basic, []*Address{basic}, func(tx *NeoTransaction) {
+			tx.AppendBasicSignWitness(keys[0])
+		}, 1305},
+		{"MultiSig2of3", multiSig, []*Address{multiSig}, func(tx *NeoTransaction) {
+			w, _ := NewMultiSigWitness(multiSig.Script)
+			w.Sign(keys[0], tx.UnsignedRawTransaction())
+			w.Sign(keys[2], tx.UnsignedRawTransaction())
+			if err := tx.AppendMultiSignWitness(w); err != nil {
+				t.Fatal(err)
+			}
+		}, 1440},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, utxos := feeTestTransaction(tt.owner)
+			fee, err := tx.ReserveNetworkFee(utxos, tt.owner, tt.signers, DefaultFeePolicy, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tx.Inputs) != 1 || len(tx.Outputs) != 1 || tx.Outputs[0].Value != 3*neoutils.Fixed8One-fee {
+				t.Fatalf("inputs %v outputs %+v", len(tx.Inputs), tx.Outputs)
+			}
+			tt.sign(tx)
+			size := len(tx.RawTransaction())
+			if size != tt.size {
+				t.Fatalf("signed size %v, want %v", size, tt.size)
+			}
+			if fee != neoCliFee(size) {
+				t.Fatalf("fee %v, want %v", fee, neoCliFee(size))
+			}
+		})
+	}
+}
+
+func TestCalculateFee(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		priority neoutils.Fixed8
+		fee      neoutils.Fixed8
+	}{
+		{"Free", 1024, 0, 0},
+		{"FreeWithPriority", 1024, 5, 5},
+		{"PerByte", 1025, 0, neoCliFee(1025)},
+		{"PriorityAboveFee", 1025, neoutils.Fixed8One, neoutils.Fixed8One},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, err := DefaultFeePolicy.CalculateFee(tt.size, tt.priority)
+			if err != nil || fee != tt.fee {
+				t.Fatalf("fee %v err %v, want %v", fee, err, tt.fee)
+			}
+		})
+	}
+
+	policy := FeePolicy{MaxFreeSize: 1024, FeePerByte: neoutils.MaxFixed8 / 1024}
+	if _, err := policy.CalculateFee(2048, 0); err == nil {
+		t.Fatal("expected overflow error")
+	}
+	policy = FeePolicy{MaxFreeSize: 1024, FeePerByte: 1, BaseFee: neoutils.MaxFixed8}
+	if _, err := policy.CalculateFee(2048, 0); err == nil {
+		t.Fatal("expected overflow error")
+	}
+}
+
+func TestReserveNetworkFeeFree(t *testing.T) {
+	addr := keyPairFromInt(1).CreateBasicAddress()
+	tx, utxos := feeTestTransaction(addr)
+	tx.ExtraData.(*InvocationExtraData).Script = []byte{0x51}
+	tx.ExtraData.(*InvocationExtraData).ScriptLength.Value = 1
+	fee, err := tx.ReserveNetworkFee(utxos, addr, nil, DefaultFeePolicy, 0)
+	if err != nil || fee != 0 || len(tx.Inputs) != 0 {
+		t.Fatalf("fee %v inputs %v err %v", fee, len(tx.Inputs), err)
+	}
+}
+
+func TestReserveNetworkFeeUnknownInput(t *testing.T) {
+	addr := keyPairFromInt(1).CreateBasicAddress()
+	tx, utxos := feeTestTransaction(addr)
+	tx.AppendInputByTxHash(AssetNeoID, 0)
+	if _, err := tx.ReserveNetworkFee(utxos, addr, nil, DefaultFeePolicy, 0); err == nil {
+		t.Fatal("expected error for input not in gasUTXOs without signers")
+	}
+	if len(tx.Inputs) != 1 || len(tx.Outputs) != 0 {
+		t.Fatalf("transaction not restored: inputs %v outputs %v", len(tx.Inputs), len(tx.Outputs))
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"encoding/hex"
 	"errors"
 	"math/big"
-	"strings"
 
 	"github.com/x-contract/neo-go-sdk/neoutils"
 )
@@ -79,8 +78,7 @@ func DecodeFromPubkey(pub []byte) (*KeyPair, error) {
 func (key *KeyPair) EncodeWif() string {
 	buff := make([]byte, 34)
 	buff[0] = 0x80
-	d := key.D.Bytes()
-	copy(buff[1:33], d)
+	key.D.FillBytes(buff[1:33])
 	buff[33] = 0x01
 	buff, _ = neoutils.EncodeBase58WithChecksum(buff)
 	return string(buff)
@@ -95,7 +93,7 @@ func (key *KeyPair) EncodePubkeyCompressed() []byte {
 	} else {
 		data[0] = 0x03
 	}
-	copy(data[1:], key.X.Bytes())
+	key.X.FillBytes(data[1:])
 	return data
 }
 
@@ -104,9 +102,14 @@ func (key *KeyPair) Sign(data []byte) ([]byte, error) {
 	if key == nil || !key.HasPrivKey() {
 		return nil, errors.New("The KeyPair does not contain private key")
 	}
-	r, s, _ := ecdsa.Sign(strings.NewReader("Nidces Ecc Signer"), &key.PrivateKey, data)
-	ret := r.Bytes()
-	ret = append(ret, s.Bytes()...)
+	r, s, err := ecdsa.Sign(rand.Reader, &key.PrivateKey, data)
+	if err != nil {
+		return nil, err
+	}
+	// r 和 s 各占固定的32字节，不足的在前面补0
+	ret := make([]byte, 64)
+	r.FillBytes(ret[:32])
+	s.FillBytes(ret[32:])
 	return ret, nil
 }
 
@@ -0,0 +1,101 @@
+package neotransaction
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// keyPairFromInt 使用给定的私钥构造公私钥对
+func keyPairFromInt(d int64) *KeyPair {
+	key := &KeyPair{}
+	key.Curve = elliptic.P256()
+	key.D = big.NewInt(d)
+	key.X, key.Y = key.Curve.ScalarBaseMult(key.D.Bytes())
+	return key
+}
+
+// TestSignShortRS r 或 s 的高位字节为0时签名仍然是64字节并且可以验签
+func TestSignShortRS(t *testing.T) {
+	key := GenerateKeyPair()
+	hash := neoutils.Sha256([]byte("short r/s"))
+	short := 0
+	for i := 0; i < 2000 && short < 4; i++ {
+		sig, err := key.Sign(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != 64 {
+			t.Fatalf("signature length %v", len(sig))
+		}
+		if !key.Verify(hash, sig) {
+			t.Fatalf("signature %x verify failed", sig)
+		}
+		if sig[0] == 0 || sig[32] == 0 {
+			short++
+		}
+	}
+	if short == 0 {
+		t.Fatal("no signature with short r or s found")
+	}
+}
+
+func TestEncodeShortKey(t *testing.T) {
+	key := keyPairFromInt(1)
+	decoded, err := DecodeFromWif(key.EncodeWif())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.D.Cmp(key.D) != 0 || decoded.X.Cmp(key.X) != 0 {
+		t.Fatalf("wif round trip got %v", decoded.D)
+	}
+
+	for i := 0; i < 5000; i++ {
+		key = GenerateKeyPair()
+		if len(key.X.Bytes()) < 32 {
+			break
+		}
+	}
+	pub := key.EncodePubkeyCompressed()
+	if len(pub) != 33 || new(big.Int).SetBytes(pub[1:]).Cmp(key.X) != 0 {
+		t.Fatalf("compressed public key %x of x %x", pub, key.X)
+	}
+}
+
+func TestEmitPushNumber(t *testing.T) {
+	tests := []struct {
+		arg  int64
+		want string
+	}{
+		{-1, "4f"},
+		{0, "00"},
+		{16, "60"},
+		{17, "0111"},
+		{-2, "01fe"},
+		{127, "017f"},
+		{128, "028000"},
+		{255, "02ff00"},
+		{-129, "027fff"},
+		{100000000, "0400e1f505"},
+	}
+	for _, tt := range tests {
+		sb := &ScriptBuilder{}
+		sb.EmitPushNumber(tt.arg)
+		if got := hex.EncodeToString(sb.Bytes()); got != tt.want {
+			t.Errorf("EmitPushNumber(%v) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestAppendWitnessAfterWitnesses(t *testing.T) {
+	tx := CreateContractTransaction()
+	before := tx.RawTransaction()
+	tx.AppendWitness(&Script{InvocationScript: []byte{}, VerificationScript: []byte{}})
+	if bytes.Equal(tx.RawTransaction(), before) {
+		t.Fatal("witness appended after Witnesses is missing")
+	}
+}
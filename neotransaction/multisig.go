@@ -0,0 +1,218 @@
+package neotransaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction/OpCode"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// MaxMultiSigKeys 多签账户允许的最大公钥数量
+const MaxMultiSigKeys = 1024
+
+// SortPublicKeys 按照neo-cli的规则对公钥排序（先比较X坐标，再比较Y坐标）
+// 返回排序后的新数组，不修改输入
+func SortPublicKeys(keys []*KeyPair) []*KeyPair {
+	sorted := make([]*KeyPair, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if c := sorted[i].X.Cmp(sorted[j].X); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Y.Cmp(sorted[j].Y) < 0
+	})
+	return sorted
+}
+
+// BuildMultiSigVerifyScript 创建m-n多签账户鉴权脚本
+// =============多签鉴权脚本============
+// Push m
+// Push PublicKey 1 ... PublicKey n (排序后)
+// Push n
+// CheckMultiSig
+// ================================
+func BuildMultiSigVerifyScript(m int, keys []*KeyPair) ([]byte, error) {
+	n := len(keys)
+	if m < 1 || m > n || n > MaxMultiSigKeys {
+		return nil, fmt.Errorf("BuildMultiSigVerifyScript invalid m[%v] n[%v]", m, n)
+	}
+	sb := ScriptBuilder{}
+	sb.EmitPushNumber(int64(m))
+	for _, key := range SortPublicKeys(keys) {
+		sb.EmitPushBytes(key.EncodePubkeyCompressed())
+	}
+	sb.EmitPushNumber(int64(n))
+	sb.Emit(OpCode.CHECKMULTISIG)
+	return sb.Bytes(), nil
+}
+
+// CreateMultiSigAddress 使用一组公钥创建m-n多签账户地址，地址中包含鉴权脚本
+func CreateMultiSigAddress(m int, keys []*KeyPair) (*Address, error) {
+	script, err := BuildMultiSigVerifyScript(m, keys)
+	if err != nil {
+		return nil, err
+	}
+	return CreateAddressByScript(script)
+}
+
+// IsBasicVerifyScript 判断一个鉴权脚本是否是基本账户鉴权脚本
+func IsBasicVerifyScript(script []byte) bool {
+	return len(script) == 35 && script[0] == 33 && script[34] == byte(OpCode.CHECKSIG)
+}
+
+// ParseMultiSigVerifyScript 解析多签账户鉴权脚本，返回m以及按脚本顺序排列的压缩公钥
+// 如果不是多签鉴权脚本则返回false
+func ParseMultiSigVerifyScript(script []byte) (int, [][]byte, bool) {
+	r := bytes.NewReader(script)
+	m, ok := readPushNumber(r)
+	if !ok || m < 1 {
+		return 0, nil, false
+	}
+	pubKeys := make([][]byte, 0)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, false
+		}
+		if b != 33 {
+			r.UnreadByte()
+			break
+		}
+		key, err := neoutils.ReadBytesFromBuffer(r, 33)
+		if err != nil {
+			return 0, nil, false
+		}
+		pubKeys = append(pubKeys, key)
+	}
+	n, ok := readPushNumber(r)
+	if !ok || n != int64(len(pubKeys)) || m > n {
+		return 0, nil, false
+	}
+	if b, err := r.ReadByte(); err != nil || b != byte(OpCode.CHECKMULTISIG) || r.Len() != 0 {
+		return 0, nil, false
+	}
+	return int(m), pubKeys, true
+}
+
+// readPushNumber 读取一条由 EmitPushNumber 产生的压栈小整数指令
+func readPushNumber(r *bytes.Reader) (int64, bool) {
+	op, err := r.ReadByte()
+	if err != nil {
+		return 0, false
+	}
+	if op >= byte(OpCode.PUSH1) && op <= byte(OpCode.PUSH16) {
+		return int64(op-byte(OpCode.PUSH1)) + 1, true
+	}
+	if op == 0 || op > 2 {
+		return 0, false
+	}
+	data, err := neoutils.ReadBytesFromBuffer(r, int(op))
+	if err != nil {
+		return 0, false
+	}
+	v := int64(data[0])
+	if op == 2 {
+		v |= int64(data[1]) << 8
+	}
+	return v, true
+}
+
+// MultiSigWitness 多签账户的鉴证人，可以分多次从不同的 KeyPair 收集签名
+// 签名收集足够 m 个之后即可生成鉴证人脚本
+type MultiSigWitness struct {
+	M                  int
+	PubKeys            [][]byte // 鉴权脚本中的压缩公钥，已排序
+	VerificationScript []byte
+	Signatures         map[string][]byte // 公钥的十六进制字符串 -> 签名
+}
+
+// NewMultiSigWitness 根据多签鉴权脚本创建一个多签鉴证人
+func NewMultiSigWitness(verificationScript []byte) (*MultiSigWitness, error) {
+	m, pubKeys, ok := ParseMultiSigVerifyScript(verificationScript)
+	if !ok {
+		return nil, errors.New("NewMultiSigWitness invalid multi-sig verification script")
+	}
+	return &MultiSigWitness{
+		M:                  m,
+		PubKeys:            pubKeys,
+		VerificationScript: verificationScript,
+		Signatures:         make(map[string][]byte),
+	}, nil
+}
+
+// indexOf 返回公钥在鉴权脚本中的位置，不存在时返回-1
+func (w *MultiSigWitness) indexOf(pubKey []byte) int {
+	for i, key := range w.PubKeys {
+		if bytes.Equal(key, pubKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Sign 使用 key 对原始交易 rawTx 签名并加入签名列表
+func (w *MultiSigWitness) Sign(key *KeyPair, rawTx []byte) error {
+	signature, err := key.Sign(neoutils.Sha256(rawTx))
+	if err != nil {
+		return err
+	}
+	return w.AddSignature(key.EncodePubkeyCompressed(), signature)
+}
+
+// AddSignature 加入一个由其它签名方提供的签名，pubKey 必须是鉴权脚本中的公钥
+func (w *MultiSigWitness) AddSignature(pubKey []byte, signature []byte) error {
+	if w.indexOf(pubKey) < 0 {
+		return fmt.Errorf("MultiSigWitness.AddSignature public key %s not in script", hex.EncodeToString(pubKey))
+	}
+	if len(signature) != 64 {
+		return errors.New("MultiSigWitness.AddSignature invalid signature length")
+	}
+	w.Signatures[hex.EncodeToString(pubKey)] = signature
+	return nil
+}
+
+// IsComplete 判断是否已经收集到足够的签名
+func (w *MultiSigWitness) IsComplete() bool {
+	return len(w.Signatures) >= w.M
+}
+
+// Script 生成多签鉴证人脚本，压栈脚本按照公钥在鉴权脚本中的顺序压入 m 个签名
+func (w *MultiSigWitness) Script() (*Script, error) {
+	if !w.IsComplete() {
+		return nil, fmt.Errorf("MultiSigWitness.Script need %v signatures but got %v", w.M, len(w.Signatures))
+	}
+	sb := ScriptBuilder{}
+	count := 0
+	for _, key := range w.PubKeys {
+		signature, ok := w.Signatures[hex.EncodeToString(key)]
+		if !ok {
+			continue
+		}
+		sb.EmitPushBytes(signature)
+		count++
+		if count == w.M {
+			break
+		}
+	}
+
+	script := &Script{}
+	script.InvocationScript = sb.Bytes()
+	script.InvScriptLength.Value = uint64(len(script.InvocationScript))
+	script.VerificationScript = w.VerificationScript
+	script.VrifScriptLength.Value = uint64(len(script.VerificationScript))
+	return script, nil
+}
+
+// AppendMultiSignWitness 向交易添加一个已收集足够签名的多签账户鉴证人脚本
+func (tx *NeoTransaction) AppendMultiSignWitness(w *MultiSigWitness) error {
+	script, err := w.Script()
+	if err != nil {
+		return err
+	}
+	tx.AppendWitness(script)
+	return nil
+}
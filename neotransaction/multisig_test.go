@@ -0,0 +1,177 @@
+package neotransaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// P-256 上私钥为1、2、3的压缩公钥
+const (
+	pubKey1 = "036b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"
+	pubKey2 = "037cf27b188d034f7e8a52380304b51ac3c08969e277f21b35a60b48fc47669978"
+	pubKey3 = "025ecbe4d1a6330a44c8f7ef951d4bf165e6c6b721efada985fb41661bc6e7fd6c"
+)
+
+// multiSig2of3Script 私钥1、2、3的2-3多签鉴权脚本，公钥按X坐标排序为 3、1、2
+const multiSig2of3Script = "52" + "21" + pubKey3 + "21" + pubKey1 + "21" + pubKey2 + "53" + "ae"
+
+func TestSortPublicKeys(t *testing.T) {
+	key1, key2, key3 := keyPairFromInt(1), keyPairFromInt(2), keyPairFromInt(3)
+	for i, want := range []string{pubKey1, pubKey2, pubKey3} {
+		if got := hex.EncodeToString(keyPairFromInt(int64(i + 1)).EncodePubkeyCompressed()); got != want {
+			t.Fatalf("public key of %v: %v", i+1, got)
+		}
+	}
+	input := []*KeyPair{key2, key1, key3}
+	sorted := SortPublicKeys(input)
+	if sorted[0] != key3 || sorted[1] != key1 || sorted[2] != key2 {
+		t.Fatal("keys not sorted by X")
+	}
+	if input[0] != key2 || input[1] != key1 || input[2] != key3 {
+		t.Fatal("input modified")
+	}
+
+	// X 坐标相同时按 Y 排序：-G 与 G 的 X 相同，Y 为 p - G.y，比 G.y 大
+	negative := &KeyPair{}
+	negative.Curve = key1.Curve
+	negative.X = new(big.Int).Set(key1.X)
+	negative.Y = new(big.Int).Sub(key1.Curve.Params().P, key1.Y)
+	if sorted = SortPublicKeys([]*KeyPair{negative, key1}); sorted[0] != key1 || sorted[1] != negative {
+		t.Fatal("keys with the same X not sorted by Y")
+	}
+}
+
+func TestBuildMultiSigVerifyScript(t *testing.T) {
+	keys := []*KeyPair{keyPairFromInt(1), keyPairFromInt(2), keyPairFromInt(3)}
+	script, err := BuildMultiSigVerifyScript(2, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(script) != multiSig2of3Script {
+		t.Fatalf("script %x\nwant   %v", script, multiSig2of3Script)
+	}
+	// 公钥的顺序不影响脚本和地址
+	reordered, _ := CreateMultiSigAddress(2, []*KeyPair{keys[2], keys[0], keys[1]})
+	if !bytes.Equal(reordered.Script, script) || !bytes.Equal(reordered.ScripHash, neoutils.Hash160(script)) {
+		t.Fatalf("address of reordered keys %+v", reordered)
+	}
+
+	for _, tt := range []struct{ m, n int }{{0, 3}, {4, 3}, {-1, 3}, {1, 0}} {
+		if _, err = BuildMultiSigVerifyScript(tt.m, keys[:tt.n]); err == nil {
+			t.Errorf("m %v n %v accepted", tt.m, tt.n)
+		}
+	}
+}
+
+func TestParseMultiSigVerifyScript(t *testing.T) {
+	keys := make([]*KeyPair, 0, 17)
+	for i := int64(1); i <= 17; i++ {
+		keys = append(keys, keyPairFromInt(i))
+	}
+	// 17个公钥时 m、n 超过 PUSH16，使用 PUSHBYTES1 压栈
+	for _, tt := range []struct{ m, n int }{{1, 1}, {2, 3}, {3, 3}, {16, 16}, {2, 17}, {17, 17}} {
+		script, err := BuildMultiSigVerifyScript(tt.m, keys[:tt.n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, pubKeys, ok := ParseMultiSigVerifyScript(script)
+		if !ok || m != tt.m || len(pubKeys) != tt.n {
+			t.Fatalf("m %v n %v parsed as %v %v %v", tt.m, tt.n, m, len(pubKeys), ok)
+		}
+		for i, key := range SortPublicKeys(keys[:tt.n]) {
+			if !bytes.Equal(pubKeys[i], key.EncodePubkeyCompressed()) {
+				t.Fatalf("m %v n %v public key %v %x", tt.m, tt.n, i, pubKeys[i])
+			}
+		}
+		if IsBasicVerifyScript(script) {
+			t.Fatalf("m %v n %v script is basic", tt.m, tt.n)
+		}
+	}
+
+	valid, _ := hex.DecodeString(multiSig2of3Script)
+	invalid := map[string][]byte{
+		"basic":     BuildBasicVerifyScript(keys[0]),
+		"empty":     {},
+		"truncated": valid[:len(valid)-1],
+		"trailing":  append(append([]byte{}, valid...), 0x61),
+		"wrong n":   append(append(append([]byte{}, valid[:len(valid)-2]...), 0x52), 0xae),
+		"m > n":     append([]byte{0x54}, valid[1:]...),
+		"m is 0":    append([]byte{0x00}, valid[1:]...),
+		"CHECKSIG":  append(append([]byte{}, valid[:len(valid)-1]...), 0xac),
+		"short key": append([]byte{0x51, 0x20}, valid[2:]...),
+	}
+	for name, script := range invalid {
+		if m, _, ok := ParseMultiSigVerifyScript(script); ok {
+			t.Errorf("%v script parsed with m %v", name, m)
+		}
+	}
+}
+
+func TestMultiSigWitness(t *testing.T) {
+	key1, key2, key3 := keyPairFromInt(1), keyPairFromInt(2), keyPairFromInt(3)
+	script, _ := hex.DecodeString(multiSig2of3Script)
+	witness, err := NewMultiSigWitness(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if witness.M != 2 || len(witness.PubKeys) != 3 {
+		t.Fatalf("witness %+v", witness)
+	}
+	if _, err = NewMultiSigWitness(BuildBasicVerifyScript(key1)); err == nil {
+		t.Fatal("witness created with a basic script")
+	}
+
+	rawTx := []byte("unsigned transaction")
+	if err = witness.Sign(keyPairFromInt(4), rawTx); err == nil {
+		t.Fatal("signed with a key not in the script")
+	}
+	if err = witness.AddSignature(key1.EncodePubkeyCompressed(), make([]byte, 63)); err == nil {
+		t.Fatal("signature with a wrong length added")
+	}
+	if err = witness.Sign(key2, rawTx); err != nil {
+		t.Fatal(err)
+	}
+	if witness.IsComplete() {
+		t.Fatal("complete with one signature")
+	}
+	if _, err = witness.Script(); err == nil {
+		t.Fatal("script built with one signature")
+	}
+	// 重复签名只算一个
+	if err = witness.Sign(key2, rawTx); err != nil || witness.IsComplete() {
+		t.Fatalf("signing twice completed the witness: %v", err)
+	}
+	if err = witness.Sign(key3, rawTx); err != nil || !witness.IsComplete() {
+		t.Fatalf("witness not complete: %v", err)
+	}
+
+	s, err := witness.Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.VerificationScript, script) || s.VrifScriptLength.Value != uint64(len(script)) {
+		t.Fatalf("verification script %x", s.VerificationScript)
+	}
+	// 签名按公钥在鉴权脚本中的顺序压栈：公钥3在公钥2之前
+	invocation := s.InvocationScript
+	if len(invocation) != 130 || s.InvScriptLength.Value != 130 || invocation[0] != 64 || invocation[65] != 64 {
+		t.Fatalf("invocation script %x", invocation)
+	}
+	hash := neoutils.Sha256(rawTx)
+	if !key3.Verify(hash, invocation[1:65]) || !key2.Verify(hash, invocation[66:130]) {
+		t.Fatal("signatures not in script order")
+	}
+
+	// 收集的签名多于 m 个时只使用前 m 个
+	if err = witness.Sign(key1, rawTx); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = witness.Script(); err != nil || len(s.InvocationScript) != 130 || !key3.Verify(hash, s.InvocationScript[1:65]) ||
+		!key1.Verify(hash, s.InvocationScript[66:130]) {
+		t.Fatalf("invocation script with 3 signatures %x %v", s.InvocationScript, err)
+	}
+}
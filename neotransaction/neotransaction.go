@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"errors"
+	"fmt"
 
 	"github.com/x-contract/neo-go-sdk/neoutils"
 )
@@ -41,13 +42,48 @@ func GetAssetSymbol(assetID string) string {
 // The type of NEO Transaction
 // Length = 1 byte
 const (
-	MinerTranscation     byte = 0x00 // 用于分配字节费的交易
-	IssueTransaction     byte = 0x01 // 用于分发资产的交易
-	ClaimTransaction     byte = 0x02 // 用于分配 NeoGas 的交易
-	ContractTransaction  byte = 0x80 // 合约交易，这是最常用的一种交易
-	InvocationTransacton byte = 0xd1 // 调用智能合约的特殊交易
+	MinerTranscation      byte = 0x00 // 用于分配字节费的交易
+	IssueTransaction      byte = 0x01 // 用于分发资产的交易
+	ClaimTransaction      byte = 0x02 // 用于分配 NeoGas 的交易
+	EnrollmentTransaction byte = 0x20 // 用于报名成为记账候选人的特殊交易
+	RegisterTransaction   byte = 0x40 // 用于资产登记的交易
+	ContractTransaction   byte = 0x80 // 合约交易，这是最常用的一种交易
+	StateTransaction      byte = 0x90 // 投票及记账人报名的状态交易
+	PublishTransaction    byte = 0xd0 // 发布智能合约的特殊交易（已弃用）
+	InvocationTransacton  byte = 0xd1 // 调用智能合约的特殊交易
 )
 
+var transactionTypeNames = map[byte]string{
+	MinerTranscation:      "MinerTransaction",
+	IssueTransaction:      "IssueTransaction",
+	ClaimTransaction:      "ClaimTransaction",
+	EnrollmentTransaction: "EnrollmentTransaction",
+	RegisterTransaction:   "RegisterTransaction",
+	ContractTransaction:   "ContractTransaction",
+	StateTransaction:      "StateTransaction",
+	PublishTransaction:    "PublishTransaction",
+	InvocationTransacton:  "InvocationTransaction",
+}
+
+// TransactionTypeName 返回交易类型的名称，与neo-cli中的类名一致
+func TransactionTypeName(txType byte) string {
+	name, ok := transactionTypeNames[txType]
+	if !ok {
+		return ``
+	}
+	return name
+}
+
+// ParseTransactionType 根据交易类型名称获取交易类型
+func ParseTransactionType(name string) (byte, bool) {
+	for txType, typeName := range transactionTypeNames {
+		if typeName == name {
+			return txType, true
+		}
+	}
+	return 0, false
+}
+
 // The type of NEO transaction attribute usage
 // Length = 1 byte
 const (
@@ -65,13 +101,49 @@ const (
 	//-0xff	Remark-Remark15
 )
 
+// ParseAttributeUsage 根据neo-cli json中的属性用途名称（如 Script、Remark1）获取属性用途
+func ParseAttributeUsage(name string) (byte, bool) {
+	switch name {
+	case `ContractHash`:
+		return UsageContractHash, true
+	case `ECDH02`:
+		return UsageECDH02, true
+	case `ECDH03`:
+		return UsageECDH03, true
+	case `Script`:
+		return UsageScript, true
+	case `Vote`:
+		return UsageVote, true
+	case `CertUrl`:
+		return UsageCertURL, true
+	case `DescriptionUrl`:
+		return UsageDescriptionURL, true
+	case `Description`:
+		return UsageDescription, true
+	case `Remark`:
+		return UsageRemark, true
+	}
+	var n int
+	if _, err := fmt.Sscanf(name, "Hash%d", &n); err == nil && n >= 1 && n <= 15 {
+		return UsageHash1 + byte(n-1), true
+	}
+	if _, err := fmt.Sscanf(name, "Remark%d", &n); err == nil && n >= 1 && n <= 15 {
+		return UsageRemark + byte(n), true
+	}
+	return 0, false
+}
+
 // TxOutputValueBase Neo交易金额的基数（定点数的小数位数)
 const TxOutputValueBase = int64(100000000)
 
+// TxOutputValueUnit 1个单位的资产对应的 Fixed8 金额，与 TxOutputValueBase 数值相同
+const TxOutputValueUnit = neoutils.Fixed8One
+
 // Attribute attribute of a NeoTransaction
 // 对于 ContractHash，ECDH 系列，Vote，Hash 系列，数据长度固定为 32 字节，length 字段省略
 // 对于 Script 固定为20个字节，并且是 Big-Endian 字节序
-// 对于 CertUrl，DescriptionUrl，Description，Remark 系列，必须明确给出数据长度，且长度不能超过 255
+// 对于 DescriptionUrl，Description，Remark 系列，必须明确给出数据长度，且长度不能超过 255
+// CertUrl 没有被节点实现，包含它的交易无法反序列化
 type Attribute struct {
 	Usage byte   // 用途
 	Data  []byte // 特定于用途的外部数据
@@ -86,7 +158,7 @@ type TxInput struct {
 // TxOutput output struct of a NeoTransaction
 type TxOutput struct {
 	AssetID    neoutils.HASH256 // 资产编号
-	Value      int64            // 金额 金额固定为 10e-8 单位
+	Value      neoutils.Fixed8  // 金额 金额固定为 10e-8 单位
 	ScriptHash neoutils.HASH160 // 收款地址
 }
 
@@ -107,19 +179,157 @@ type extraData interface {
 type InvocationExtraData struct {
 	ScriptLength neoutils.VarInt
 	Script       []byte
-	GasConsumed  int64
+	GasConsumed  neoutils.Fixed8
 }
 
-// Bytes ...
+// Bytes 返回版本号1以上的交易中的额外数据
 func (extra *InvocationExtraData) Bytes() []byte {
+	return extra.bytes(1)
+}
+
+func (extra *InvocationExtraData) bytes(version byte) []byte {
 	buff := new(bytes.Buffer)
 	extra.ScriptLength.Value = uint64(len(extra.Script))
 	buff.Write(extra.ScriptLength.Bytes())
 	buff.Write(extra.Script)
-	//neoutils.WriteUint64ToBuffer(buff, uint64(extra.GasConsumed))
+	// 版本号1以上的调用交易在脚本之后还需要写入消耗的GAS
+	if version >= 1 {
+		neoutils.WriteUint64ToBuffer(buff, uint64(extra.GasConsumed))
+	}
+	return buff.Bytes()
+}
+
+// MinerExtraData 挖矿交易的额外数据
+type MinerExtraData struct {
+	Nonce uint32
+}
+
+// Bytes ...
+func (extra *MinerExtraData) Bytes() []byte {
+	buff := new(bytes.Buffer)
+	neoutils.WriteUint32ToBuffer(buff, extra.Nonce)
+	return buff.Bytes()
+}
+
+// ClaimExtraData 提取GAS交易的额外数据，Claims 为用于提取GAS的已花费的NEO输出
+type ClaimExtraData struct {
+	ClaimsCount neoutils.VarInt
+	Claims      []TxInput
+}
+
+// Bytes ...
+func (extra *ClaimExtraData) Bytes() []byte {
+	buff := new(bytes.Buffer)
+	extra.ClaimsCount.Value = uint64(len(extra.Claims))
+	buff.Write(extra.ClaimsCount.Bytes())
+	for _, claim := range extra.Claims {
+		buff.Write(claim.PrevHash)
+		neoutils.WriteUint16ToBuffer(buff, claim.PrevIndex)
+	}
+	return buff.Bytes()
+}
+
+// RegisterExtraData 资产登记交易的额外数据（已弃用，仅用于解析链上的交易）
+type RegisterExtraData struct {
+	AssetType byte
+	Name      string          // 资产名称，一般为多语言名称的JSON数组
+	Amount    neoutils.Fixed8 // 总量，-0.00000001 表示不限量
+	Precision byte
+	Owner     []byte           // 所有者公钥，压缩格式；为 0x00 时表示无穷远点
+	Admin     neoutils.HASH160 // 管理员的ScriptHash
+}
+
+// Bytes ...
+func (extra *RegisterExtraData) Bytes() []byte {
+	buff := new(bytes.Buffer)
+	buff.WriteByte(extra.AssetType)
+	writeVarBytes(buff, []byte(extra.Name))
+	neoutils.WriteUint64ToBuffer(buff, uint64(extra.Amount))
+	buff.WriteByte(extra.Precision)
+	buff.Write(extra.Owner)
+	buff.Write(extra.Admin)
+	return buff.Bytes()
+}
+
+// EnrollmentExtraData 记账人报名交易的额外数据（已弃用，仅用于解析链上的交易）
+type EnrollmentExtraData struct {
+	PublicKey []byte // 压缩格式的公钥
+}
+
+// Bytes ...
+func (extra *EnrollmentExtraData) Bytes() []byte {
+	return extra.PublicKey
+}
+
+// StateDescriptor StateTransaction 中的一条状态描述，如投票（Account/Votes）或记账人报名（Validator/Registered）
+type StateDescriptor struct {
+	Type  byte // 0x40 Account，0x48 Validator
+	Key   []byte
+	Field string
+	Value []byte
+}
+
+// StateExtraData 状态交易的额外数据
+type StateExtraData struct {
+	Descriptors []StateDescriptor
+}
+
+// Bytes ...
+func (extra *StateExtraData) Bytes() []byte {
+	buff := new(bytes.Buffer)
+	buff.Write(neoutils.VarInt{Value: uint64(len(extra.Descriptors))}.Bytes())
+	for _, d := range extra.Descriptors {
+		buff.WriteByte(d.Type)
+		writeVarBytes(buff, d.Key)
+		writeVarBytes(buff, []byte(d.Field))
+		writeVarBytes(buff, d.Value)
+	}
 	return buff.Bytes()
 }
 
+// PublishExtraData 发布智能合约交易的额外数据（已弃用，仅用于解析链上的交易）
+// NeedStorage 只在版本号1以上的交易中存在
+type PublishExtraData struct {
+	Script        []byte
+	ParameterList []byte // 参数类型
+	ReturnType    byte
+	NeedStorage   bool
+	Name          string
+	CodeVersion   string
+	Author        string
+	Email         string
+	Description   string
+}
+
+// Bytes 返回版本号1以上的交易中的额外数据
+func (extra *PublishExtraData) Bytes() []byte {
+	return extra.bytes(1)
+}
+
+func (extra *PublishExtraData) bytes(version byte) []byte {
+	buff := new(bytes.Buffer)
+	writeVarBytes(buff, extra.Script)
+	writeVarBytes(buff, extra.ParameterList)
+	buff.WriteByte(extra.ReturnType)
+	if version >= 1 {
+		if extra.NeedStorage {
+			buff.WriteByte(1)
+		} else {
+			buff.WriteByte(0)
+		}
+	}
+	for _, str := range []string{extra.Name, extra.CodeVersion, extra.Author, extra.Email, extra.Description} {
+		writeVarBytes(buff, []byte(str))
+	}
+	return buff.Bytes()
+}
+
+// writeVarBytes 写入以VarInt表示长度的字节数组
+func writeVarBytes(buff *bytes.Buffer, data []byte) {
+	buff.Write(neoutils.VarInt{Value: uint64(len(data))}.Bytes())
+	buff.Write(data)
+}
+
 // NeoTransaction struct
 type NeoTransaction struct {
 	Type    byte
@@ -174,7 +384,7 @@ func (tx *NeoTransaction) AppendInputByTxHash(transactionID string, index uint16
 }
 
 // AppendOutputByAddrString 向交易添加一笔输出
-func (tx *NeoTransaction) AppendOutputByAddrString(address string, assetID string, count int64) error {
+func (tx *NeoTransaction) AppendOutputByAddrString(address string, assetID string, count neoutils.Fixed8) error {
 
 	addr, err := ParseAddress(address)
 	if err != nil {
@@ -197,7 +407,7 @@ func (tx *NeoTransaction) AppendOutputByAddrString(address string, assetID strin
 }
 
 // AppendOutput 向交易添加一笔输出
-func (tx *NeoTransaction) AppendOutput(addr *Address, assetHash neoutils.HASH256, count int64) error {
+func (tx *NeoTransaction) AppendOutput(addr *Address, assetHash neoutils.HASH256, count neoutils.Fixed8) error {
 
 	if !assetHash.IsValid() {
 		return errors.New("NeoTransaction.AppendOutput invalid assetHash")
@@ -216,16 +426,24 @@ func (tx *NeoTransaction) UnsignedRawTransaction() []byte {
 	buff := new(bytes.Buffer)
 	buff.WriteByte(tx.Type)
 	buff.WriteByte(tx.Version)
-	if tx.ExtraData != nil {
-		buff.Write(tx.ExtraData.Bytes())
+	switch extra := tx.ExtraData.(type) {
+	case *PublishExtraData:
+		buff.Write(extra.bytes(tx.Version))
+	case *InvocationExtraData:
+		buff.Write(extra.bytes(tx.Version))
+	case nil:
+	default:
+		buff.Write(extra.Bytes())
 	}
 
 	tx.AttributeCount.Value = uint64(len(tx.Attributes))
 	buff.Write(tx.AttributeCount.Bytes())
 	for _, attr := range tx.Attributes {
 		buff.WriteByte(attr.Usage)
-		if attr.Usage == UsageCertURL || attr.Usage == UsageDescription || attr.Usage == UsageDescriptionURL || attr.Usage >= UsageRemark {
+		if attr.Usage == UsageCertURL || attr.Usage == UsageDescriptionURL {
 			buff.WriteByte(byte(len(attr.Data)))
+		} else if attr.Usage == UsageDescription || attr.Usage >= UsageRemark {
+			buff.Write(neoutils.VarInt{Value: uint64(len(attr.Data))}.Bytes())
 		}
 		buff.Write(attr.Data)
 	}
@@ -261,6 +479,7 @@ func (tx *NeoTransaction) TXID() string {
 // AppendWitness 向交易添加一个鉴证人。一个独立的鉴证人有一个鉴证人脚本，包括一个压栈脚本和一个鉴权脚本
 func (tx *NeoTransaction) AppendWitness(witness *Script) {
 	tx.Scripts = append(tx.Scripts, *witness)
+	tx.witness = nil
 }
 
 // AppendBasicSignWitness 向交易添加一个基本签名账户的鉴证人脚本，压栈脚本为一条将签名压栈的指令，
@@ -308,6 +527,24 @@ func CreateContractTransaction() *NeoTransaction {
 	return tx
 }
 
+// CreateClaimTransaction 创建一个提取GAS交易，将 claims 中的NEO输出产生的 amount 个GAS提取到 addr
+// claims 中的输出必须已经被花费，amount 必须与节点计算的可提取数量一致
+func CreateClaimTransaction(claims []TxInput, addr *Address, amount neoutils.Fixed8) (*NeoTransaction, error) {
+	if len(claims) == 0 {
+		return nil, errors.New("CreateClaimTransaction no claims")
+	}
+	tx := &NeoTransaction{
+		Type:      ClaimTransaction,
+		ExtraData: &ClaimExtraData{Claims: claims},
+		dirty:     true,
+	}
+	gasID, _ := neoutils.ParseHASH256(AssetGasID)
+	if err := tx.AppendOutput(addr, gasID, amount); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
 // CreateInvocationTransaction 创建一个调用交易（调用只能合约）
 func CreateInvocationTransaction() *NeoTransaction {
 	tx := &NeoTransaction{
@@ -0,0 +1,128 @@
+package neotransaction
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ScryptParams NEP-2 加密使用的scrypt参数
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// DefaultScryptParams NEP-2 标准规定的默认scrypt参数
+var DefaultScryptParams = ScryptParams{N: 16384, R: 8, P: 8}
+
+// NEP-2 加密私钥的前缀
+var nep2Prefix = []byte{0x01, 0x42, 0xe0}
+
+// nep2Passphrase NEP-2 标准规定密码在计算scrypt之前需要进行 NFC 规范化
+func nep2Passphrase(passphrase string) []byte {
+	return []byte(norm.NFC.String(passphrase))
+}
+
+// EncodeNep2 使用密码 passphrase 将私钥加密为NEP-2格式的字符串（以6P开头）
+func (key *KeyPair) EncodeNep2(passphrase string, params ScryptParams) (string, error) {
+	if !key.HasPrivKey() {
+		return "", errors.New("EncodeNep2 the KeyPair does not contain private key")
+	}
+	addressHash := nep2AddressHash(key)
+	derived, err := scrypt.Key(nep2Passphrase(passphrase), addressHash, params.N, params.R, params.P, 64)
+	if err != nil {
+		return "", err
+	}
+
+	priv := key.D.FillBytes(make([]byte, 32))
+	xored := xorBytes(priv, derived[:32])
+	encrypted, err := aesEncryptECB(xored, derived[32:])
+	if err != nil {
+		return "", err
+	}
+
+	buff := new(bytes.Buffer)
+	buff.Write(nep2Prefix)
+	buff.Write(addressHash)
+	buff.Write(encrypted)
+	nep2, _ := neoutils.EncodeBase58WithChecksum(buff.Bytes())
+	return string(nep2), nil
+}
+
+// DecodeFromNep2 使用密码 passphrase 解密NEP-2格式的私钥字符串，得到公私钥对
+func DecodeFromNep2(nep2 string, passphrase string, params ScryptParams) (*KeyPair, error) {
+	data, ok := neoutils.DecodeBase58WithChecksum([]byte(nep2))
+	if !ok {
+		return nil, errors.New("DecodeFromNep2 checksum failed")
+	}
+	if len(data) != 39 || !bytes.Equal(data[:3], nep2Prefix) {
+		return nil, errors.New("DecodeFromNep2 invalid nep2 string")
+	}
+
+	addressHash := data[3:7]
+	derived, err := scrypt.Key(nep2Passphrase(passphrase), addressHash, params.N, params.R, params.P, 64)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := aesDecryptECB(data[7:], derived[32:])
+	if err != nil {
+		return nil, err
+	}
+	priv := xorBytes(decrypted, derived[:32])
+
+	key := new(KeyPair)
+	key.PublicKey.Curve = elliptic.P256()
+	key.D = new(big.Int).SetBytes(priv)
+	key.PublicKey.X, key.PublicKey.Y = key.PublicKey.Curve.ScalarBaseMult(priv)
+	if !bytes.Equal(nep2AddressHash(key), addressHash) {
+		return nil, errors.New("DecodeFromNep2 wrong passphrase")
+	}
+	return key, nil
+}
+
+// nep2AddressHash 私钥对应基本账户地址字符串的HASH256的前4个字节
+func nep2AddressHash(key *KeyPair) []byte {
+	addr := key.CreateBasicAddress()
+	return neoutils.Hash256([]byte(addr.Addr))[:4]
+}
+
+func xorBytes(a, b []byte) []byte {
+	ret := make([]byte, len(a))
+	for i := range a {
+		ret[i] = a[i] ^ b[i]
+	}
+	return ret
+}
+
+// aesEncryptECB 使用AES-256 ECB模式加密，数据长度必须是16的整数倍
+func aesEncryptECB(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Encrypt(ret[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return ret, nil
+}
+
+// aesDecryptECB 使用AES-256 ECB模式解密，数据长度必须是16的整数倍
+func aesDecryptECB(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(ret[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return ret, nil
+}
@@ -0,0 +1,58 @@
+package neotransaction
+
+import (
+	"testing"
+)
+
+// NEP-2 标准中的测试向量
+const (
+	nep2TestWif        = "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP"
+	nep2TestPassphrase = "TestingOneTwoThree"
+	nep2TestEncrypted  = "6PYVPVe1fQznphjbUxXP9KZJqPMVnVwCx5s5pr5axRJ8uHkMtZg97eT5kL"
+)
+
+func TestNep2SpecVector(t *testing.T) {
+	key, err := DecodeFromWif(nep2TestWif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := key.EncodeNep2(nep2TestPassphrase, DefaultScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted != nep2TestEncrypted {
+		t.Fatalf("EncodeNep2 = %v, want %v", encrypted, nep2TestEncrypted)
+	}
+	decoded, err := DecodeFromNep2(nep2TestEncrypted, nep2TestPassphrase, DefaultScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.EncodeWif() != nep2TestWif {
+		t.Fatalf("DecodeFromNep2 = %v, want %v", decoded.EncodeWif(), nep2TestWif)
+	}
+	if _, err = DecodeFromNep2(nep2TestEncrypted, "wrong", DefaultScryptParams); err == nil {
+		t.Fatal("DecodeFromNep2 with wrong passphrase succeeded")
+	}
+}
+
+func TestNep2PassphraseNFC(t *testing.T) {
+	params := ScryptParams{N: 1024, R: 8, P: 1}
+	key, _ := DecodeFromWif(nep2TestWif)
+	composed := "caf\u00e9\u03d3"          // NFC
+	decomposed := "cafe\u0301\u03d2\u0301" // NFD
+
+	encrypted, err := key.EncodeNep2(composed, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := key.EncodeNep2(decomposed, params); other != encrypted {
+		t.Fatalf("passphrases in different normalization forms encrypt to %v and %v", encrypted, other)
+	}
+	decoded, err := DecodeFromNep2(encrypted, decomposed, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.EncodeWif() != nep2TestWif {
+		t.Fatalf("DecodeFromNep2 = %v, want %v", decoded.EncodeWif(), nep2TestWif)
+	}
+}
@@ -81,8 +81,16 @@ func (sb *ScriptBuilder) EmitPushNumber(arg int64) {
 		sb.Emit(OpCode.PUSH1 - 1 + OpCode.OPCODE(arg))
 		return
 	}
-	bytes := neoutils.Reverse(big.NewInt(arg).Bytes())
-	sb.EmitPushBytes(bytes)
+	sb.EmitPushBytes(neoutils.BigIntToBytes(big.NewInt(arg)))
+}
+
+// EmitPushBigInt 在脚本构建器中加入一条压栈大整数的指令，用于超出 int64 范围的数字，如NEP-5资产的金额
+func (sb *ScriptBuilder) EmitPushBigInt(arg *big.Int) {
+	if arg.IsInt64() {
+		sb.EmitPushNumber(arg.Int64())
+		return
+	}
+	sb.EmitPushBytes(neoutils.BigIntToBytes(arg))
 }
 
 // EmitPushString 在脚本构建器中加入一条压栈字符串的指令，压栈字符串实际上是压栈字节数组
@@ -90,7 +98,7 @@ func (sb *ScriptBuilder) EmitPushString(arg string) {
 	sb.EmitPushBytes([]byte(arg))
 }
 
-// EmitPushArray 在脚本构建器中加入一条压栈数组的指令，数组的元素可以是 HASH256 HASH160 string []byte number bool
+// EmitPushArray 在脚本构建器中加入一条压栈数组的指令，数组的元素可以是 HASH256 HASH160 string []byte number *big.Int bool
 // 将数组压栈需要将数组元素按照从右至左压入栈中，然后压入数组长度，最后压栈 Pack 指令
 func (sb *ScriptBuilder) EmitPushArray(arg []interface{}) error {
 	for i := len(arg) - 1; i >= 0; i-- {
@@ -116,6 +124,8 @@ func (sb *ScriptBuilder) EmitPushArray(arg []interface{}) error {
 			sb.EmitPushNumber(int64(v))
 		case byte:
 			sb.EmitPushNumber(int64(v))
+		case *big.Int:
+			sb.EmitPushBigInt(v)
 		case string:
 			sb.EmitPushString(v)
 		case bool:
@@ -215,6 +225,8 @@ func BuildCallMethodScript(contractHash neoutils.HASH160, method string, args []
 			sb.EmitPushNumber(int64(v))
 		case byte:
 			sb.EmitPushNumber(int64(v))
+		case *big.Int:
+			sb.EmitPushBigInt(v)
 		case string:
 			sb.EmitPushString(v)
 		case bool:
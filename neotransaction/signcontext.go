@@ -0,0 +1,478 @@
+package neotransaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction/OpCode"
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// 合约参数类型，与neo-cli的ContractParameterType名称一致
+const (
+	ParamSignature = "Signature"
+	ParamBoolean   = "Boolean"
+	ParamInteger   = "Integer"
+	ParamHash160   = "Hash160"
+	ParamHash256   = "Hash256"
+	ParamByteArray = "ByteArray"
+	ParamPublicKey = "PublicKey"
+	ParamString    = "String"
+	ParamArray     = "Array"
+)
+
+// ContractParameter 鉴证人压栈脚本中使用的合约参数
+// Value 的格式与neo-cli的json格式一致：Signature ByteArray PublicKey 为十六进制字符串，
+// Integer 为十进制字符串，Hash160 Hash256 为0x开头的大端序十六进制字符串，Boolean 为bool，Array 为[]ContractParameter
+type ContractParameter struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// UnmarshalJSON 解析合约参数，Array 类型的值解析为 []ContractParameter
+func (param *ContractParameter) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	param.Type = raw.Type
+	param.Value = nil
+	if len(raw.Value) == 0 || string(raw.Value) == "null" {
+		return nil
+	}
+	if raw.Type == ParamArray {
+		items := []ContractParameter{}
+		if err := json.Unmarshal(raw.Value, &items); err != nil {
+			return err
+		}
+		param.Value = items
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw.Value))
+	dec.UseNumber()
+	return dec.Decode(&param.Value)
+}
+
+// ContextItem 签名上下文中一个鉴证人（ScriptHash）对应的数据
+type ContextItem struct {
+	Script     []byte
+	Parameters []ContractParameter
+	Signatures map[string][]byte // 多签账户收集中的签名，公钥的十六进制字符串 -> 签名，收集满后填入参数并清空
+}
+
+type contextItemJSON struct {
+	Script     *string             `json:"script"`
+	Parameters []ContractParameter `json:"parameters"`
+	Signatures map[string]string   `json:"signatures,omitempty"`
+}
+
+// SigningContext 离线签名上下文，与neo-cli的ContractParametersContext兼容
+// 可以导出为json交给其它签名方（或neo-cli的sign命令）继续签名，签名完成后生成鉴证人脚本
+type SigningContext struct {
+	Tx           *NeoTransaction
+	ScriptHashes []neoutils.HASH160 // 需要鉴证的ScriptHash，按neo-cli的规则排序
+	Items        map[string]*ContextItem
+}
+
+// CompareHash160 按照neo中UInt160的规则比较两个ScriptHash的大小（从最高位字节开始比较）
+func CompareHash160(a, b neoutils.HASH160) int {
+	return bytes.Compare(neoutils.Reverse(a), neoutils.Reverse(b))
+}
+
+// ScriptHashesForVerifying 返回交易需要鉴证的ScriptHash列表，已排序且去重
+// utxos 是交易输入所引用的输出（对于提取GAS交易还包括 Claims 引用的输出），每一个引用都必须能在 utxos 中找到；
+// 另外包含所有 UsageScript 类型属性中的ScriptHash
+func (tx *NeoTransaction) ScriptHashesForVerifying(utxos []*UTXO) ([]neoutils.HASH160, error) {
+	hashes := make(map[string]neoutils.HASH160)
+	references := tx.Inputs
+	if extra, ok := tx.ExtraData.(*ClaimExtraData); ok {
+		references = append(append([]TxInput{}, tx.Inputs...), extra.Claims...)
+	}
+	for _, input := range references {
+		found := false
+		for _, utxo := range utxos {
+			if utxo.Index == input.PrevIndex && bytes.Equal(utxo.TxHash, input.PrevHash) {
+				hashes[hex.EncodeToString(utxo.ScriptHash)] = utxo.ScriptHash
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("ScriptHashesForVerifying input %s:%v not found in utxos",
+				neoutils.HASH256(input.PrevHash).ToHexString(), input.PrevIndex)
+		}
+	}
+	for _, attr := range tx.Attributes {
+		if attr.Usage == UsageScript {
+			hashes[hex.EncodeToString(attr.Data)] = neoutils.HASH160(attr.Data)
+		}
+	}
+	ret := make([]neoutils.HASH160, 0, len(hashes))
+	for _, hash := range hashes {
+		ret = append(ret, hash)
+	}
+	sortHash160(ret)
+	return ret, nil
+}
+
+func sortHash160(hashes []neoutils.HASH160) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return CompareHash160(hashes[i], hashes[j]) < 0
+	})
+}
+
+// NewSigningContext 创建一个签名上下文，scriptHashes 为需要鉴证的ScriptHash
+func NewSigningContext(tx *NeoTransaction, scriptHashes []neoutils.HASH160) *SigningContext {
+	hashes := make([]neoutils.HASH160, len(scriptHashes))
+	copy(hashes, scriptHashes)
+	sortHash160(hashes)
+	return &SigningContext{
+		Tx:           tx,
+		ScriptHashes: hashes,
+		Items:        make(map[string]*ContextItem),
+	}
+}
+
+func (ctx *SigningContext) contains(scriptHash neoutils.HASH160) bool {
+	for _, hash := range ctx.ScriptHashes {
+		if bytes.Equal(hash, scriptHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddContract 为一个鉴证人设置鉴权脚本，基本账户和多签账户会自动生成签名参数列表
+func (ctx *SigningContext) AddContract(addr *Address) error {
+	if !addr.HaveScript() {
+		return errors.New("SigningContext.AddContract address has no script")
+	}
+	if !ctx.contains(addr.ScripHash) {
+		return fmt.Errorf("SigningContext.AddContract script hash %s not required", addr.ScripHash.ToHexString())
+	}
+	key := hex.EncodeToString(addr.ScripHash)
+	if item, ok := ctx.Items[key]; ok && item.Script != nil {
+		return nil
+	}
+
+	count := 0
+	if IsBasicVerifyScript(addr.Script) {
+		count = 1
+	} else if m, _, ok := ParseMultiSigVerifyScript(addr.Script); ok {
+		count = m
+	}
+	item := &ContextItem{Script: addr.Script, Parameters: make([]ContractParameter, count)}
+	for i := range item.Parameters {
+		item.Parameters[i].Type = ParamSignature
+	}
+	ctx.Items[key] = item
+	return nil
+}
+
+// AddParameter 为非标准合约的鉴证人设置第 index 个参数
+func (ctx *SigningContext) AddParameter(scriptHash neoutils.HASH160, index int, param ContractParameter) error {
+	item, ok := ctx.Items[hex.EncodeToString(scriptHash)]
+	if !ok {
+		return fmt.Errorf("SigningContext.AddParameter script hash %s not added", scriptHash.ToHexString())
+	}
+	if index < 0 || index >= len(item.Parameters) {
+		return fmt.Errorf("SigningContext.AddParameter index %v out of range", index)
+	}
+	item.Parameters[index] = param
+	return nil
+}
+
+// AddSignature 向鉴权脚本为 script 的鉴证人加入一个签名
+// 对于多签账户，签名会先收集起来，收集满 m 个之后按公钥顺序填入参数列表
+func (ctx *SigningContext) AddSignature(script []byte, pubKey []byte, signature []byte) error {
+	addr, err := CreateAddressByScript(script)
+	if err != nil {
+		return err
+	}
+	if err = ctx.AddContract(addr); err != nil {
+		return err
+	}
+	item := ctx.Items[hex.EncodeToString(addr.ScripHash)]
+
+	if IsBasicVerifyScript(script) {
+		if !bytes.Equal(script[1:34], pubKey) {
+			return errors.New("SigningContext.AddSignature public key not match the script")
+		}
+		item.Parameters[0].Value = hex.EncodeToString(signature)
+		return nil
+	}
+
+	m, pubKeys, ok := ParseMultiSigVerifyScript(script)
+	if !ok {
+		return errors.New("SigningContext.AddSignature script is neither basic nor multi-sig")
+	}
+	index := -1
+	for i, key := range pubKeys {
+		if bytes.Equal(key, pubKey) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return errors.New("SigningContext.AddSignature public key not in multi-sig script")
+	}
+	if item.completed() {
+		return nil
+	}
+	if item.Signatures == nil {
+		item.Signatures = make(map[string][]byte)
+	}
+	item.Signatures[hex.EncodeToString(pubKey)] = signature
+	if len(item.Signatures) < m {
+		return nil
+	}
+
+	// 与neo-cli一致，按公钥序号从大到小填入参数，生成脚本时逆序压栈
+	k := 0
+	for i := len(pubKeys) - 1; i >= 0; i-- {
+		if sig, ok := item.Signatures[hex.EncodeToString(pubKeys[i])]; ok {
+			item.Parameters[k].Value = hex.EncodeToString(sig)
+			k++
+		}
+	}
+	// 与neo-cli一致，签名已经填入参数，不再导出
+	item.Signatures = nil
+	return nil
+}
+
+// Sign 使用 key 对所有包含此公钥的鉴证人签名，返回是否至少签了一个
+func (ctx *SigningContext) Sign(key *KeyPair) (bool, error) {
+	pubKey := key.EncodePubkeyCompressed()
+	signed := false
+	for _, hash := range ctx.ScriptHashes {
+		item, ok := ctx.Items[hex.EncodeToString(hash)]
+		if !ok || item.Script == nil {
+			continue
+		}
+		if !bytes.Contains(item.Script, pubKey) {
+			continue
+		}
+		signature, err := key.Sign(neoutils.Sha256(ctx.Tx.UnsignedRawTransaction()))
+		if err != nil {
+			return signed, err
+		}
+		if err = ctx.AddSignature(item.Script, pubKey, signature); err != nil {
+			return signed, err
+		}
+		signed = true
+	}
+	return signed, nil
+}
+
+// Completed 判断所有鉴证人的参数是否都已经填写完整
+func (ctx *SigningContext) Completed() bool {
+	for _, hash := range ctx.ScriptHashes {
+		item, ok := ctx.Items[hex.EncodeToString(hash)]
+		if !ok {
+			return false
+		}
+		if !item.completed() {
+			return false
+		}
+	}
+	return true
+}
+
+// completed 判断鉴证人的参数是否都已经填写完整
+func (item *ContextItem) completed() bool {
+	for _, param := range item.Parameters {
+		if param.Value == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Witnesses 生成所有鉴证人脚本，按ScriptHash排序
+func (ctx *SigningContext) Witnesses() ([]Script, error) {
+	if !ctx.Completed() {
+		return nil, errors.New("SigningContext.Witnesses context not completed")
+	}
+	scripts := make([]Script, 0, len(ctx.ScriptHashes))
+	for _, hash := range ctx.ScriptHashes {
+		item := ctx.Items[hex.EncodeToString(hash)]
+		sb := ScriptBuilder{}
+		for i := len(item.Parameters) - 1; i >= 0; i-- {
+			if err := sb.emitContractParameter(item.Parameters[i]); err != nil {
+				return nil, err
+			}
+		}
+		script := Script{InvocationScript: sb.Bytes(), VerificationScript: item.Script}
+		if script.VerificationScript == nil {
+			script.VerificationScript = []byte{}
+		}
+		script.InvScriptLength.Value = uint64(len(script.InvocationScript))
+		script.VrifScriptLength.Value = uint64(len(script.VerificationScript))
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// ApplyWitnesses 生成鉴证人脚本并替换交易中已有的鉴证人
+func (ctx *SigningContext) ApplyWitnesses() error {
+	scripts, err := ctx.Witnesses()
+	if err != nil {
+		return err
+	}
+	ctx.Tx.Scripts = scripts
+	ctx.Tx.witness = nil
+	return nil
+}
+
+// emitContractParameter 在脚本构建器中压栈一个合约参数
+func (sb *ScriptBuilder) emitContractParameter(param ContractParameter) error {
+	switch param.Type {
+	case ParamSignature, ParamByteArray, ParamPublicKey:
+		s, _ := param.Value.(string)
+		data, err := hex.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("emitContractParameter %s value invalid: %v", param.Type, err)
+		}
+		sb.EmitPushBytes(data)
+	case ParamBoolean:
+		v, ok := param.Value.(bool)
+		if !ok {
+			return fmt.Errorf("emitContractParameter Boolean value[%v] invalid", param.Value)
+		}
+		sb.EmitPushBool(v)
+	case ParamInteger:
+		v, ok := new(big.Int).SetString(fmt.Sprint(param.Value), 10)
+		if !ok {
+			return fmt.Errorf("emitContractParameter Integer value[%v] invalid", param.Value)
+		}
+		if v.IsInt64() {
+			sb.EmitPushNumber(v.Int64())
+		} else {
+			sb.EmitPushBytes(neoutils.BigIntToBytes(v))
+		}
+	case ParamHash160, ParamHash256:
+		s, _ := param.Value.(string)
+		data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return fmt.Errorf("emitContractParameter %s value invalid: %v", param.Type, err)
+		}
+		sb.EmitPushBytes(neoutils.Reverse(data))
+	case ParamString:
+		s, _ := param.Value.(string)
+		sb.EmitPushString(s)
+	case ParamArray:
+		items, _ := param.Value.([]ContractParameter)
+		for i := len(items) - 1; i >= 0; i-- {
+			if err := sb.emitContractParameter(items[i]); err != nil {
+				return err
+			}
+		}
+		sb.EmitPushNumber(int64(len(items)))
+		sb.Emit(OpCode.PACK)
+	default:
+		return fmt.Errorf("emitContractParameter type[%s] not supported", param.Type)
+	}
+	return nil
+}
+
+// transactionTypeName 返回neo-cli中交易类型的完整类名
+func transactionTypeName(txType byte) string {
+	name := TransactionTypeName(txType)
+	if name == `` {
+		name = "Transaction"
+	}
+	return "Neo.Network.P2P.Payloads." + name
+}
+
+// MarshalJSON 将签名上下文导出为neo-cli的ContractParametersContext json格式
+func (ctx *SigningContext) MarshalJSON() ([]byte, error) {
+	items := make(map[string]*contextItemJSON)
+	for _, hash := range ctx.ScriptHashes {
+		item := &contextItemJSON{Parameters: []ContractParameter{}}
+		if ctxItem, ok := ctx.Items[hex.EncodeToString(hash)]; ok {
+			if ctxItem.Script != nil {
+				script := hex.EncodeToString(ctxItem.Script)
+				item.Script = &script
+			}
+			item.Parameters = ctxItem.Parameters
+			if ctxItem.Signatures != nil {
+				item.Signatures = make(map[string]string)
+				for pubKey, sig := range ctxItem.Signatures {
+					item.Signatures[pubKey] = hex.EncodeToString(sig)
+				}
+			}
+		}
+		items["0x"+hash.ToHexString()] = item
+	}
+	return json.Marshal(&struct {
+		Type  string                      `json:"type"`
+		Hex   string                      `json:"hex"`
+		Items map[string]*contextItemJSON `json:"items"`
+	}{
+		Type:  transactionTypeName(ctx.Tx.Type),
+		Hex:   hex.EncodeToString(ctx.Tx.UnsignedRawTransaction()),
+		Items: items,
+	})
+}
+
+// UnmarshalJSON 从neo-cli的ContractParametersContext json格式导入签名上下文
+func (ctx *SigningContext) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Type  string                      `json:"type"`
+		Hex   string                      `json:"hex"`
+		Items map[string]*contextItemJSON `json:"items"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	tx, err := DecodeTransactionString(raw.Hex)
+	if err != nil {
+		return err
+	}
+	if len(tx.Scripts) != 0 {
+		return errors.New("SigningContext.UnmarshalJSON hex should be unsigned transaction")
+	}
+
+	ctx.Tx = tx
+	ctx.ScriptHashes = make([]neoutils.HASH160, 0, len(raw.Items))
+	ctx.Items = make(map[string]*ContextItem)
+	for hashString, rawItem := range raw.Items {
+		hash, err := neoutils.ParseHASH160(hashString)
+		if err != nil {
+			return fmt.Errorf("SigningContext.UnmarshalJSON script hash %s invalid: %v", hashString, err)
+		}
+		ctx.ScriptHashes = append(ctx.ScriptHashes, hash)
+		if rawItem == nil {
+			continue
+		}
+		item := &ContextItem{Parameters: rawItem.Parameters}
+		if rawItem.Script != nil {
+			if item.Script, err = hex.DecodeString(*rawItem.Script); err != nil {
+				return fmt.Errorf("SigningContext.UnmarshalJSON script of %s invalid: %v", hashString, err)
+			}
+		}
+		if rawItem.Signatures != nil {
+			item.Signatures = make(map[string][]byte)
+			for pubKey, sig := range rawItem.Signatures {
+				if item.Signatures[pubKey], err = hex.DecodeString(sig); err != nil {
+					return fmt.Errorf("SigningContext.UnmarshalJSON signature of %s invalid: %v", pubKey, err)
+				}
+			}
+		}
+		if item.completed() {
+			item.Signatures = nil
+		}
+		ctx.Items[hex.EncodeToString(hash)] = item
+	}
+	sortHash160(ctx.ScriptHashes)
+	return nil
+}
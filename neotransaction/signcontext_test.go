@@ -0,0 +1,96 @@
+package neotransaction
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// partialContext 是按 neo-cli 2.x 的 sign 命令导出的 ContractParametersContext 格式手工构造的，不是 neo-cli 导出的文件：
+// type 为交易类型的完整类名，hex 为未签名的交易，items 以 0x 加大端脚本哈希为键，
+// 未签名的参数不输出 value，收集中的签名以公钥为键放在 signatures 中。
+// 交易将私钥1、2、3的2-3多签账户中的1 NEO转出，已由私钥2签名
+const partialContext = `{"type":"Neo.Network.P2P.Payloads.ContractTransaction","hex":"8000000101000000000000000000000000000000000000000000000000000000000000000000019b7cffdaa674beae0f930ebe6085af9093e5fe56b34a5c220ccdcf6efc336fc500e1f5050000000066390a342e73b750424b4c41c2108cdb40153aa1","items":{"0xdb8f863c3e572126812325f1e1974c3a41748f57":{"script":"5221025ecbe4d1a6330a44c8f7ef951d4bf165e6c6b721efada985fb41661bc6e7fd6c21036b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c29621037cf27b188d034f7e8a52380304b51ac3c08969e277f21b35a60b48fc4766997853ae","parameters":[{"type":"Signature"},{"type":"Signature"}],"signatures":{"037cf27b188d034f7e8a52380304b51ac3c08969e277f21b35a60b48fc47669978":"d229cccbd6419d5495538733ec76f582f073e2b73d0829c2dbe3256776875fa6658921faa2020057603df6986ffb8b92af7f65102e26ae9c59db7b81bae41a7a"}}}}`
+
+func TestSigningContextSignExport(t *testing.T) {
+	ctx := &SigningContext{}
+	if err := json.Unmarshal([]byte(partialContext), ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Completed() {
+		t.Fatal("context with one of two signatures completed")
+	}
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != partialContext {
+		t.Fatalf("re-exported context\n%s\nwant\n%s", data, partialContext)
+	}
+
+	if signed, err := ctx.Sign(keyPairFromInt(1)); !signed || err != nil {
+		t.Fatalf("sign %v %v", signed, err)
+	}
+	if !ctx.Completed() {
+		t.Fatal("context not completed")
+	}
+	data, err = json.Marshal(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 签名完成后neo-cli不再导出 signatures
+	if strings.Contains(string(data), `"signatures"`) {
+		t.Fatalf("completed context exports signatures: %s", data)
+	}
+	relayed := &SigningContext{}
+	if err = json.Unmarshal(data, relayed); err != nil {
+		t.Fatal(err)
+	}
+	if err = relayed.ApplyWitnesses(); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := DecodeTransaction(relayed.Tx.RawTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Scripts) != 1 {
+		t.Fatalf("witnesses count %v", len(tx.Scripts))
+	}
+	m, pubKeys, ok := ParseMultiSigVerifyScript(tx.Scripts[0].VerificationScript)
+	if !ok || m != 2 || len(pubKeys) != 3 {
+		t.Fatal("verification script is not the 2/3 multi-sig script")
+	}
+	// 压栈脚本按公钥顺序依次压入私钥1、私钥2的签名
+	inv := tx.Scripts[0].InvocationScript
+	if len(inv) != 130 || inv[0] != 0x40 || inv[65] != 0x40 {
+		t.Fatalf("invocation script %x", inv)
+	}
+	hash := neoutils.Sha256(tx.UnsignedRawTransaction())
+	if !keyPairFromInt(1).Verify(hash, inv[1:65]) || !keyPairFromInt(2).Verify(hash, inv[66:130]) {
+		t.Fatal("witness signatures verify failed")
+	}
+}
+
+// TestSigningContextImportCompleted 兼容之前导出的、签名完成后仍带有 signatures 的上下文
+func TestSigningContextImportCompleted(t *testing.T) {
+	ctx := &SigningContext{}
+	if err := json.Unmarshal([]byte(partialContext), ctx); err != nil {
+		t.Fatal(err)
+	}
+	ctx.Sign(keyPairFromInt(3))
+	for _, item := range ctx.Items {
+		item.Signatures = map[string][]byte{"00": {0}}
+	}
+	data, _ := json.Marshal(ctx)
+	imported := &SigningContext{}
+	if err := json.Unmarshal(data, imported); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(imported)
+	if strings.Contains(string(data), `"signatures"`) {
+		t.Fatalf("completed context exports signatures: %s", data)
+	}
+}
@@ -0,0 +1,310 @@
+package neotransaction
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// SelectStrategy UTXO选择策略，从同一种资产的 utxos 中选出总额不少于 amount 的一组输入
+// 余额不足时返回nil
+type SelectStrategy func(utxos []*UTXO, amount neoutils.Fixed8) []*UTXO
+
+// exactMatchMaxSteps ExactMatch策略搜索组合的最大步数，超过之后放弃精确匹配
+const exactMatchMaxSteps = 100000
+
+func sortUTXOs(utxos []*UTXO, desc bool) []*UTXO {
+	sorted := make([]*UTXO, len(utxos))
+	copy(sorted, utxos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if desc {
+			return sorted[i].Value > sorted[j].Value
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+// takeUntil 按顺序选择UTXO直到总额不小于 amount，总额不足或超出 Fixed8 的范围时返回nil
+func takeUntil(sorted []*UTXO, amount neoutils.Fixed8) []*UTXO {
+	sum := neoutils.Fixed8(0)
+	for i, utxo := range sorted {
+		var err error
+		if sum, err = sum.Add(utxo.Value); err != nil {
+			return nil
+		}
+		if sum >= amount {
+			return sorted[:i+1]
+		}
+	}
+	return nil
+}
+
+// SelectLargestFirst 优先选择金额最大的UTXO
+func SelectLargestFirst(utxos []*UTXO, amount neoutils.Fixed8) []*UTXO {
+	return takeUntil(sortUTXOs(utxos, true), amount)
+}
+
+// SelectSmallestFirst 优先选择金额最小的UTXO，可以用于归集零钱
+func SelectSmallestFirst(utxos []*UTXO, amount neoutils.Fixed8) []*UTXO {
+	return takeUntil(sortUTXOs(utxos, false), amount)
+}
+
+// SelectFewestInputs 使用尽量少的输入，如果单个UTXO就足够则选择满足条件的最小的那个
+func SelectFewestInputs(utxos []*UTXO, amount neoutils.Fixed8) []*UTXO {
+	for _, utxo := range sortUTXOs(utxos, false) {
+		if utxo.Value >= amount {
+			return []*UTXO{utxo}
+		}
+	}
+	return SelectLargestFirst(utxos, amount)
+}
+
+// SelectExactMatch 尽量寻找总额恰好等于 amount 的组合以避免找零，找不到时退化为 SelectFewestInputs
+func SelectExactMatch(utxos []*UTXO, amount neoutils.Fixed8) []*UTXO {
+	sorted := sortUTXOs(utxos, true)
+	// suffix[i] 为 sorted[i:] 的总额，用于剪枝
+	suffix := make([]neoutils.Fixed8, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		var err error
+		if suffix[i], err = suffix[i+1].Add(sorted[i].Value); err != nil {
+			// 总额超出 Fixed8 的范围，无法剪枝
+			return SelectFewestInputs(utxos, amount)
+		}
+	}
+
+	steps := 0
+	picked := make([]*UTXO, 0)
+	var search func(start int, remain neoutils.Fixed8) bool
+	search = func(start int, remain neoutils.Fixed8) bool {
+		if remain == 0 {
+			return true
+		}
+		for i := start; i < len(sorted); i++ {
+			steps++
+			if steps > exactMatchMaxSteps || suffix[i] < remain {
+				return false
+			}
+			if sorted[i].Value > remain {
+				continue
+			}
+			picked = append(picked, sorted[i])
+			if search(i+1, remain-sorted[i].Value) {
+				return true
+			}
+			picked = picked[:len(picked)-1]
+		}
+		return false
+	}
+	if amount > 0 && search(0, amount) {
+		return picked
+	}
+	return SelectFewestInputs(utxos, amount)
+}
+
+// uniqueUTXOs 去掉重复的UTXO（交易ID和序号相同），保持第一次出现的顺序
+func uniqueUTXOs(utxos []*UTXO) []*UTXO {
+	seen := make(map[string]bool, len(utxos))
+	ret := make([]*UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		key := fmt.Sprintf("%x:%d", []byte(utxo.TxHash), utxo.Index)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ret = append(ret, utxo)
+	}
+	return ret
+}
+
+// sumUTXOs 计算UTXO的总额，超出 Fixed8 的范围时返回错误
+func sumUTXOs(utxos []*UTXO) (neoutils.Fixed8, error) {
+	values := make([]neoutils.Fixed8, 0, len(utxos))
+	for _, utxo := range utxos {
+		values = append(values, utxo.Value)
+	}
+	return neoutils.SumFixed8(values...)
+}
+
+// InsufficientFundsError 某种资产的可用UTXO总额不足，或选择策略选出的UTXO总额不足
+type InsufficientFundsError struct {
+	AssetID   neoutils.HASH256
+	Required  neoutils.Fixed8
+	Available neoutils.Fixed8 // 该资产所有可用UTXO的总额
+	Selected  neoutils.Fixed8 // 选择策略选出的UTXO总额，可用总额不足时为0
+}
+
+func (e *InsufficientFundsError) Error() string {
+	if e.Available >= e.Required {
+		return fmt.Sprintf("insufficient funds of asset %s: required %v available %v but strategy selected %v",
+			e.AssetID.ToHexString(), e.Required, e.Available, e.Selected)
+	}
+	return fmt.Sprintf("insufficient funds of asset %s: required %v available %v",
+		e.AssetID.ToHexString(), e.Required, e.Available)
+}
+
+// TransferOutput 转账的一笔输出
+type TransferOutput struct {
+	Address *Address
+	AssetID neoutils.HASH256
+	Value   neoutils.Fixed8
+}
+
+// TransferBuilder 合约交易（UTXO转账）构建器
+// 根据输出自动按资产选择输入，并把多余的金额找零到 ChangeAddress
+type TransferBuilder struct {
+	UTXOs         []*UTXO          // 可以使用的UTXO
+	Outputs       []TransferOutput // 转账输出
+	ChangeAddress *Address         // 找零地址
+	Strategy      SelectStrategy   // UTXO选择策略，为nil时使用 SelectLargestFirst
+
+	NetworkFee  neoutils.Fixed8 // 固定的网络手续费，从GAS中预留
+	AutoFee     bool            // 是否按手续费策略自动计算网络手续费（不低于 NetworkFee）
+	FeePolicy   *FeePolicy      // 自动计算手续费使用的策略，为nil时使用 DefaultFeePolicy
+	PriorityFee neoutils.Fixed8 // 自动计算手续费时希望支付的最低优先级手续费
+	Signers     []*Address      // 输入所属的账户（带鉴权脚本），用于估算鉴证人大小，未提供的按基本账户估算
+}
+
+// AddOutput 添加一笔转账输出
+func (b *TransferBuilder) AddOutput(addr *Address, assetID neoutils.HASH256, value neoutils.Fixed8) {
+	b.Outputs = append(b.Outputs, TransferOutput{Address: addr, AssetID: assetID, Value: value})
+}
+
+// assetAmount 一种资产需要的总额
+type assetAmount struct {
+	assetID neoutils.HASH256
+	amount  neoutils.Fixed8
+}
+
+// requiredAmounts 按资产汇总需要的金额，保持资产第一次出现的顺序
+func (b *TransferBuilder) requiredAmounts(extra []assetAmount) ([]assetAmount, error) {
+	required := make([]assetAmount, 0)
+	add := func(assetID neoutils.HASH256, value neoutils.Fixed8) error {
+		for i := range required {
+			if bytes.Equal(required[i].assetID, assetID) {
+				sum, err := required[i].amount.Add(value)
+				if err != nil {
+					return fmt.Errorf("TransferBuilder %v", err)
+				}
+				required[i].amount = sum
+				return nil
+			}
+		}
+		required = append(required, assetAmount{assetID: assetID, amount: value})
+		return nil
+	}
+	for _, output := range b.Outputs {
+		if output.Address == nil || !output.AssetID.IsValid() || output.Value <= 0 {
+			return nil, errors.New("TransferBuilder invalid output")
+		}
+		if err := add(output.AssetID, output.Value); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range extra {
+		if err := add(e.assetID, e.amount); err != nil {
+			return nil, err
+		}
+	}
+	return required, nil
+}
+
+// build 构建交易，extra 为输出之外需要额外支付的资产（例如网络手续费）
+func (b *TransferBuilder) build(extra []assetAmount) (*NeoTransaction, error) {
+	required, err := b.requiredAmounts(extra)
+	if err != nil {
+		return nil, err
+	}
+	if len(required) == 0 {
+		return nil, errors.New("TransferBuilder no outputs")
+	}
+	strategy := b.Strategy
+	if strategy == nil {
+		strategy = SelectLargestFirst
+	}
+
+	utxos := uniqueUTXOs(b.UTXOs)
+	tx := CreateContractTransaction()
+	changes := make([]assetAmount, 0)
+	for _, r := range required {
+		if r.amount <= 0 {
+			continue
+		}
+		candidates := make([]*UTXO, 0)
+		available := neoutils.Fixed8(0)
+		for _, utxo := range utxos {
+			if bytes.Equal(utxo.AssetID, r.assetID) {
+				candidates = append(candidates, utxo)
+				if available, err = available.Add(utxo.Value); err != nil {
+					return nil, fmt.Errorf("TransferBuilder available %v", err)
+				}
+			}
+		}
+		if available < r.amount {
+			return nil, &InsufficientFundsError{AssetID: r.assetID, Required: r.amount, Available: available}
+		}
+		selected := strategy(candidates, r.amount)
+		sum, err := sumUTXOs(selected)
+		if err != nil {
+			return nil, fmt.Errorf("TransferBuilder selected %v", err)
+		}
+		for _, utxo := range selected {
+			tx.AppendInput(utxo)
+		}
+		if sum < r.amount {
+			return nil, &InsufficientFundsError{AssetID: r.assetID, Required: r.amount, Available: available, Selected: sum}
+		}
+		if sum > r.amount {
+			changes = append(changes, assetAmount{assetID: r.assetID, amount: sum - r.amount})
+		}
+	}
+
+	for _, output := range b.Outputs {
+		tx.AppendOutput(output.Address, output.AssetID, output.Value)
+	}
+	if len(changes) > 0 && b.ChangeAddress == nil {
+		return nil, errors.New("TransferBuilder change address not set")
+	}
+	for _, change := range changes {
+		tx.AppendOutput(b.ChangeAddress, change.assetID, change.amount)
+	}
+	return tx, nil
+}
+
+// Build 选择输入并构建未签名的合约交易
+// 如果设置了网络手续费则额外选择GAS输入支付，开启 AutoFee 时会根据估算的交易大小反复调整手续费
+func (b *TransferBuilder) Build() (*NeoTransaction, error) {
+	gasID, _ := neoutils.ParseHASH256(AssetGasID)
+	policy := b.FeePolicy
+	if policy == nil {
+		policy = &DefaultFeePolicy
+	}
+	priorityFee := b.PriorityFee
+	if priorityFee < b.NetworkFee {
+		priorityFee = b.NetworkFee
+	}
+
+	fee := b.NetworkFee
+	for i := 0; i < maxFeeIterations; i++ {
+		tx, err := b.build([]assetAmount{{assetID: gasID, amount: fee}})
+		if err != nil || !b.AutoFee {
+			return tx, err
+		}
+		hashes, err := tx.ScriptHashesForVerifying(b.UTXOs)
+		if err != nil {
+			return nil, err
+		}
+		required, err := policy.CalculateFee(tx.EstimateSize(signerScripts(hashes, b.Signers)), priorityFee)
+		if err != nil {
+			return nil, err
+		}
+		if required <= fee {
+			return tx, nil
+		}
+		fee = required
+	}
+	return nil, errors.New("TransferBuilder fee calculation not converged")
+}
@@ -0,0 +1,135 @@
+package neotransaction
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neoutils"
+)
+
+// testUTXOs 构造一组同一交易中的输出，第i个输出的金额为 values[i]
+func testUTXOs(assetID neoutils.HASH256, values ...neoutils.Fixed8) []*UTXO {
+	utxos := make([]*UTXO, 0, len(values))
+	for i, v := range values {
+		utxos = append(utxos, &UTXO{TxHash: make([]byte, 32), Index: uint16(i), TxOutput: TxOutput{AssetID: assetID, Value: v}})
+	}
+	return utxos
+}
+
+func sumInputs(tx *NeoTransaction, utxos []*UTXO) neoutils.Fixed8 {
+	sum := neoutils.Fixed8(0)
+	for _, input := range tx.Inputs {
+		for _, utxo := range utxos {
+			if utxo.Index == input.PrevIndex {
+				sum += utxo.Value
+			}
+		}
+	}
+	return sum
+}
+
+func TestTransferBuilderStrategies(t *testing.T) {
+	neo, _ := neoutils.ParseHASH256(AssetNeoID)
+	addr := GenerateKeyPair().CreateBasicAddress()
+	utxos := testUTXOs(neo, 5, 3, 8, 1)
+
+	tests := []struct {
+		name     string
+		strategy SelectStrategy
+		inputs   int
+		outputs  int
+	}{
+		{"LargestFirst", SelectLargestFirst, 2, 2},
+		{"SmallestFirst", SelectSmallestFirst, 3, 1},
+		{"FewestInputs", SelectFewestInputs, 2, 2},
+		{"ExactMatch", SelectExactMatch, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := TransferBuilder{UTXOs: utxos, ChangeAddress: addr, Strategy: tt.strategy}
+			b.AddOutput(addr, neo, 9)
+			tx, err := b.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tx.Inputs) != tt.inputs || len(tx.Outputs) != tt.outputs {
+				t.Fatalf("inputs %v outputs %v, want %v %v", len(tx.Inputs), len(tx.Outputs), tt.inputs, tt.outputs)
+			}
+			change := neoutils.Fixed8(0)
+			if len(tx.Outputs) > 1 {
+				change = tx.Outputs[1].Value
+			}
+			if sumInputs(tx, utxos) != 9+change {
+				t.Fatalf("inputs %v not balanced with outputs", sumInputs(tx, utxos))
+			}
+		})
+	}
+}
+
+func TestTransferBuilderDuplicateUTXOs(t *testing.T) {
+	neo, _ := neoutils.ParseHASH256(AssetNeoID)
+	addr := GenerateKeyPair().CreateBasicAddress()
+	utxos := testUTXOs(neo, 5)
+	utxos = append(utxos, utxos[0], &UTXO{TxHash: utxos[0].TxHash, Index: 0, TxOutput: utxos[0].TxOutput})
+
+	b := TransferBuilder{UTXOs: utxos, ChangeAddress: addr}
+	b.AddOutput(addr, neo, 8)
+	_, err := b.Build()
+	var insufficient *InsufficientFundsError
+	if !errors.As(err, &insufficient) || insufficient.Available != 5 {
+		t.Fatalf("duplicate UTXOs counted more than once: %v", err)
+	}
+
+	b.Outputs = nil
+	b.AddOutput(addr, neo, 5)
+	tx, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Inputs) != 1 {
+		t.Fatalf("inputs %v, want 1", len(tx.Inputs))
+	}
+}
+
+func TestTransferBuilderStrategyUnderSelects(t *testing.T) {
+	neo, _ := neoutils.ParseHASH256(AssetNeoID)
+	addr := GenerateKeyPair().CreateBasicAddress()
+	utxos := testUTXOs(neo, 5, 3, 8, 1)
+	firstOnly := func(utxos []*UTXO, amount neoutils.Fixed8) []*UTXO {
+		return utxos[:1]
+	}
+
+	b := TransferBuilder{UTXOs: utxos, ChangeAddress: addr, Strategy: firstOnly}
+	b.AddOutput(addr, neo, 9)
+	_, err := b.Build()
+	var insufficient *InsufficientFundsError
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("expected InsufficientFundsError, got %v", err)
+	}
+	if insufficient.Required != 9 || insufficient.Available != 17 || insufficient.Selected != 5 {
+		t.Fatalf("InsufficientFundsError %+v", insufficient)
+	}
+}
+
+func TestTransferBuilderOverflow(t *testing.T) {
+	neo, _ := neoutils.ParseHASH256(AssetNeoID)
+	addr := GenerateKeyPair().CreateBasicAddress()
+	utxos := testUTXOs(neo, neoutils.MaxFixed8-1, 5, 1)
+
+	// 选择的UTXO总额超出 Fixed8 的范围时策略不选择
+	if selected := SelectLargestFirst(utxos, neoutils.MaxFixed8); selected != nil {
+		t.Fatalf("selected %v UTXOs with overflowed sum", len(selected))
+	}
+	// 总额超出范围时不剪枝，退化为 SelectFewestInputs
+	if selected := SelectExactMatch(utxos, 6); len(selected) != 1 || selected[0].Value != neoutils.MaxFixed8-1 {
+		t.Fatalf("exact match selected %v", selected)
+	}
+
+	b := TransferBuilder{UTXOs: utxos, ChangeAddress: addr}
+	b.AddOutput(addr, neo, 10)
+	_, err := b.Build()
+	var insufficient *InsufficientFundsError
+	if err == nil || errors.As(err, &insufficient) {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package neoutils
+
+import "math/big"
+
+// BigIntToBytes 将大整数编码为NeoVM使用的小端序补码字节数组（与C# BigInteger.ToByteArray一致）
+// 0 编码为空数组
+func BigIntToBytes(v *big.Int) []byte {
+	if v.Sign() == 0 {
+		return []byte{}
+	}
+	if v.Sign() > 0 {
+		b := v.Bytes()
+		if b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return Reverse(b)
+	}
+
+	// 负数取 2^(8*l) + v 得到补码
+	l := len(new(big.Int).Neg(v).Bytes())
+	x := new(big.Int).Lsh(big.NewInt(1), uint(8*l))
+	x.Add(x, v)
+	b := x.FillBytes(make([]byte, l))
+	if b[0]&0x80 == 0 {
+		b = append([]byte{0xff}, b...)
+	}
+	return Reverse(b)
+}
+
+// BytesToBigInt 将NeoVM使用的小端序补码字节数组解码为大整数，是 BigIntToBytes 的逆运算
+// 空数组解码为 0
+func BytesToBigInt(b []byte) *big.Int {
+	if len(b) == 0 {
+		return new(big.Int)
+	}
+	v := new(big.Int).SetBytes(Reverse(b))
+	if b[len(b)-1]&0x80 != 0 {
+		// 负数减去 2^(8*len)
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return v
+}
@@ -1,6 +1,10 @@
 package neoutils
 
-import "bytes"
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
 
 // WriteUint16ToBuffer 向bytes.Buffer中以小端序写入一个uint16
 func WriteUint16ToBuffer(b *bytes.Buffer, v uint16) {
@@ -37,3 +41,54 @@ func Reverse(s []byte) []byte {
 	}
 	return k
 }
+
+// ReadUint16FromBuffer 从bytes.Reader中以小端序读取一个uint16
+func ReadUint16FromBuffer(r *bytes.Reader) (uint16, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(b[:]), nil
+}
+
+// ReadUint32FromBuffer 从bytes.Reader中以小端序读取一个uint32
+func ReadUint32FromBuffer(r *bytes.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+// ReadUint64FromBuffer 从bytes.Reader中以小端序读取一个uint64
+func ReadUint64FromBuffer(r *bytes.Reader) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+// ReadBytesFromBuffer 从bytes.Reader中读取固定长度n的字节数组
+func ReadBytesFromBuffer(r *bytes.Reader, n int) ([]byte, error) {
+	if n < 0 || n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ReadVarBytesFromBuffer 从bytes.Reader中读取一个以VarInt表示长度的字节数组
+func ReadVarBytesFromBuffer(r *bytes.Reader) ([]byte, error) {
+	length, err := ReadVarIntFromBuffer(r)
+	if err != nil {
+		return nil, err
+	}
+	if length.Value > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return ReadBytesFromBuffer(r, int(length.Value))
+}
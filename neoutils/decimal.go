@@ -0,0 +1,70 @@
+package neoutils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ParseDecimal 将十进制金额字符串（如 "12.345"）转换为 decimals 位小数的整数单位，如 decimals 为8时 "1.5" 转换为 150000000
+// 小数位数超过 decimals 时返回错误，不做舍入
+func ParseDecimal(s string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("ParseDecimal invalid decimals %d", decimals)
+	}
+	str := strings.TrimSpace(s)
+	neg := strings.HasPrefix(str, "-")
+	str = strings.TrimPrefix(strings.TrimPrefix(str, "-"), "+")
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	if len(intPart)+len(fracPart) == 0 || !isDigits(intPart) || !isDigits(fracPart) {
+		return nil, fmt.Errorf("ParseDecimal invalid number %q", s)
+	}
+	// 去掉小数部分末尾的0后仍超出精度
+	if len(strings.TrimRight(fracPart, "0")) > decimals {
+		return nil, fmt.Errorf("ParseDecimal number %q has more than %d decimals", s, decimals)
+	}
+	if len(fracPart) > decimals {
+		fracPart = fracPart[:decimals]
+	}
+	digits := intPart + fracPart + strings.Repeat("0", decimals-len(fracPart))
+	v, ok := new(big.Int).SetString("0"+digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("ParseDecimal invalid number %q", s)
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return v, nil
+}
+
+// FormatDecimal 将 decimals 位小数的整数单位格式化为十进制金额字符串，去掉小数部分末尾的0
+func FormatDecimal(v *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return v.String()
+	}
+	abs := new(big.Int).Abs(v).String()
+	if len(abs) <= decimals {
+		abs = strings.Repeat("0", decimals-len(abs)+1) + abs
+	}
+	intPart, fracPart := abs[:len(abs)-decimals], strings.TrimRight(abs[len(abs)-decimals:], "0")
+	str := intPart
+	if len(fracPart) > 0 {
+		str += "." + fracPart
+	}
+	if v.Sign() < 0 {
+		str = "-" + str
+	}
+	return str
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
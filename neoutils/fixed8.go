@@ -0,0 +1,196 @@
+package neoutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Fixed8 NEO的定点数金额，以 10e-8 为单位，如 1 GAS 为 Fixed8(100000000)
+// 交易输出、手续费、GAS消耗等金额都使用 Fixed8
+type Fixed8 int64
+
+// Fixed8 的精度
+const (
+	Fixed8Decimals        = 8
+	Fixed8One      Fixed8 = 100000000 // 1个单位的资产
+)
+
+// Fixed8 的取值范围
+const (
+	MaxFixed8 Fixed8 = math.MaxInt64
+	MinFixed8 Fixed8 = math.MinInt64
+)
+
+var (
+	maxFixed8Big = big.NewInt(math.MaxInt64)
+	minFixed8Big = big.NewInt(math.MinInt64)
+)
+
+// ParseFixed8 将十进制金额字符串（如 "12.345"，也接受节点可能返回的科学计数法）精确转换为 Fixed8
+// 小数位数超过8位或超出取值范围时返回错误，不做舍入
+func ParseFixed8(s string) (Fixed8, error) {
+	str := strings.TrimSpace(s)
+	if strings.ContainsAny(str, "eE") {
+		r, ok := new(big.Rat).SetString(str)
+		if !ok {
+			return 0, fmt.Errorf("ParseFixed8 invalid number %q", s)
+		}
+		r.Mul(r, new(big.Rat).SetInt64(int64(Fixed8One)))
+		if !r.IsInt() {
+			return 0, fmt.Errorf("ParseFixed8 number %q has more than %d decimals", s, Fixed8Decimals)
+		}
+		return fixed8FromBig(r.Num(), s)
+	}
+	v, err := ParseDecimal(str, Fixed8Decimals)
+	if err != nil {
+		return 0, fmt.Errorf("ParseFixed8 %v", err)
+	}
+	return fixed8FromBig(v, s)
+}
+
+// fixed8FromBig 检查以 10e-8 为单位的大整数是否超出 Fixed8 的取值范围
+func fixed8FromBig(v *big.Int, s string) (Fixed8, error) {
+	if v.Cmp(maxFixed8Big) > 0 || v.Cmp(minFixed8Big) < 0 {
+		return 0, fmt.Errorf("Fixed8 number %s out of range", s)
+	}
+	return Fixed8(v.Int64()), nil
+}
+
+// Fixed8FromRat 将精确的金额（以1个单位计）转换为 Fixed8，超出8位的小数部分四舍五入，超出取值范围时返回错误
+func Fixed8FromRat(r *big.Rat) (Fixed8, error) {
+	v := new(big.Rat).Mul(r, new(big.Rat).SetInt64(int64(Fixed8One)))
+	// 四舍五入到整数：(2*num + sign*denom) / (2*denom)，向零取整
+	num := new(big.Int).Mul(v.Num(), big.NewInt(2))
+	num.Add(num, new(big.Int).Mul(v.Denom(), big.NewInt(int64(v.Sign()))))
+	num.Quo(num, new(big.Int).Mul(v.Denom(), big.NewInt(2)))
+	return fixed8FromBig(num, r.FloatString(Fixed8Decimals+1))
+}
+
+// Fixed8FromInt 将整数个单位的金额（如 10 NEO）转换为 Fixed8
+func Fixed8FromInt(n int64) (Fixed8, error) {
+	return Fixed8One.Mul(n)
+}
+
+// Fixed8FromDecimals 将 decimals 位小数的整数单位（如NEP-5资产的金额）转换为 Fixed8
+// 小数位数超过8位且不能整除或超出取值范围时返回错误
+func Fixed8FromDecimals(v *big.Int, decimals int) (Fixed8, error) {
+	if decimals < 0 {
+		return 0, fmt.Errorf("Fixed8FromDecimals invalid decimals %d", decimals)
+	}
+	ret := new(big.Int)
+	if decimals <= Fixed8Decimals {
+		ret.Mul(v, pow10(Fixed8Decimals-decimals))
+	} else {
+		r := new(big.Int)
+		ret.QuoRem(v, pow10(decimals-Fixed8Decimals), r)
+		if r.Sign() != 0 {
+			return 0, fmt.Errorf("Fixed8FromDecimals %s has more than %d decimals", FormatDecimal(v, decimals), Fixed8Decimals)
+		}
+	}
+	return fixed8FromBig(ret, FormatDecimal(v, decimals))
+}
+
+// ToDecimals 将金额转换为 decimals 位小数的整数单位（如NEP-5资产的金额），decimals 小于8且不能整除时返回错误
+func (f Fixed8) ToDecimals(decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("Fixed8 invalid decimals %d", decimals)
+	}
+	v := big.NewInt(int64(f))
+	if decimals >= Fixed8Decimals {
+		return v.Mul(v, pow10(decimals-Fixed8Decimals)), nil
+	}
+	r := new(big.Int)
+	v.QuoRem(v, pow10(Fixed8Decimals-decimals), r)
+	if r.Sign() != 0 {
+		return nil, fmt.Errorf("Fixed8 %s has more than %d decimals", f, decimals)
+	}
+	return v, nil
+}
+
+// BigInt 返回以 10e-8 为单位的大整数
+func (f Fixed8) BigInt() *big.Int {
+	return big.NewInt(int64(f))
+}
+
+// IntegerValue 返回金额的整数部分（向零取整）
+func (f Fixed8) IntegerValue() int64 {
+	return int64(f / Fixed8One)
+}
+
+// String 将金额格式化为十进制字符串，去掉小数部分末尾的0，如 "1.5"
+func (f Fixed8) String() string {
+	return FormatDecimal(big.NewInt(int64(f)), Fixed8Decimals)
+}
+
+// Add 加法，溢出时返回错误
+func (f Fixed8) Add(g Fixed8) (Fixed8, error) {
+	sum := f + g
+	if (g > 0 && sum < f) || (g < 0 && sum > f) {
+		return 0, fmt.Errorf("Fixed8 %s + %s overflow", f, g)
+	}
+	return sum, nil
+}
+
+// Sub 减法，溢出时返回错误
+func (f Fixed8) Sub(g Fixed8) (Fixed8, error) {
+	diff := f - g
+	if (g > 0 && diff > f) || (g < 0 && diff < f) {
+		return 0, fmt.Errorf("Fixed8 %s - %s overflow", f, g)
+	}
+	return diff, nil
+}
+
+// Mul 乘以一个整数，溢出时返回错误
+func (f Fixed8) Mul(n int64) (Fixed8, error) {
+	if f == 0 || n == 0 {
+		return 0, nil
+	}
+	product := f * Fixed8(n)
+	if product/Fixed8(n) != f || (n == -1 && f == MinFixed8) {
+		return 0, fmt.Errorf("Fixed8 %s * %d overflow", f, n)
+	}
+	return product, nil
+}
+
+// SumFixed8 计算多个金额的和，溢出时返回错误
+func SumFixed8(values ...Fixed8) (Fixed8, error) {
+	sum := Fixed8(0)
+	for _, v := range values {
+		var err error
+		if sum, err = sum.Add(v); err != nil {
+			return 0, err
+		}
+	}
+	return sum, nil
+}
+
+// MarshalJSON 编码为与 neo-cli 相同的十进制字符串，如 "1.5"
+func (f Fixed8) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON 解析十进制字符串或数字，如 "1.5"、1.5
+func (f *Fixed8) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	v, err := ParseFixed8(s)
+	if err != nil {
+		return err
+	}
+	*f = v
+	return nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
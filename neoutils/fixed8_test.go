@@ -0,0 +1,354 @@
+package neoutils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestParseFixed8(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Fixed8
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"-0", 0, true},
+		{"1", Fixed8One, true},
+		{"12.5", 1250000000, true},
+		{"+12.5", 1250000000, true},
+		{"-12.5", -1250000000, true},
+		{" 1.5 ", 150000000, true},
+		{".5", 50000000, true},
+		{"1.", Fixed8One, true},
+		{"0.00000001", 1, true},
+		{"-0.00000001", -1, true},
+		{"1.000000000", Fixed8One, true}, // 末尾的0不算超出精度
+		{"92233720368.54775807", MaxFixed8, true},
+		{"-92233720368.54775808", MinFixed8, true},
+		{"1e-8", 1, true},
+		{"1.5E2", 15000000000, true},
+		{"-2.5e-3", -250000, true},
+		{"0.000000001", 0, false},
+		{"-0.000000001", 0, false},
+		{"1e-9", 0, false},
+		{"92233720368.54775808", 0, false},
+		{"-92233720368.54775809", 0, false},
+		{"1e11", 0, false},
+		{"", 0, false},
+		{"-", 0, false},
+		{".", 0, false},
+		{"abc", 0, false},
+		{"1.2.3", 0, false},
+		{"--1", 0, false},
+		{"0x10", 0, false},
+		{"1e", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseFixed8(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseFixed8(%q) = %v, %v; want %v ok %v", tt.in, int64(got), err, int64(tt.want), tt.ok)
+		}
+	}
+}
+
+func TestFixed8String(t *testing.T) {
+	tests := []struct {
+		in   Fixed8
+		want string
+	}{
+		{0, "0"},
+		{1, "0.00000001"},
+		{-1, "-0.00000001"},
+		{Fixed8One, "1"},
+		{150000000, "1.5"},
+		{-150000000, "-1.5"},
+		{1234567890123, "12345.67890123"},
+		{MaxFixed8, "92233720368.54775807"},
+		{MinFixed8, "-92233720368.54775808"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Fixed8(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+		}
+		// 格式化的结果可以精确地解析回来
+		if back, err := ParseFixed8(tt.want); err != nil || back != tt.in {
+			t.Errorf("ParseFixed8(%q) = %v, %v; want %d", tt.want, int64(back), err, int64(tt.in))
+		}
+	}
+}
+
+func TestFixed8JSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Fixed8
+		ok   bool
+	}{
+		{`"1.5"`, 150000000, true},
+		{`1.5`, 150000000, true},
+		{`-1.5`, -150000000, true},
+		{`1e-8`, 1, true},
+		{`"1E2"`, 10000000000, true},
+		{`"92233720368.54775807"`, MaxFixed8, true},
+		{`"-92233720368.54775808"`, MinFixed8, true},
+		{`0.000000001`, 0, false},
+		{`"0.000000001"`, 0, false},
+		{`"92233720368.54775808"`, 0, false},
+		{`true`, 0, false},
+		{`"abc"`, 0, false},
+	}
+	for _, tt := range tests {
+		var got Fixed8
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v ok %v", tt.in, int64(got), err, int64(tt.want), tt.ok)
+		}
+		if !tt.ok {
+			continue
+		}
+		data, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Fixed8
+		if err = json.Unmarshal(data, &back); err != nil || back != got {
+			t.Errorf("round trip of %s through %s = %v, %v", tt.in, data, int64(back), err)
+		}
+	}
+
+	// null 不修改原值
+	f := Fixed8(7)
+	if err := json.Unmarshal([]byte(`null`), &f); err != nil || f != 7 {
+		t.Errorf("Unmarshal(null) = %v, %v", int64(f), err)
+	}
+	data, _ := json.Marshal(struct{ Value Fixed8 }{-150000000})
+	if string(data) != `{"Value":"-1.5"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
+
+func TestFixed8Arithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (Fixed8, error)
+		want Fixed8
+		ok   bool
+	}{
+		{"Add", func() (Fixed8, error) { return Fixed8(1).Add(2) }, 3, true},
+		{"AddNegative", func() (Fixed8, error) { return Fixed8(1).Add(-2) }, -1, true},
+		{"AddToMax", func() (Fixed8, error) { return (MaxFixed8 - 1).Add(1) }, MaxFixed8, true},
+		{"AddOverflow", func() (Fixed8, error) { return MaxFixed8.Add(1) }, 0, false},
+		{"AddUnderflow", func() (Fixed8, error) { return MinFixed8.Add(-1) }, 0, false},
+		{"AddMinMax", func() (Fixed8, error) { return MinFixed8.Add(MaxFixed8) }, -1, true},
+		{"Sub", func() (Fixed8, error) { return Fixed8(1).Sub(2) }, -1, true},
+		{"SubToMin", func() (Fixed8, error) { return (MinFixed8 + 1).Sub(1) }, MinFixed8, true},
+		{"SubUnderflow", func() (Fixed8, error) { return MinFixed8.Sub(1) }, 0, false},
+		{"SubOverflow", func() (Fixed8, error) { return MaxFixed8.Sub(-1) }, 0, false},
+		{"SubMin", func() (Fixed8, error) { return Fixed8(0).Sub(MinFixed8) }, 0, false},
+		{"SubMinFromMin", func() (Fixed8, error) { return MinFixed8.Sub(MinFixed8) }, 0, true},
+		{"Mul", func() (Fixed8, error) { return Fixed8One.Mul(3) }, 3 * Fixed8One, true},
+		{"MulNegative", func() (Fixed8, error) { return Fixed8(-5).Mul(-3) }, 15, true},
+		{"MulZero", func() (Fixed8, error) { return MaxFixed8.Mul(0) }, 0, true},
+		{"MulMax", func() (Fixed8, error) { return MaxFixed8.Mul(1) }, MaxFixed8, true},
+		{"MulMaxNegate", func() (Fixed8, error) { return MaxFixed8.Mul(-1) }, -MaxFixed8, true},
+		{"MulOverflow", func() (Fixed8, error) { return MaxFixed8.Mul(2) }, 0, false},
+		{"MulMinNegate", func() (Fixed8, error) { return MinFixed8.Mul(-1) }, 0, false},
+		{"MulMinByOne", func() (Fixed8, error) { return MinFixed8.Mul(1) }, MinFixed8, true},
+		{"MulLarge", func() (Fixed8, error) { return Fixed8One.Mul(92233720369) }, 0, false},
+		{"FromInt", func() (Fixed8, error) { return Fixed8FromInt(92233720368) }, 92233720368 * Fixed8One, true},
+		{"FromIntOverflow", func() (Fixed8, error) { return Fixed8FromInt(92233720369) }, 0, false},
+		{"Sum", func() (Fixed8, error) { return SumFixed8(1, 2, -4) }, -1, true},
+		{"SumEmpty", func() (Fixed8, error) { return SumFixed8() }, 0, true},
+		{"SumOverflow", func() (Fixed8, error) { return SumFixed8(MaxFixed8, 1, -1) }, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.op()
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s = %v, %v; want %v ok %v", tt.name, int64(got), err, int64(tt.want), tt.ok)
+		}
+	}
+}
+
+func TestFixed8FromRat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Fixed8
+		ok   bool
+	}{
+		{"1.5", 150000000, true},
+		{"0.000000005", 1, true},        // 恰好一半时远离0舍入
+		{"-0.000000005", -1, true},      // 负数同样远离0
+		{"0.0000000049", 0, true},       // 不足一半舍去
+		{"-0.0000000049", 0, true},      // 负数不足一半舍去
+		{"0.123456785", 12345679, true}, // 进位
+		{"0.1234567849999", 12345678, true},
+		{"1/3", 33333333, true},
+		{"2/3", 66666667, true},
+		{"-2/3", -66666667, true},
+		{"1e-9", 0, true},
+		{"92233720368.547758074", MaxFixed8, true},
+		{"92233720368.547758075", 0, false},
+		{"-92233720368.547758084", MinFixed8, true},
+		{"-92233720368.547758085", 0, false},
+	}
+	for _, tt := range tests {
+		r, ok := new(big.Rat).SetString(tt.in)
+		if !ok {
+			t.Fatalf("invalid rat %q", tt.in)
+		}
+		got, err := Fixed8FromRat(r)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("Fixed8FromRat(%s) = %v, %v; want %v ok %v", tt.in, int64(got), err, int64(tt.want), tt.ok)
+		}
+	}
+}
+
+func TestFixed8Decimals(t *testing.T) {
+	tests := []struct {
+		value    Fixed8
+		decimals int
+		units    string // decimals 位小数的整数单位
+		exact    bool   // 是否可以转换为 decimals 位小数
+	}{
+		{150000000, 8, "150000000", true},
+		{150000000, 0, "", false},
+		{3 * Fixed8One, 0, "3", true},
+		{150000000, 1, "15", true},
+		{150000000, 18, "1500000000000000000", true},
+		{-150000000, 18, "-1500000000000000000", true},
+		{1, 18, "10000000000", true},
+		{1, 7, "", false},
+		{MaxFixed8, 30, "92233720368547758070000000000000000000000", true},
+		{MinFixed8, 8, "-9223372036854775808", true},
+		{0, 0, "0", true},
+	}
+	for _, tt := range tests {
+		units, err := tt.value.ToDecimals(tt.decimals)
+		if (err == nil) != tt.exact || (tt.exact && units.String() != tt.units) {
+			t.Errorf("Fixed8(%d).ToDecimals(%d) = %v, %v; want %v", int64(tt.value), tt.decimals, units, err, tt.units)
+		}
+		if !tt.exact {
+			continue
+		}
+		v, _ := new(big.Int).SetString(tt.units, 10)
+		back, err := Fixed8FromDecimals(v, tt.decimals)
+		if err != nil || back != tt.value {
+			t.Errorf("Fixed8FromDecimals(%v, %d) = %v, %v; want %d", v, tt.decimals, int64(back), err, int64(tt.value))
+		}
+	}
+
+	errors := []struct {
+		units    string
+		decimals int
+	}{
+		{"1", 9},                    // 超过8位小数
+		{"1234567890123456789", 18}, // 超过8位小数
+		{"9223372036854775808", 8},  // 超出范围
+		{"-9223372036854775809", 8}, // 超出范围
+		{"92233720368547758080", 9}, // 可以整除但超出范围
+		{"1", -1},                   // 无效的精度
+	}
+	for _, tt := range errors {
+		v, _ := new(big.Int).SetString(tt.units, 10)
+		if got, err := Fixed8FromDecimals(v, tt.decimals); err == nil {
+			t.Errorf("Fixed8FromDecimals(%v, %d) = %d, want error", v, tt.decimals, int64(got))
+		}
+	}
+	if _, err := Fixed8(1).ToDecimals(-1); err == nil {
+		t.Error("ToDecimals(-1) accepted")
+	}
+	if Fixed8(-150000000).IntegerValue() != -1 || MaxFixed8.BigInt().String() != "9223372036854775807" {
+		t.Error("IntegerValue or BigInt")
+	}
+}
+
+func TestParseFormatDecimal(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int
+		units    string
+		out      string // 格式化的结果，与 in 不同时填写
+	}{
+		{"0", 0, "0", ""},
+		{"123", 0, "123", ""},
+		{"1.5", 18, "1500000000000000000", ""},
+		{"-1.5", 18, "-1500000000000000000", ""},
+		{"0.000000000000000001", 18, "1", ""},
+		{"123456789012345678901234567890.5", 2, "12345678901234567890123456789050", ""},
+		{"1.50", 2, "150", "1.5"},
+		{"0.10", 8, "10000000", "0.1"},
+		{"-0.5", 1, "-5", ""},
+	}
+	for _, tt := range tests {
+		v, err := ParseDecimal(tt.in, tt.decimals)
+		if err != nil || v.String() != tt.units {
+			t.Errorf("ParseDecimal(%q, %d) = %v, %v; want %v", tt.in, tt.decimals, v, err, tt.units)
+			continue
+		}
+		out := tt.out
+		if out == "" {
+			out = tt.in
+		}
+		if got := FormatDecimal(v, tt.decimals); got != out {
+			t.Errorf("FormatDecimal(%v, %d) = %q, want %q", v, tt.decimals, got, out)
+		}
+	}
+	for _, in := range []string{"1.5", "0.000000001", "1e2", "1,5", " ", "1 5"} {
+		if v, err := ParseDecimal(in, 0); err == nil && in != "1e2" {
+			t.Errorf("ParseDecimal(%q, 0) = %v, want error", in, v)
+		} else if in == "1e2" && err == nil {
+			t.Errorf("ParseDecimal(%q, 0) accepted exponent form", in)
+		}
+	}
+	if _, err := ParseDecimal("1", -1); err == nil {
+		t.Error("ParseDecimal with negative decimals accepted")
+	}
+}
+
+func TestBigIntBytes(t *testing.T) {
+	// 与 C# BigInteger.ToByteArray 的结果一致
+	tests := []struct {
+		v   int64
+		hex string
+	}{
+		{0, ""},
+		{1, "01"},
+		{-1, "ff"},
+		{127, "7f"},
+		{128, "8000"},
+		{255, "ff00"},
+		{256, "0001"},
+		{-128, "80"},
+		{-129, "7fff"},
+		{-256, "00ff"},
+		{-32768, "0080"},
+		{-32769, "ff7fff"},
+		{100000000, "00e1f505"},
+		{9223372036854775807, "ffffffffffffff7f"},
+		{-9223372036854775808, "0000000000000080"},
+	}
+	for _, tt := range tests {
+		v := big.NewInt(tt.v)
+		if got := hex.EncodeToString(BigIntToBytes(v)); got != tt.hex {
+			t.Errorf("BigIntToBytes(%d) = %s, want %s", tt.v, got, tt.hex)
+		}
+		b, _ := hex.DecodeString(tt.hex)
+		if got := BytesToBigInt(b); got.Cmp(v) != 0 {
+			t.Errorf("BytesToBigInt(%s) = %v, want %d", tt.hex, got, tt.v)
+		}
+	}
+
+	// 超出 int64 的值
+	big1, _ := new(big.Int).SetString("-1000000000000000000000000", 10)
+	if got := BytesToBigInt(BigIntToBytes(big1)); got.Cmp(big1) != 0 {
+		t.Errorf("round trip of %v = %v", big1, got)
+	}
+	// 非最短编码也能解码
+	if got := BytesToBigInt([]byte{0x01, 0x00, 0x00}); got.Int64() != 1 {
+		t.Errorf("BytesToBigInt(010000) = %v", got)
+	}
+	if got := BytesToBigInt([]byte{0xff, 0xff}); got.Int64() != -1 {
+		t.Errorf("BytesToBigInt(ffff) = %v", got)
+	}
+}
@@ -2,6 +2,9 @@ package neoutils
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
 
 	"golang.org/x/crypto/ripemd160"
 )
@@ -38,6 +41,42 @@ func (hash HASH160) Copy() HASH160 {
 	return ret
 }
 
+// ToHexString 返回 HASH256 的大端序十六进制字符串（不带0x前缀），即区块链浏览器中显示的格式
+func (hash HASH256) ToHexString() string {
+	return hex.EncodeToString(Reverse(hash))
+}
+
+// ToHexString 返回 HASH160 的大端序十六进制字符串（不带0x前缀），即区块链浏览器中显示的格式
+func (hash HASH160) ToHexString() string {
+	return hex.EncodeToString(Reverse(hash))
+}
+
+// ParseHASH256 将大端序十六进制字符串（可以带0x前缀）解析为 HASH256
+func ParseHASH256(s string) (HASH256, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	hash := HASH256(Reverse(b))
+	if !hash.IsValid() {
+		return nil, fmt.Errorf("ParseHASH256 invalid hash length %v", len(b))
+	}
+	return hash, nil
+}
+
+// ParseHASH160 将大端序十六进制字符串（可以带0x前缀）解析为 HASH160
+func ParseHASH160(s string) (HASH160, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	hash := HASH160(Reverse(b))
+	if !hash.IsValid() {
+		return nil, fmt.Errorf("ParseHASH160 invalid hash length %v", len(b))
+	}
+	return hash, nil
+}
+
 //var sha, ripemd hash.Hash
 
 func init() {
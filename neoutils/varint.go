@@ -1,6 +1,7 @@
 package neoutils
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -83,3 +84,35 @@ func ParseVarInt(bytes []byte) (VarInt, error) {
 	ret.Value = binary.LittleEndian.Uint64(bytes[1:])
 	return ret, nil
 }
+
+// ReadVarIntFromBuffer 从bytes.Reader中读取一个VarInt
+func ReadVarIntFromBuffer(r *bytes.Reader) (VarInt, error) {
+	ret := VarInt{}
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return ret, errors.New("ReadVarIntFromBuffer: no data to read")
+	}
+	switch prefix {
+	case 0xfd:
+		v, err := ReadUint16FromBuffer(r)
+		if err != nil {
+			return ret, fmt.Errorf("ReadVarIntFromBuffer: read uint16 failed %v", err)
+		}
+		ret.Value = uint64(v)
+	case 0xfe:
+		v, err := ReadUint32FromBuffer(r)
+		if err != nil {
+			return ret, fmt.Errorf("ReadVarIntFromBuffer: read uint32 failed %v", err)
+		}
+		ret.Value = uint64(v)
+	case 0xff:
+		v, err := ReadUint64FromBuffer(r)
+		if err != nil {
+			return ret, fmt.Errorf("ReadVarIntFromBuffer: read uint64 failed %v", err)
+		}
+		ret.Value = v
+	default:
+		ret.Value = uint64(prefix)
+	}
+	return ret, nil
+}
@@ -0,0 +1,221 @@
+package neowallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+)
+
+// ContractParameterInfo 账户合约的参数描述
+type ContractParameterInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Contract NEP-6 钱包账户的合约（鉴权脚本）
+type Contract struct {
+	Script     []byte
+	Parameters []ContractParameterInfo
+	Deployed   bool
+}
+
+type contractJSON struct {
+	Script     string                  `json:"script"`
+	Parameters []ContractParameterInfo `json:"parameters"`
+	Deployed   bool                    `json:"deployed"`
+}
+
+// MarshalJSON 脚本以十六进制字符串输出
+func (c *Contract) MarshalJSON() ([]byte, error) {
+	params := c.Parameters
+	if params == nil {
+		params = []ContractParameterInfo{}
+	}
+	return json.Marshal(&contractJSON{
+		Script:     hex.EncodeToString(c.Script),
+		Parameters: params,
+		Deployed:   c.Deployed,
+	})
+}
+
+// UnmarshalJSON 解析十六进制字符串格式的脚本
+func (c *Contract) UnmarshalJSON(data []byte) error {
+	raw := contractJSON{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	script, err := hex.DecodeString(raw.Script)
+	if err != nil {
+		return fmt.Errorf("Contract.UnmarshalJSON invalid script: %v", err)
+	}
+	c.Script = script
+	c.Parameters = raw.Parameters
+	c.Deployed = raw.Deployed
+	return nil
+}
+
+// Account NEP-6 钱包中的一个账户
+// Key 为NEP-2加密的私钥，观察账户（watch-only）没有私钥，Key 为空字符串
+type Account struct {
+	Address   string
+	Label     string
+	IsDefault bool
+	Lock      bool
+	Key       string
+	Contract  *Contract
+	Extra     json.RawMessage
+}
+
+type accountJSON struct {
+	Address   string          `json:"address"`
+	Label     *string         `json:"label"`
+	IsDefault bool            `json:"isDefault"`
+	Lock      bool            `json:"lock"`
+	Key       *string         `json:"key"`
+	Contract  *Contract       `json:"contract"`
+	Extra     json.RawMessage `json:"extra"`
+}
+
+// MarshalJSON 空的 Label 和 Key 输出为 null，与neo-cli保持一致
+func (account *Account) MarshalJSON() ([]byte, error) {
+	raw := accountJSON{
+		Address:   account.Address,
+		IsDefault: account.IsDefault,
+		Lock:      account.Lock,
+		Contract:  account.Contract,
+		Extra:     account.Extra,
+	}
+	if account.Label != "" {
+		raw.Label = &account.Label
+	}
+	if account.Key != "" {
+		raw.Key = &account.Key
+	}
+	if raw.Extra == nil {
+		raw.Extra = json.RawMessage("null")
+	}
+	return json.Marshal(&raw)
+}
+
+// UnmarshalJSON ...
+func (account *Account) UnmarshalJSON(data []byte) error {
+	raw := accountJSON{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*account = Account{
+		Address:   raw.Address,
+		IsDefault: raw.IsDefault,
+		Lock:      raw.Lock,
+		Contract:  raw.Contract,
+		Extra:     raw.Extra,
+	}
+	if raw.Label != nil {
+		account.Label = *raw.Label
+	}
+	if raw.Key != nil {
+		account.Key = *raw.Key
+	}
+	return nil
+}
+
+// WatchOnly 判断是否是没有私钥的观察账户
+func (account *Account) WatchOnly() bool {
+	return account.Key == ""
+}
+
+// GetAddress 返回账户地址，如果账户有合约则地址中包含鉴权脚本，可以用于签名交易
+func (account *Account) GetAddress() (*neotransaction.Address, error) {
+	if account.Contract != nil && len(account.Contract.Script) > 0 {
+		addr, err := neotransaction.CreateAddressByScript(account.Contract.Script)
+		if err != nil {
+			return nil, err
+		}
+		if addr.Addr != account.Address {
+			return nil, fmt.Errorf("Account.GetAddress contract script not match address %s", account.Address)
+		}
+		return addr, nil
+	}
+	return neotransaction.ParseAddress(account.Address)
+}
+
+// Unlock 使用密码解密账户私钥，params 为钱包的scrypt参数
+func (account *Account) Unlock(passphrase string, params neotransaction.ScryptParams) (*neotransaction.KeyPair, error) {
+	if account.WatchOnly() {
+		return nil, errors.New("Account.Unlock watch-only account has no key")
+	}
+	key, err := neotransaction.DecodeFromNep2(account.Key, passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	if err = account.checkKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// checkKey 检查私钥是否属于账户的合约：基本账户的地址由私钥得出，多签账户的私钥必须是其中一个成员的私钥，
+// 其他合约的脚本中必须包含私钥的公钥
+func (account *Account) checkKey(key *neotransaction.KeyPair) error {
+	if account.Contract == nil || len(account.Contract.Script) == 0 {
+		if key.CreateBasicAddress().Addr != account.Address {
+			return fmt.Errorf("Account.Unlock key not match address %s", account.Address)
+		}
+		return nil
+	}
+	if _, err := account.GetAddress(); err != nil {
+		return err
+	}
+	script := account.Contract.Script
+	pubKey := key.EncodePubkeyCompressed()
+	if neotransaction.IsBasicVerifyScript(script) {
+		if !bytes.Equal(script, neotransaction.BuildBasicVerifyScript(key)) {
+			return fmt.Errorf("Account.Unlock key not match address %s", account.Address)
+		}
+		return nil
+	}
+	if _, pubKeys, ok := neotransaction.ParseMultiSigVerifyScript(script); ok {
+		for _, member := range pubKeys {
+			if bytes.Equal(member, pubKey) {
+				return nil
+			}
+		}
+		return fmt.Errorf("Account.Unlock key is not a member of multi-sig address %s", account.Address)
+	}
+	if !bytes.Contains(script, pubKey) {
+		return fmt.Errorf("Account.Unlock key not in the contract of address %s", account.Address)
+	}
+	return nil
+}
+
+// basicContract 基本账户的合约描述
+func basicContract(key *neotransaction.KeyPair) *Contract {
+	return contractFromScript(neotransaction.BuildBasicVerifyScript(key))
+}
+
+// multiSigContract m-n多签账户的合约描述
+func multiSigContract(m int, keys []*neotransaction.KeyPair) (*Contract, error) {
+	script, err := neotransaction.BuildMultiSigVerifyScript(m, keys)
+	if err != nil {
+		return nil, err
+	}
+	return contractFromScript(script), nil
+}
+
+// contractFromScript 根据鉴权脚本生成合约描述，基本账户和多签账户会填写签名参数列表
+func contractFromScript(script []byte) *Contract {
+	contract := &Contract{Script: script, Parameters: []ContractParameterInfo{}}
+	if neotransaction.IsBasicVerifyScript(script) {
+		contract.Parameters = append(contract.Parameters, ContractParameterInfo{Name: "signature", Type: neotransaction.ParamSignature})
+	} else if m, _, ok := neotransaction.ParseMultiSigVerifyScript(script); ok {
+		for i := 0; i < m; i++ {
+			contract.Parameters = append(contract.Parameters,
+				ContractParameterInfo{Name: fmt.Sprintf("parameter%d", i), Type: neotransaction.ParamSignature})
+		}
+	}
+	return contract
+}
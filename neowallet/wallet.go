@@ -0,0 +1,232 @@
+package neowallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+)
+
+// WalletVersion NEP-6 钱包文件的版本号
+const WalletVersion = "1.0"
+
+// Wallet NEP-6 钱包，文件格式与 NEO-GUI / neo-cli 的 .json 钱包一致
+type Wallet struct {
+	Name     string                      `json:"name"`
+	Version  string                      `json:"version"`
+	Scrypt   neotransaction.ScryptParams `json:"scrypt"`
+	Accounts []*Account                  `json:"accounts"`
+	Extra    json.RawMessage             `json:"extra"`
+
+	path string
+}
+
+// NewWallet 创建一个空钱包，使用默认的scrypt参数
+func NewWallet(name string) *Wallet {
+	return &Wallet{
+		Name:     name,
+		Version:  WalletVersion,
+		Scrypt:   neotransaction.DefaultScryptParams,
+		Accounts: make([]*Account, 0),
+		Extra:    json.RawMessage("null"),
+	}
+}
+
+// OpenWallet 从文件打开一个NEP-6钱包
+func OpenWallet(path string) (*Wallet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Wallet{}
+	if err = json.Unmarshal(data, w); err != nil {
+		return nil, fmt.Errorf("OpenWallet parse %s failed: %v", path, err)
+	}
+	if w.Accounts == nil {
+		w.Accounts = make([]*Account, 0)
+	}
+	w.path = path
+	return w, nil
+}
+
+// Path 返回钱包文件路径，新建且未保存过的钱包返回空字符串
+func (w *Wallet) Path() string {
+	return w.path
+}
+
+// Save 保存钱包到打开时的文件
+func (w *Wallet) Save() error {
+	if w.path == "" {
+		return errors.New("Wallet.Save wallet has no path, use SaveAs instead")
+	}
+	return w.SaveAs(w.path)
+}
+
+// SaveAs 保存钱包到指定文件，之后 Save 也会保存到此文件
+func (w *Wallet) SaveAs(path string) error {
+	if w.Extra == nil {
+		w.Extra = json.RawMessage("null")
+	}
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	w.path = path
+	return nil
+}
+
+// GetAccount 根据地址字符串查找账户，不存在时返回nil
+func (w *Wallet) GetAccount(address string) *Account {
+	for _, account := range w.Accounts {
+		if account.Address == address {
+			return account
+		}
+	}
+	return nil
+}
+
+// DefaultAccount 返回默认账户，如果没有设置默认账户则返回第一个账户，钱包为空时返回nil
+func (w *Wallet) DefaultAccount() *Account {
+	for _, account := range w.Accounts {
+		if account.IsDefault {
+			return account
+		}
+	}
+	if len(w.Accounts) > 0 {
+		return w.Accounts[0]
+	}
+	return nil
+}
+
+// SetDefault 将地址为 address 的账户设为默认账户
+func (w *Wallet) SetDefault(address string) error {
+	target := w.GetAccount(address)
+	if target == nil {
+		return fmt.Errorf("Wallet.SetDefault account %s not found", address)
+	}
+	for _, account := range w.Accounts {
+		account.IsDefault = false
+	}
+	target.IsDefault = true
+	return nil
+}
+
+// RemoveAccount 从钱包中删除账户，返回是否找到并删除
+func (w *Wallet) RemoveAccount(address string) bool {
+	for i, account := range w.Accounts {
+		if account.Address == address {
+			w.Accounts = append(w.Accounts[:i], w.Accounts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// addAccount 添加账户，如果已经存在同地址的账户则用新账户的私钥和合约补全旧账户
+func (w *Wallet) addAccount(account *Account) *Account {
+	if old := w.GetAccount(account.Address); old != nil {
+		if old.Key == "" {
+			old.Key = account.Key
+		}
+		if old.Contract == nil {
+			old.Contract = account.Contract
+		}
+		if old.Label == "" {
+			old.Label = account.Label
+		}
+		return old
+	}
+	w.Accounts = append(w.Accounts, account)
+	return account
+}
+
+// CreateAccount 生成一个新的秘钥对并以基本账户加入钱包，私钥使用 passphrase 加密
+func (w *Wallet) CreateAccount(passphrase string, label string) (*Account, *neotransaction.KeyPair, error) {
+	key := neotransaction.GenerateKeyPair()
+	account, err := w.ImportKey(key, passphrase, label)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, key, nil
+}
+
+// ImportKey 将已有的秘钥对以基本账户加入钱包，私钥使用 passphrase 加密
+func (w *Wallet) ImportKey(key *neotransaction.KeyPair, passphrase string, label string) (*Account, error) {
+	nep2, err := key.EncodeNep2(passphrase, w.Scrypt)
+	if err != nil {
+		return nil, err
+	}
+	return w.addAccount(&Account{
+		Address:  key.CreateBasicAddress().Addr,
+		Label:    label,
+		Key:      nep2,
+		Contract: basicContract(key),
+	}), nil
+}
+
+// AddWatchOnly 添加一个没有私钥的观察账户，如果地址带有鉴权脚本则一并保存
+func (w *Wallet) AddWatchOnly(addr *neotransaction.Address, label string) (*Account, error) {
+	account := &Account{Address: addr.Addr, Label: label}
+	if addr.HaveScript() {
+		account.Contract = contractFromScript(addr.Script)
+	}
+	return w.addAccount(account), nil
+}
+
+// AddMultiSigAccount 添加一个m-n多签账户，keys 为所有成员的公钥
+// owner 为本钱包持有的成员私钥，使用 passphrase 加密保存；owner 为nil时添加为观察账户
+func (w *Wallet) AddMultiSigAccount(m int, keys []*neotransaction.KeyPair, owner *neotransaction.KeyPair, passphrase string, label string) (*Account, error) {
+	contract, err := multiSigContract(m, keys)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := neotransaction.CreateAddressByScript(contract.Script)
+	if err != nil {
+		return nil, err
+	}
+	account := &Account{Address: addr.Addr, Label: label, Contract: contract}
+	if owner != nil {
+		pubKey := string(owner.EncodePubkeyCompressed())
+		found := false
+		for _, key := range keys {
+			if string(key.EncodePubkeyCompressed()) == pubKey {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("Wallet.AddMultiSigAccount owner key is not a member")
+		}
+		if account.Key, err = owner.EncodeNep2(passphrase, w.Scrypt); err != nil {
+			return nil, err
+		}
+	}
+	return w.addAccount(account), nil
+}
+
+// Unlock 使用密码解密地址为 address 的账户私钥
+func (w *Wallet) Unlock(address string, passphrase string) (*neotransaction.KeyPair, error) {
+	account := w.GetAccount(address)
+	if account == nil {
+		return nil, fmt.Errorf("Wallet.Unlock account %s not found", address)
+	}
+	return account.Unlock(passphrase, w.Scrypt)
+}
+
+// Addresses 返回钱包中所有账户的地址，地址中包含鉴权脚本（如果有）
+func (w *Wallet) Addresses() ([]*neotransaction.Address, error) {
+	addrs := make([]*neotransaction.Address, 0, len(w.Accounts))
+	for _, account := range w.Accounts {
+		addr, err := account.GetAddress()
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
@@ -0,0 +1,213 @@
+package neowallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/x-contract/neo-go-sdk/neotransaction"
+)
+
+// testdata/wallet.json 是按 neo-gui 2.x 保存的 NEP-6 钱包格式手工构造的，不是 neo-gui 导出的文件：
+// 第一个账户是 NEP-2 标准测试向量的私钥，第二个账户是包含该私钥的2-3多签账户，第三个是观察账户
+const (
+	fixtureWif        = "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP"
+	fixturePassphrase = "TestingOneTwoThree"
+	fixtureAddress    = "AStZHy8E6StCqYQbzMqi4poH7YNDHQKxvt"
+	fixtureMultiSig   = "AHcURJq6oYpTYoRLT7eSaaVMJPxp9uRdHs"
+	fixtureWatchOnly  = "AZgyfDnjW1mMzKKg5PPUAq85PdeQWrpsM9"
+)
+
+// otherKey 多签账户中的另一个成员
+func otherKey(t *testing.T) *neotransaction.KeyPair {
+	key, err := neotransaction.DecodeFromWif("L3JpMM6RTan8zm7UH4ietp5aDoy4zahrvniTWZKpuhY7X8PeTxcT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// sameJSON 比较两个json的内容，忽略格式
+func sameJSON(t *testing.T, a []byte, b []byte) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func TestOpenWalletNEP6(t *testing.T) {
+	w, err := OpenWallet("testdata/wallet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Name != "wallet" || w.Version != WalletVersion || w.Scrypt != neotransaction.DefaultScryptParams || len(w.Accounts) != 3 {
+		t.Fatalf("wallet %+v", w)
+	}
+	if account := w.DefaultAccount(); account == nil || account.Address != fixtureAddress || account.Label != "" {
+		t.Fatalf("default account %+v", account)
+	}
+	if watch := w.GetAccount(fixtureWatchOnly); watch == nil || !watch.WatchOnly() || watch.Contract != nil || watch.Label != "watch" {
+		t.Fatalf("watch-only account %+v", watch)
+	}
+	addrs, err := w.Addresses()
+	if err != nil || len(addrs) != 3 {
+		t.Fatalf("Addresses %v %v", addrs, err)
+	}
+	if !neotransaction.IsBasicVerifyScript(addrs[0].Script) || addrs[2].HaveScript() {
+		t.Fatalf("address scripts %x %x", addrs[0].Script, addrs[2].Script)
+	}
+	if m, pubKeys, ok := neotransaction.ParseMultiSigVerifyScript(addrs[1].Script); !ok || m != 2 || len(pubKeys) != 3 {
+		t.Fatalf("multi-sig script %x", addrs[1].Script)
+	}
+
+	// 基本账户和多签账户保存的是同一个成员私钥
+	for _, address := range []string{fixtureAddress, fixtureMultiSig} {
+		key, err := w.Unlock(address, fixturePassphrase)
+		if err != nil {
+			t.Fatalf("Unlock %v: %v", address, err)
+		}
+		if key.EncodeWif() != fixtureWif {
+			t.Fatalf("Unlock %v = %v", address, key.EncodeWif())
+		}
+	}
+	if _, err = w.Unlock(fixtureWatchOnly, fixturePassphrase); err == nil {
+		t.Fatal("watch-only account unlocked")
+	}
+	if _, err = w.Unlock("AGofsxAUDwt52KjaB664GYsqVAkULYvKNt", fixturePassphrase); err == nil {
+		t.Fatal("unknown account unlocked")
+	}
+
+	// 保存后的内容与原文件一致
+	path := filepath.Join(t.TempDir(), "saved.json")
+	if err = w.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	if w.Path() != path {
+		t.Fatalf("path %v", w.Path())
+	}
+	original, _ := ioutil.ReadFile("testdata/wallet.json")
+	saved, _ := ioutil.ReadFile(path)
+	if !sameJSON(t, original, saved) {
+		t.Fatalf("saved wallet\n%s\nwant\n%s", saved, original)
+	}
+}
+
+func TestWalletCreateRoundTrip(t *testing.T) {
+	w := NewWallet("test")
+	// 测试中使用较小的scrypt参数
+	w.Scrypt = neotransaction.ScryptParams{N: 1024, R: 8, P: 1}
+	if err := w.Save(); err == nil {
+		t.Fatal("wallet without a path saved")
+	}
+	account, key, err := w.CreateAccount("password", "created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture, _ := neotransaction.DecodeFromWif(fixtureWif)
+	if _, err = w.ImportKey(fixture, "password", "imported"); err != nil {
+		t.Fatal(err)
+	}
+	other := otherKey(t)
+	multi, err := w.AddMultiSigAccount(2, []*neotransaction.KeyPair{other, fixture, key}, key, "password", "multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.AddMultiSigAccount(1, []*neotransaction.KeyPair{other, fixture}, key, "password", ""); err == nil {
+		t.Fatal("multi-sig account added with a key that is not a member")
+	}
+	watchAddr, _ := neotransaction.ParseAddress(fixtureWatchOnly)
+	if _, err = w.AddWatchOnly(watchAddr, ""); err != nil {
+		t.Fatal(err)
+	}
+	// 导入已有的地址时不会重复添加
+	if _, err = w.ImportKey(fixture, "password", "again"); err != nil || len(w.Accounts) != 4 {
+		t.Fatalf("accounts %v %v", len(w.Accounts), err)
+	}
+	if err = w.SetDefault(multi.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	if err = w.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	opened, err := OpenWallet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.Scrypt != w.Scrypt || len(opened.Accounts) != 4 || opened.DefaultAccount().Address != multi.Address {
+		t.Fatalf("opened wallet %+v", opened)
+	}
+	for i, want := range []*neotransaction.KeyPair{key, fixture, key} {
+		address := opened.Accounts[i].Address
+		unlocked, err := opened.Unlock(address, "password")
+		if err != nil || unlocked.EncodeWif() != want.EncodeWif() {
+			t.Fatalf("Unlock %v: %v", address, err)
+		}
+		if _, err = opened.Unlock(address, "wrong"); err == nil {
+			t.Fatalf("%v unlocked with a wrong password", address)
+		}
+	}
+	if opened.Accounts[0].Label != "created" || opened.Accounts[1].Label != "imported" || account.Address != opened.Accounts[0].Address {
+		t.Fatalf("accounts %+v %+v", opened.Accounts[0], opened.Accounts[1])
+	}
+	if !opened.RemoveAccount(account.Address) || opened.GetAccount(account.Address) != nil || opened.RemoveAccount(account.Address) {
+		t.Fatal("RemoveAccount")
+	}
+	if err = opened.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if reopened, err := OpenWallet(path); err != nil || len(reopened.Accounts) != 3 {
+		t.Fatalf("reopened wallet %v", err)
+	}
+}
+
+func TestAccountUnlockWrongContract(t *testing.T) {
+	params := neotransaction.ScryptParams{N: 1024, R: 8, P: 1}
+	fixture, _ := neotransaction.DecodeFromWif(fixtureWif)
+	nep2, err := fixture.EncodeNep2("password", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := otherKey(t)
+	third := neotransaction.GenerateKeyPair()
+	otherMulti, err := multiSigContract(2, []*neotransaction.KeyPair{other, third})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherMultiAddr, _ := neotransaction.CreateAddressByScript(otherMulti.Script)
+	member, _ := multiSigContract(1, []*neotransaction.KeyPair{other, fixture})
+	memberAddr, _ := neotransaction.CreateAddressByScript(member.Script)
+
+	tests := []struct {
+		name    string
+		account *Account
+		ok      bool
+	}{
+		{"Basic", &Account{Address: fixtureAddress, Key: nep2, Contract: basicContract(fixture)}, true},
+		{"NoContract", &Account{Address: fixtureAddress, Key: nep2}, true},
+		{"MultiSigMember", &Account{Address: memberAddr.Addr, Key: nep2, Contract: member}, true},
+		// 多签账户的私钥不是成员的私钥
+		{"MultiSigNotMember", &Account{Address: otherMultiAddr.Addr, Key: nep2, Contract: otherMulti}, false},
+		{"OtherAddress", &Account{Address: other.CreateBasicAddress().Addr, Key: nep2}, false},
+		{"OtherBasicContract", &Account{Address: other.CreateBasicAddress().Addr, Key: nep2, Contract: basicContract(other)}, false},
+		// 合约与地址不一致
+		{"ContractNotMatchAddress", &Account{Address: fixtureAddress, Key: nep2, Contract: member}, false},
+	}
+	for _, tt := range tests {
+		key, err := tt.account.Unlock("password", params)
+		if (err == nil) != tt.ok {
+			t.Errorf("%v: Unlock error %v", tt.name, err)
+			continue
+		}
+		if tt.ok && key.EncodeWif() != fixtureWif {
+			t.Errorf("%v: Unlock = %v", tt.name, key.EncodeWif())
+		}
+	}
+}
@@ -56,6 +56,10 @@ func InvokeContract() {
 	rawtx := tx.RawTransactionString()
 	log.Printf(rawtx)
 
-	result := neocliapi.SendRawTransaction(neocliurl, rawtx)
+	result, err := neocliapi.SendRawTransaction(neocliurl, rawtx)
+	if err != nil {
+		log.Printf(`Send transaction to neo-cli node failed %v`, err)
+		return
+	}
 	log.Printf(`Send transaction to neo-cli node result[%v]`, result)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/x-contract/neo-go-sdk/neocliapi"
 	"github.com/x-contract/neo-go-sdk/neoextapi"
 	"github.com/x-contract/neo-go-sdk/neotransaction"
+	"github.com/x-contract/neo-go-sdk/neoutils"
 )
 
 var (
@@ -38,23 +39,28 @@ func TransferUTXO() {
 	tx := neotransaction.CreateContractTransaction()
 
 	//var utxo1 *UTXO
-	value1 := int64(0)
+	value1 := neoutils.Fixed8(0)
 	for _, utxo := range utxos1 {
 		value1 += utxo.Value
 		tx.AppendInput(utxo)
 	}
 
-	value2 := int64(0)
+	value2 := neoutils.Fixed8(0)
 	for _, utxo := range utxos2 {
 		value2 += utxo.Value
 		tx.AppendInput(utxo)
 	}
 
-	wantNeoValue := (value1 / neotransaction.TxOutputValueBase) / 4 * neotransaction.TxOutputValueBase
+	// NEO 不可分割，按整数个 NEO 兑换
+	wantNeoValue, err := neotransaction.TxOutputValueUnit.Mul(value1.IntegerValue() / 4)
+	if err != nil {
+		log.Printf(`Calculate NEO value failed %v`, err)
+		return
+	}
 	payGasValue := wantNeoValue * 4
 
 	changeGasBack := value1 - payGasValue
-	changeNeoBack := int64(0)
+	changeNeoBack := neoutils.Fixed8(0)
 
 	if value2 < wantNeoValue {
 		wantNeoValue = value2
@@ -87,6 +93,10 @@ func TransferUTXO() {
 	rawtx := tx.RawTransactionString()
 	log.Printf(rawtx)
 
-	result := neocliapi.SendRawTransaction(neocliurl, rawtx)
+	result, err := neocliapi.SendRawTransaction(neocliurl, rawtx)
+	if err != nil {
+		log.Printf(`Send transaction to neo-cli node failed %v`, err)
+		return
+	}
 	log.Printf(`Send transaction to neo-cli node result[%v]`, result)
 }